@@ -3,10 +3,10 @@ package main
 import "github.com/gotk3/gotk3/glib"
 
 const (
-	exitNormal      = 0
-	exitConfigError = 1
-	// exitArgumentError = 2 // Not used anymore
-	exitUnknown = 3
+	exitNormal        = 0
+	exitConfigError   = 1
+	exitArgumentError = 2
+	exitUnknown       = 3
 )
 
 const (
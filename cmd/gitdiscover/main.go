@@ -1,6 +1,7 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
 
@@ -8,6 +9,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	gitConfig "github.com/hultan/gitdiscover/internal/config"
+	"github.com/hultan/gitdiscover/internal/gitdiscover-cli"
 	"github.com/hultan/gitdiscover/internal/gitdiscover-gui"
 )
 
@@ -20,6 +22,14 @@ func main() {
 	logger = startLogging()
 	c := loadConfig()
 
+	// Any arguments means that we should run a CLI command, like
+	// "gitdiscover list", instead of starting the GUI.
+	if len(os.Args) > 1 {
+		logger.Info("Starting GitDiscover CLI!")
+		runCLI(c, os.Args[1:])
+		return
+	}
+
 	logger.Info("Starting GitDiscover GUI!")
 	showGUI(logger, c)
 }
@@ -71,6 +81,23 @@ func loadConfig() *gitConfig.Config {
 	return c
 }
 
+//
+// CLI functions
+//
+
+func runCLI(c *gitConfig.Config, args []string) {
+	cli := gitdiscover_cli.NewCLI(c, os.Stdout, os.Stderr)
+	err := cli.Run(args)
+	switch {
+	case err == nil:
+		exitProgram(exitNormal, nil)
+	case errors.Is(err, gitdiscover_cli.ErrUsage):
+		exitProgram(exitArgumentError, nil)
+	default:
+		exitProgram(exitUnknown, err)
+	}
+}
+
 //
 // GUI functions
 //
@@ -4,17 +4,15 @@ go 1.17
 
 require (
 	github.com/gotk3/gotk3 v0.6.1
-	github.com/hultan/gitstatus v1.0.0
-	github.com/hultan/gitstatusprompt v1.0.0
 	github.com/hultan/gomod v1.0.1
 	github.com/hultan/softteam v1.2.7
 	github.com/sirupsen/logrus v1.8.1
 	github.com/stretchr/testify v1.7.0
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/sys v0.0.0-20191026070338-33540a1f6037 // indirect
-	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c // indirect
 )
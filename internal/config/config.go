@@ -6,14 +6,47 @@ import (
 	"os"
 	"os/user"
 	"path"
+	"time"
 )
 
 // Config : The main config type
 type Config struct {
 	Repositories         []*Repository          `json:"repositories"`
+	ScanRoots            []*ScanRoot            `json:"scan-roots"`
 	ExternalApplications []*ExternalApplication `json:"external-applications"`
 	DateFormat           string                 `json:"date-format"`
 	PathColumnWidth      int                    `json:"path-column-width"`
+	StartMaximized       bool                   `json:"start-maximized"`
+	AutoUpdate           bool                   `json:"auto-update"`
+	// FetchInterval is the number of minutes between background
+	// fetches of each repository, 0 means no background fetch
+	FetchInterval int `json:"fetch-interval"`
+	// Columns are the columns of the repository list, in the order
+	// they are shown in, with their widths and visibility
+	Columns []*Column `json:"columns"`
+	// CollapsedSections are the keys of the collapsed sections in the
+	// repository list, like "favorites" or "group:work"
+	CollapsedSections []string `json:"collapsed-sections"`
+	// Filter is the last filter of the repository list
+	Filter Filter `json:"filter"`
+	// Sort is the sort order of the repository list
+	Sort Sort `json:"sort"`
+	// FetchTimes are the last times the repositories were fetched by
+	// GitDiscover, by path, for both configured and scanned repositories
+	FetchTimes map[string]time.Time `json:"fetch-times"`
+}
+
+// Filter : The filter of the repository list
+type Filter struct {
+	// Text is matched against the name and path of the repositories,
+	// as a case insensitive substring, or as a regular expression
+	Text  string `json:"text"`
+	Regex bool   `json:"regex"`
+
+	OnlyDirty         bool `json:"only-dirty"`
+	OnlyAhead         bool `json:"only-ahead"`
+	OnlyWithoutRemote bool `json:"only-without-remote"`
+	OnlyGoModules     bool `json:"only-go-modules"`
 }
 
 // Repository : A Repository in the config
@@ -21,6 +54,50 @@ type Repository struct {
 	Path       string `json:"path"`
 	ImagePath  string `json:"image-path"`
 	IsFavorite bool   `json:"is-favorite"`
+	// Group is a user defined group, like "work", empty for ungrouped repositories
+	Group string `json:"group"`
+
+	// ScanRoot is the path of the scan root that found the
+	// repository, it is empty for configured repositories.
+	ScanRoot string `json:"-"`
+}
+
+// Sort : The sort order of the repository list
+type Sort struct {
+	FavoritesFirst bool `json:"favorites-first"`
+	// Keys are the columns to sort by, like "date" or "ahead", the second
+	// key is used when the first key is equal, and so on. No keys means
+	// the default sort order (favorites, git repositories, name).
+	Keys []SortKey `json:"keys"`
+}
+
+// SortKey : A column in the sort order
+type SortKey struct {
+	Column     string `json:"column"`
+	Descending bool   `json:"descending"`
+}
+
+// Column : A column in the repository list
+type Column struct {
+	// Name identifies the column, like "path" or "git-status"
+	Name string `json:"name"`
+	// Width is the width in pixels, 0 means the natural width
+	Width   int  `json:"width"`
+	Visible bool `json:"visible"`
+}
+
+// ScanRoot : A folder that is scanned for git repositories
+type ScanRoot struct {
+	Path string `json:"path"`
+	// MaxDepth is the maximum number of folders below Path to look
+	// for repositories in, 0 means the default depth (3)
+	MaxDepth int `json:"max-depth"`
+	// Include and Exclude are glob patterns (like "work/*") that are matched
+	// against the folder name, and the folder path relative to Path
+	Include []string `json:"include"`
+	Exclude []string `json:"exclude"`
+	// SkipNested skips repositories inside other repositories (like submodules)
+	SkipNested bool `json:"skip-nested"`
 }
 
 // ExternalApplication : An external application in the config
@@ -113,15 +190,42 @@ func (c *Config) AddRepository(path, imagePath string, isFavorite bool) {
 	c.Repositories = append(c.Repositories, repo)
 }
 
+// GetRepositoryByPath gets a repository by path
+func (c *Config) GetRepositoryByPath(path string) *Repository {
+	for i := range c.Repositories {
+		repo := c.Repositories[i]
+		if repo.Path == path {
+			return repo
+		}
+	}
+
+	return nil
+}
+
 // RemoveRepository adds a new repository
 func (c *Config) RemoveRepository(path string) {
 	for i := range c.Repositories {
 		if c.Repositories[i].Path == path {
 			c.Repositories = append(c.Repositories[:i], c.Repositories[i+1:]...)
+			return
 		}
 	}
 }
 
+// GetFetchTime returns the last time the repository at path was fetched,
+// or the zero time if it has never been fetched
+func (c *Config) GetFetchTime(path string) time.Time {
+	return c.FetchTimes[path]
+}
+
+// SetFetchTime sets the last time the repository at path was fetched
+func (c *Config) SetFetchTime(path string, t time.Time) {
+	if c.FetchTimes == nil {
+		c.FetchTimes = make(map[string]time.Time)
+	}
+	c.FetchTimes[path] = t
+}
+
 // GetExternalApplicationByName gets an external application by name
 func (c *Config) GetExternalApplicationByName(name string) *ExternalApplication {
 	for i := range c.ExternalApplications {
@@ -134,6 +238,17 @@ func (c *Config) GetExternalApplicationByName(name string) *ExternalApplication
 	return nil
 }
 
+// GetColumnByName gets a column by name
+func (c *Config) GetColumnByName(name string) *Column {
+	for _, column := range c.Columns {
+		if column.Name == name {
+			return column
+		}
+	}
+
+	return nil
+}
+
 // ClearExternalApplications clears the slice of external applications
 func (c *Config) ClearExternalApplications() {
 	c.ExternalApplications = nil
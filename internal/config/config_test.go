@@ -94,6 +94,17 @@ func TestConfig_RemoveRepository(t *testing.T) {
 	assert.Equal(t, 1, len(c.Repositories))
 }
 
+func TestConfig_GetRepositoryByPath(t *testing.T) {
+	c := NewConfig()
+	c.AddRepository("/code/first", "", false)
+	c.AddRepository("/code/second", "", true)
+
+	repo := c.GetRepositoryByPath("/code/second")
+	assert.NotNil(t, repo)
+	assert.True(t, repo.IsFavorite)
+	assert.Nil(t, c.GetRepositoryByPath("/code/third"))
+}
+
 func TestConfig_AddExternalApplication(t *testing.T) {
 	c := NewConfig()
 	_ = c.Load(testConfigPath)
@@ -124,3 +135,13 @@ func TestConfig_RemoveExternalApplication(t *testing.T) {
 	c.RemoveExternalApplication("test")
 	assert.Equal(t, 0, len(c.ExternalApplications))
 }
+
+func TestConfig_GetColumnByName(t *testing.T) {
+	c := NewConfig()
+	c.Columns = []*Column{{Name: "path", Width: 300, Visible: true}, {Name: "tag"}}
+
+	column := c.GetColumnByName("path")
+	assert.NotNil(t, column)
+	assert.Equal(t, 300, column.Width)
+	assert.Nil(t, c.GetColumnByName("remote"))
+}
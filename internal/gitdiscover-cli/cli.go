@@ -0,0 +1,218 @@
+package gitdiscover_cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/hultan/gitdiscover/internal/config"
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// ErrUsage is returned when the command line arguments are invalid
+var ErrUsage = errors.New("invalid arguments")
+
+// CLI runs GitDiscover commands in a terminal, without the GUI
+type CLI struct {
+	config *config.Config
+	out    io.Writer
+	errOut io.Writer
+}
+
+// NewCLI creates a new CLI object
+func NewCLI(config *config.Config, out, errOut io.Writer) *CLI {
+	cli := new(CLI)
+	cli.config = config
+	cli.out = out
+	cli.errOut = errOut
+	return cli
+}
+
+// Run runs the command given in args (without the program name)
+func (c *CLI) Run(args []string) error {
+	if len(args) == 0 {
+		c.printUsage()
+		return ErrUsage
+	}
+
+	switch args[0] {
+	case "list":
+		return c.runTable(args[0], args[1:], false)
+	case "status":
+		return c.runTable(args[0], args[1:], true)
+	case "export":
+		return c.runExport(args[0], args[1:])
+	case "help", "-h", "-help", "--help":
+		c.printUsage()
+		return nil
+	default:
+		_, _ = fmt.Fprintf(c.errOut, "unknown command : %s\n\n", args[0])
+		c.printUsage()
+		return ErrUsage
+	}
+}
+
+func (c *CLI) runTable(command string, args []string, onlyChanged bool) error {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(c.errOut)
+	sortBy := flags.String("sort", "", sortUsage)
+	filter := flags.String("filter", "", "only show git repositories with changes in a category")
+	noColor := flags.Bool("no-color", false, "do not colorize the output")
+	err := flags.Parse(args)
+	if err != nil {
+		return ErrUsage
+	}
+
+	sortOrder, err := c.getSortOrder(*sortBy)
+	if err != nil {
+		_, _ = fmt.Fprintln(c.errOut, err)
+		return ErrUsage
+	}
+	if onlyChanged && *filter == "" {
+		*filter = gitdiscover.ChangesTotal.String()
+	}
+
+	// Discover and sort the repositories, the same way the GUI does
+	discover := gitdiscover.NewDiscover(c.config)
+	discover.Repositories.Sort(sortOrder)
+
+	repos, err := c.filterRepositories(discover.Repositories, *filter)
+	if err != nil {
+		_, _ = fmt.Fprintln(c.errOut, err)
+		return ErrUsage
+	}
+
+	t := newTable(discover.GetDateFormat(), !*noColor && c.useColor())
+	t.write(c.out, repos)
+
+	return nil
+}
+
+func (c *CLI) runExport(command string, args []string) error {
+	flags := flag.NewFlagSet(command, flag.ContinueOnError)
+	flags.SetOutput(c.errOut)
+	format := flags.String("format", "json", "export format, json, csv or yaml")
+	output := flags.String("output", "", "file to export to (default stdout)")
+	sortBy := flags.String("sort", "", sortUsage)
+	filter := flags.String("filter", "", "only export git repositories with changes in a category")
+	err := flags.Parse(args)
+	if err != nil {
+		return ErrUsage
+	}
+
+	exportFormat, err := gitdiscover.ParseExportFormat(*format)
+	if err != nil {
+		_, _ = fmt.Fprintln(c.errOut, err)
+		return ErrUsage
+	}
+	sortOrder, err := c.getSortOrder(*sortBy)
+	if err != nil {
+		_, _ = fmt.Fprintln(c.errOut, err)
+		return ErrUsage
+	}
+
+	discover := gitdiscover.NewDiscover(c.config)
+	discover.Repositories.Sort(sortOrder)
+	repos, err := c.filterRepositories(discover.Repositories, *filter)
+	if err != nil {
+		_, _ = fmt.Fprintln(c.errOut, err)
+		return ErrUsage
+	}
+
+	if *output == "" {
+		return repos.Export(c.out, exportFormat)
+	}
+
+	file, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	err = repos.Export(file, exportFormat)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// getSortOrder returns the sort order in the config, with the sort keys
+// in value (like "ahead,name:desc") if it is not empty
+func (c *CLI) getSortOrder(value string) (*gitdiscover.SortOrder, error) {
+	order, err := gitdiscover.NewSortOrder(c.config.Sort)
+	if err != nil {
+		return nil, err
+	}
+	if value == "" {
+		return order, nil
+	}
+	keys, err := gitdiscover.ParseSortKeys(value)
+	if err != nil {
+		return nil, err
+	}
+	order.Keys = keys
+	return order, nil
+}
+
+// filterRepositories returns the git repositories with changes in the category
+// with the given name, or all repositories if no name is given
+func (c *CLI) filterRepositories(repos gitdiscover.Repositories, filter string) (gitdiscover.Repositories, error) {
+	if filter == "" {
+		return repos, nil
+	}
+	category, err := gitdiscover.ParseChangeCategory(filter)
+	if err != nil {
+		return nil, err
+	}
+	return repos.FilterByChanges(category), nil
+}
+
+// useColor returns true if the output is a terminal, and the
+// user has not asked for no color (https://no-color.org)
+func (c *CLI) useColor() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	file, ok := c.out.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func (c *CLI) printUsage() {
+	usage := `Usage: gitdiscover [command] [flags]
+
+Without a command, the GitDiscover GUI is started.
+
+Commands:
+  list      Print all repositories and folders
+  status    Print only the git repositories that have changes
+  export    Export the repositories as JSON, CSV or YAML
+  help      Print this help
+
+Flags (list and status):
+  -sort string     sort keys, like "ahead,name:desc" (default the sort order in the config)
+  -filter string   only show git repositories with changes in a category
+  -no-color        do not colorize the output
+
+Flags (export):
+  -format string   json, csv or yaml (default "json")
+  -output string   file to export to (default stdout)
+  -sort string     sort keys, like "ahead,name:desc" (default the sort order in the config)
+  -filter string   only export git repositories with changes in a category
+
+Sort columns:
+  name, path, date, ahead, behind, tag, go, remote, group, git or a change category,
+  optionally followed by :asc or :desc
+
+Change categories:
+  changes, staged, unstaged, untracked, modified, deleted, renamed, unmerged, stashes
+`
+	_, _ = fmt.Fprint(c.out, usage)
+}
@@ -0,0 +1,73 @@
+package gitdiscover_cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+func TestCLI_Run_List(t *testing.T) {
+	dir := t.TempDir()
+	c := config.NewConfig()
+	c.DateFormat = "2006-01-02"
+	c.AddRepository(dir, "", true)
+
+	var out, errOut bytes.Buffer
+	cli := NewCLI(c, &out, &errOut)
+	err := cli.Run([]string{"list", "-no-color"})
+	assert.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 2, len(lines))
+	assert.True(t, strings.HasPrefix(lines[0], "Date"))
+	assert.Contains(t, lines[1], dir)
+	assert.Contains(t, lines[1], "★")
+	assert.NotContains(t, out.String(), "\x1b[")
+}
+
+func TestCLI_Run_Status(t *testing.T) {
+	c := config.NewConfig()
+	c.AddRepository(t.TempDir(), "", false)
+
+	var out, errOut bytes.Buffer
+	cli := NewCLI(c, &out, &errOut)
+	err := cli.Run([]string{"status"})
+	assert.Nil(t, err)
+
+	// Non-git folders never have changes, so only the header is printed
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, 1, len(lines))
+}
+
+func TestCLI_Run_Export(t *testing.T) {
+	c := config.NewConfig()
+	c.AddRepository(t.TempDir(), "", false)
+
+	var out, errOut bytes.Buffer
+	cli := NewCLI(c, &out, &errOut)
+	err := cli.Run([]string{"export", "-format", "csv"})
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(out.String(), "name,path,is-git,"))
+	assert.Equal(t, ErrUsage, cli.Run([]string{"export", "-format", "xml"}))
+}
+
+func TestCLI_Run_InvalidArguments(t *testing.T) {
+	var out, errOut bytes.Buffer
+	cli := NewCLI(config.NewConfig(), &out, &errOut)
+	assert.Equal(t, ErrUsage, cli.Run(nil))
+	assert.Equal(t, ErrUsage, cli.Run([]string{"unknown"}))
+	assert.Equal(t, ErrUsage, cli.Run([]string{"list", "-sort", "size"}))
+	assert.Equal(t, ErrUsage, cli.Run([]string{"list", "-filter", "size"}))
+	assert.Nil(t, cli.Run([]string{"list", "-sort", "staged", "-filter", "stashes"}))
+}
+
+func TestTable_colorize(t *testing.T) {
+	tbl := newTable("", true)
+	assert.Equal(t, "\x1b[38;2;141;179;139mtext\x1b[0m", tbl.colorize("text", "8DB38B"))
+	tbl = newTable("", false)
+	assert.Equal(t, "text", tbl.colorize("text", "8DB38B"))
+}
@@ -0,0 +1,14 @@
+package gitdiscover_cli
+
+// sortUsage is the usage of the -sort flag
+const sortUsage = "sort keys, like \"ahead,name:desc\" (name, path, date, ahead, behind, tag, go,\n" +
+	"remote, group, git or a change category), the default is the sort order in the config"
+
+// Column :                  Path      Date      GitStatus GoStatus  Yes       No
+var columnColors = []string{"8DB38B", "8DB38B", "D2AB99", "8DB38B", "8DB38B", "4D934B"}
+var favoriteColor = "8C8C00"
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiBold  = "\x1b[1m"
+)
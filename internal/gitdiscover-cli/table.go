@@ -0,0 +1,114 @@
+package gitdiscover_cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+type table struct {
+	dateFormat string
+	color      bool
+}
+
+type tableCell struct {
+	text  string
+	color string
+}
+
+func newTable(dateFormat string, color bool) *table {
+	t := new(table)
+	t.dateFormat = dateFormat
+	t.color = color
+	return t
+}
+
+// write writes the repositories as an aligned table, with
+// the same columns as the repository list in the GUI.
+func (t *table) write(w io.Writer, repos gitdiscover.Repositories) {
+	header := []string{"Date", "Fav", "Path", "Git status", "Go status", "Remote"}
+
+	var rows [][]tableCell
+	for _, repo := range repos {
+		rows = append(rows, t.createRow(repo))
+	}
+
+	// Calculate column widths
+	widths := make([]int, len(header))
+	for i, text := range header {
+		widths[i] = utf8.RuneCountInString(text)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			if l := utf8.RuneCountInString(cell.text); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+
+	// Header
+	var line []string
+	for i, text := range header {
+		line = append(line, t.bold(t.pad(text, widths[i])))
+	}
+	_, _ = fmt.Fprintln(w, strings.TrimRight(strings.Join(line, "  "), " "))
+
+	// Rows
+	for _, row := range rows {
+		line = line[:0]
+		for i, cell := range row {
+			line = append(line, t.colorize(t.pad(cell.text, widths[i]), cell.color))
+		}
+		_, _ = fmt.Fprintln(w, strings.TrimRight(strings.Join(line, "  "), " "))
+	}
+}
+
+func (t *table) createRow(repo *gitdiscover.Repository) []tableCell {
+	favorite := ""
+	if repo.IsFavorite() {
+		favorite = "★"
+	}
+
+	// The hosting provider of the default remote
+	remote, remoteColor := "none", columnColors[5]
+	if defaultRemote := repo.DefaultRemote(); defaultRemote != nil {
+		remote, remoteColor = defaultRemote.Provider().String(), columnColors[4]
+	}
+
+	return []tableCell{
+		{repo.ModifiedDate().Format(t.dateFormat), columnColors[1]},
+		{favorite, favoriteColor},
+		{repo.Path(), columnColors[0]},
+		{repo.GitStatus(), columnColors[2]},
+		{strings.TrimSpace(repo.GoStatus()), columnColors[3]},
+		{remote, remoteColor},
+	}
+}
+
+func (t *table) pad(text string, width int) string {
+	return text + strings.Repeat(" ", width-utf8.RuneCountInString(text))
+}
+
+func (t *table) bold(text string) string {
+	if !t.color {
+		return text
+	}
+	return ansiBold + text + ansiReset
+}
+
+// colorize wraps the text in a 24-bit ANSI color escape sequence
+func (t *table) colorize(text, color string) string {
+	if !t.color || len(color) != 6 {
+		return text
+	}
+	rgb, err := strconv.ParseUint(color, 16, 32)
+	if err != nil {
+		return text
+	}
+	r, g, b := rgb>>16&0xFF, rgb>>8&0xFF, rgb&0xFF
+	return fmt.Sprintf("\x1b[38;2;%d;%d;%dm%s%s", r, g, b, text, ansiReset)
+}
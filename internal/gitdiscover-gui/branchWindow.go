@@ -0,0 +1,397 @@
+package gitdiscover_gui
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// The columns in the branch list store
+const (
+	branchColumnCurrent = iota
+	branchColumnName
+	branchColumnUpstream
+	branchColumnAhead
+	branchColumnBehind
+	branchColumnDate
+	branchColumnSubject
+	// branchColumnIndex is the index of the branch in branchWindow.branches
+	branchColumnIndex
+)
+
+type branchWindow struct {
+	gitWindow
+
+	store          *gtk.ListStore
+	treeView       *gtk.TreeView
+	checkoutButton *gtk.Button
+	newButton      *gtk.Button
+	renameButton   *gtk.Button
+	deleteButton   *gtk.Button
+	upstreamButton *gtk.Button
+
+	branches []*gitdiscover.Branch
+}
+
+func newBranchWindow(mainWindow *MainWindow) *branchWindow {
+	branch := new(branchWindow)
+	branch.mainWindow = mainWindow
+	return branch
+}
+
+func (b *branchWindow) openWindow(repo *gitdiscover.Repository) {
+	// Create a new softBuilder
+	builder, err := fw.Gtk.CreateBuilder("branchWindow.ui")
+	if err != nil {
+		panic(err)
+	}
+	b.builder = builder
+	b.repo = repo
+
+	window := b.builder.GetObject("branchWindow").(*gtk.Window)
+	window.Connect("destroy", b.closeWindow)
+	window.SetTitle("Branches...")
+	window.HideOnDelete()
+	window.SetModal(true)
+	window.SetKeepAbove(true)
+	window.SetPosition(gtk.WIN_POS_CENTER_ALWAYS)
+
+	label := b.builder.GetObject("labelHeader").(*gtk.Label)
+	label.SetText("Branches in " + repo.Path())
+
+	b.stateLabel = b.builder.GetObject("labelState").(*gtk.Label)
+	b.reload = b.loadBranches
+
+	button := b.builder.GetObject("closeButton").(*gtk.Button)
+	button.Connect("clicked", b.closeWindow)
+	button = b.builder.GetObject("refreshButton").(*gtk.Button)
+	button.Connect("clicked", b.loadBranches)
+
+	b.checkoutButton = b.builder.GetObject("checkoutButton").(*gtk.Button)
+	b.checkoutButton.Connect("clicked", b.checkout)
+	b.newButton = b.builder.GetObject("newButton").(*gtk.Button)
+	b.newButton.Connect("clicked", b.createBranch)
+	b.renameButton = b.builder.GetObject("renameButton").(*gtk.Button)
+	b.renameButton.Connect("clicked", b.renameBranch)
+	b.deleteButton = b.builder.GetObject("deleteButton").(*gtk.Button)
+	b.deleteButton.Connect("clicked", b.deleteBranch)
+	b.upstreamButton = b.builder.GetObject("upstreamButton").(*gtk.Button)
+	b.upstreamButton.Connect("clicked", b.setUpstream)
+
+	if !b.setupTreeView() {
+		return
+	}
+
+	b.window = window
+	window.ShowAll()
+
+	b.updateButtons()
+	b.loadBranches()
+}
+
+func (b *branchWindow) setupTreeView() bool {
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_INT)
+	if err != nil {
+		b.mainWindow.logger.Error(err)
+		return false
+	}
+	b.store = store
+
+	b.treeView = b.builder.GetObject("branchTreeView").(*gtk.TreeView)
+	b.treeView.SetModel(store)
+
+	columns := []struct {
+		title  string
+		column int
+	}{
+		{"", branchColumnCurrent},
+		{"Branch", branchColumnName},
+		{"Upstream", branchColumnUpstream},
+		{"Ahead", branchColumnAhead},
+		{"Behind", branchColumnBehind},
+		{"Date", branchColumnDate},
+		{"Last commit", branchColumnSubject},
+	}
+	for _, c := range columns {
+		renderer, err := gtk.CellRendererTextNew()
+		if err != nil {
+			b.mainWindow.logger.Error(err)
+			return false
+		}
+		column, err := gtk.TreeViewColumnNewWithAttribute(c.title, renderer, "text", c.column)
+		if err != nil {
+			b.mainWindow.logger.Error(err)
+			return false
+		}
+		column.SetResizable(true)
+		column.SetExpand(c.column == branchColumnSubject)
+		b.treeView.AppendColumn(column)
+	}
+
+	selection, err := b.treeView.GetSelection()
+	if err != nil {
+		b.mainWindow.logger.Error(err)
+		return false
+	}
+	selection.Connect("changed", b.updateButtons)
+	b.treeView.Connect("row-activated", b.checkout)
+	return true
+}
+
+func (b *branchWindow) closeWindow() {
+	b.window.Hide()
+	b.window = nil
+
+	// Update the branch of the repository in the main window
+	b.mainWindow.refreshSingleRepository(b.repo.Path())
+}
+
+// loadBranches loads the branches in the background
+func (b *branchWindow) loadBranches() {
+	path := b.repo.Path()
+	go func() {
+		branches, err := gitdiscover.GetBranches(context.Background(), path)
+		glib.IdleAdd(func() {
+			// The window is closed
+			if b.window == nil {
+				return
+			}
+			if err != nil {
+				b.showError(err)
+				return
+			}
+			b.branchesLoaded(branches)
+		})
+	}()
+}
+
+func (b *branchWindow) branchesLoaded(branches []*gitdiscover.Branch) {
+	b.branches = branches
+	b.store.Clear()
+
+	dateFormat := b.mainWindow.discover.GetDateFormat()
+	for i, branch := range branches {
+		current := ""
+		if branch.Current {
+			current = "*"
+		}
+		upstream := branch.Upstream
+		if branch.UpstreamGone {
+			upstream += " (gone)"
+		}
+
+		iter := b.store.Append()
+		err := b.store.Set(iter,
+			[]int{branchColumnCurrent, branchColumnName, branchColumnUpstream, branchColumnAhead,
+				branchColumnBehind, branchColumnDate, branchColumnSubject, branchColumnIndex},
+			[]interface{}{current, branch.Name, upstream, b.getCount(branch.Ahead), b.getCount(branch.Behind),
+				branch.Date.Format(dateFormat), branch.Subject, i})
+		if err != nil {
+			b.mainWindow.logger.Error(err)
+		}
+	}
+	b.updateButtons()
+}
+
+// getCount returns the ahead or behind count, or an empty string if it is zero
+func (b *branchWindow) getCount(count int) string {
+	if count == 0 {
+		return ""
+	}
+	return strconv.Itoa(count)
+}
+
+// getSelectedBranch returns the selected branch, or nil if no branch is selected
+func (b *branchWindow) getSelectedBranch() *gitdiscover.Branch {
+	selection, err := b.treeView.GetSelection()
+	if err != nil {
+		b.mainWindow.logger.Error(err)
+		return nil
+	}
+	_, iter, ok := selection.GetSelected()
+	if !ok {
+		return nil
+	}
+	value, err := b.store.GetValue(iter, branchColumnIndex)
+	if err != nil {
+		b.mainWindow.logger.Error(err)
+		return nil
+	}
+	index, err := value.GoValue()
+	if err != nil {
+		b.mainWindow.logger.Error(err)
+		return nil
+	}
+	i, ok := index.(int)
+	if !ok || i < 0 || i >= len(b.branches) {
+		return nil
+	}
+	return b.branches[i]
+}
+
+// updateButtons enables the actions that can be used on the selected branch
+func (b *branchWindow) updateButtons() {
+	branch := b.getSelectedBranch()
+	local := branch != nil && !branch.Remote
+	b.checkoutButton.SetSensitive(branch != nil && !branch.Current)
+	b.newButton.SetSensitive(true)
+	b.renameButton.SetSensitive(local)
+	b.deleteButton.SetSensitive(local && !branch.Current)
+	b.upstreamButton.SetSensitive(local)
+}
+
+func (b *branchWindow) checkout() {
+	branch := b.getSelectedBranch()
+	if branch == nil || branch.Current {
+		return
+	}
+	b.runAction("Checked out "+branch.Name, func(ctx context.Context, repoPath string) error {
+		return gitdiscover.CheckoutBranch(ctx, repoPath, branch)
+	})
+}
+
+// createBranch creates a new branch from the selected branch, or from HEAD
+func (b *branchWindow) createBranch() {
+	startPoint := ""
+	prompt := "Name of the new branch (from HEAD) :"
+	if branch := b.getSelectedBranch(); branch != nil {
+		startPoint = branch.Name
+		prompt = "Name of the new branch (from " + branch.Name + ") :"
+	}
+	name, ok := b.mainWindow.askForText(b.window, "New branch...", prompt, "Create", "")
+	if !ok || name == "" {
+		return
+	}
+	checkout := b.mainWindow.askQuestion(b.window, "Do you want to check out the new branch "+name+"?")
+	b.runAction("Created "+name, func(ctx context.Context, repoPath string) error {
+		return gitdiscover.CreateBranch(ctx, repoPath, name, startPoint, checkout)
+	})
+}
+
+func (b *branchWindow) renameBranch() {
+	branch := b.getSelectedBranch()
+	if branch == nil || branch.Remote {
+		return
+	}
+	name, ok := b.mainWindow.askForText(b.window, "Rename branch...", "New name of "+branch.Name+" :",
+		"Rename", branch.Name)
+	if !ok || name == "" || name == branch.Name {
+		return
+	}
+	b.runAction("Renamed "+branch.Name+" to "+name, func(ctx context.Context, repoPath string) error {
+		return gitdiscover.RenameBranch(ctx, repoPath, branch.Name, name)
+	})
+}
+
+// deleteBranch deletes the selected branch, and asks again
+// before deleting a branch that is not merged
+func (b *branchWindow) deleteBranch() {
+	branch := b.getSelectedBranch()
+	if branch == nil || branch.Remote || branch.Current {
+		return
+	}
+	if !b.mainWindow.askQuestion(b.window, "Are you sure that you want to delete the branch "+branch.Name+"?") {
+		return
+	}
+
+	// Check if the branch is merged in the background, and ask again if it is not
+	path := b.repo.Path()
+	go func() {
+		merged, err := gitdiscover.IsBranchMerged(context.Background(), path, branch.Name)
+		glib.IdleAdd(func() {
+			// The window is closed
+			if b.window == nil {
+				return
+			}
+			if err != nil {
+				b.showError(err)
+				return
+			}
+			if !merged && !b.mainWindow.askQuestion(b.window, "The branch "+branch.Name+
+				" is not merged into the current branch, and its commits may be lost. Delete it anyway?") {
+				return
+			}
+			b.runAction("Deleted "+branch.Name, func(ctx context.Context, repoPath string) error {
+				return gitdiscover.DeleteBranch(ctx, repoPath, branch.Name, !merged)
+			})
+		})
+	}()
+}
+
+// setUpstream lets the user pick one of the remote branches as upstream, or none
+func (b *branchWindow) setUpstream() {
+	branch := b.getSelectedBranch()
+	if branch == nil || branch.Remote {
+		return
+	}
+	upstream, ok := b.askForUpstream(branch)
+	if !ok {
+		return
+	}
+	description := "Removed the upstream of " + branch.Name
+	if upstream != "" {
+		description = "Set the upstream of " + branch.Name + " to " + upstream
+	}
+	b.runAction(description, func(ctx context.Context, repoPath string) error {
+		return gitdiscover.SetUpstream(ctx, repoPath, branch.Name, upstream)
+	})
+}
+
+func (b *branchWindow) askForUpstream(branch *gitdiscover.Branch) (string, bool) {
+	dialog, err := gtk.DialogNewWithButtons("Set upstream...", b.window, gtk.DIALOG_MODAL,
+		[]interface{}{"Cancel", gtk.RESPONSE_CANCEL}, []interface{}{"Set upstream", gtk.RESPONSE_OK})
+	if err != nil {
+		b.mainWindow.logger.Error(err)
+		return "", false
+	}
+	defer dialog.Destroy()
+	dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	content, err := dialog.GetContentArea()
+	if err != nil {
+		b.mainWindow.logger.Error(err)
+		return "", false
+	}
+	label, err := gtk.LabelNew("Upstream branch of " + branch.Name + " :")
+	if err != nil {
+		b.mainWindow.logger.Error(err)
+		return "", false
+	}
+	combo, err := gtk.ComboBoxTextNew()
+	if err != nil {
+		b.mainWindow.logger.Error(err)
+		return "", false
+	}
+	const noUpstream = "(none)"
+	combo.AppendText(noUpstream)
+	combo.SetActive(0)
+	index := 1
+	for _, remote := range b.branches {
+		if !remote.Remote {
+			continue
+		}
+		combo.AppendText(remote.Name)
+		if remote.Name == branch.Upstream {
+			combo.SetActive(index)
+		}
+		index++
+	}
+	content.SetSpacing(5)
+	content.PackStart(label, false, false, 5)
+	content.PackStart(combo, false, false, 5)
+	dialog.ShowAll()
+
+	if dialog.Run() != gtk.RESPONSE_OK {
+		return "", false
+	}
+	upstream := combo.GetActiveText()
+	if upstream == noUpstream {
+		upstream = ""
+	}
+	return upstream, true
+}
@@ -0,0 +1,254 @@
+package gitdiscover_gui
+
+import (
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+	"github.com/hultan/gitdiscover/internal/gitoutput"
+	"github.com/hultan/softteam/framework"
+)
+
+// The columns in the command palette list store
+const (
+	paletteColumnName = iota
+	paletteColumnDetail
+)
+
+// paletteItem is a row in the command palette, a command or a repository
+type paletteItem struct {
+	command *command
+	repo    *gitdiscover.Repository
+}
+
+// commandPalette searches the commands and the repositories. Choosing a
+// repository selects it in the main window, and choosing a command runs
+// it on the selected repository.
+type commandPalette struct {
+	mainWindow *MainWindow
+	window     *gtk.Window
+	builder    *framework.GtkBuilder
+
+	repoLabel *gtk.Label
+	entry     *gtk.SearchEntry
+	store     *gtk.ListStore
+	treeView  *gtk.TreeView
+
+	commands []command
+	// items are the rows in the list, in the order they are shown in
+	items []paletteItem
+}
+
+func newCommandPalette(mainWindow *MainWindow) *commandPalette {
+	palette := new(commandPalette)
+	palette.mainWindow = mainWindow
+	return palette
+}
+
+func (p *commandPalette) openWindow() {
+	// Create a new softBuilder
+	builder, err := fw.Gtk.CreateBuilder("commandPalette.ui")
+	if err != nil {
+		panic(err)
+	}
+	p.builder = builder
+
+	window := p.builder.GetObject("commandPaletteWindow").(*gtk.Window)
+	window.Connect("destroy", p.closeWindow)
+	window.SetTitle("Command palette...")
+	window.HideOnDelete()
+	window.SetModal(true)
+	window.SetKeepAbove(true)
+	window.SetPosition(gtk.WIN_POS_CENTER_ALWAYS)
+
+	p.repoLabel = p.builder.GetObject("labelRepository").(*gtk.Label)
+
+	p.entry = p.builder.GetObject("commandEntry").(*gtk.SearchEntry)
+	p.entry.Connect("search-changed", p.search)
+	p.entry.Connect("activate", p.activateSelectedItem)
+	p.entry.Connect("stop-search", p.closeWindow)
+	p.entry.Connect("key-press-event", p.entryKeyPressed)
+
+	if !p.setupTreeView() {
+		return
+	}
+
+	p.commands = p.mainWindow.getCommands()
+
+	p.window = window
+	window.ShowAll()
+
+	p.updateRepoLabel()
+	p.search()
+	p.entry.GrabFocus()
+}
+
+func (p *commandPalette) setupTreeView() bool {
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		p.mainWindow.logger.Error(err)
+		return false
+	}
+	p.store = store
+
+	p.treeView = p.builder.GetObject("commandTreeView").(*gtk.TreeView)
+	p.treeView.SetModel(store)
+
+	for _, column := range []int{paletteColumnName, paletteColumnDetail} {
+		renderer, err := gtk.CellRendererTextNew()
+		if err != nil {
+			p.mainWindow.logger.Error(err)
+			return false
+		}
+		treeViewColumn, err := gtk.TreeViewColumnNewWithAttribute("", renderer, "markup", column)
+		if err != nil {
+			p.mainWindow.logger.Error(err)
+			return false
+		}
+		treeViewColumn.SetExpand(column == paletteColumnName)
+		p.treeView.AppendColumn(treeViewColumn)
+	}
+
+	p.treeView.Connect("row-activated", p.activateSelectedItem)
+	return true
+}
+
+func (p *commandPalette) closeWindow() {
+	if p.window == nil {
+		return
+	}
+	p.window.Hide()
+	p.window = nil
+}
+
+// updateRepoLabel shows the repository that the commands run on
+func (p *commandPalette) updateRepoLabel() {
+	repo := p.mainWindow.getSelectedRepo()
+	if repo == nil {
+		p.repoLabel.SetMarkup("No repository is selected, choose a repository to select it")
+		return
+	}
+	p.repoLabel.SetMarkup("Commands run on <b>" + gitoutput.EscapeMarkup(repo.Name()) + "</b>, " +
+		"or choose another repository to select it")
+}
+
+// search shows the commands and the repositories that match the text in
+// the entry, the best match first, and selects the first row
+func (p *commandPalette) search() {
+	text, err := p.entry.GetText()
+	if err != nil {
+		p.mainWindow.logger.Error(err)
+		return
+	}
+
+	var candidates []paletteItem
+	var names []string
+	for i := range p.commands {
+		candidates = append(candidates, paletteItem{command: &p.commands[i]})
+		names = append(names, p.commands[i].name)
+	}
+	for _, repo := range p.mainWindow.discover.Repositories {
+		candidates = append(candidates, paletteItem{repo: repo})
+		names = append(names, repo.Name())
+	}
+
+	p.items = nil
+	p.store.Clear()
+	for _, index := range gitdiscover.FuzzyFilter(text, names) {
+		item := candidates[index]
+		name, detail := "", ""
+		if item.command != nil {
+			name = gitoutput.EscapeMarkup(item.command.name)
+			detail = gitoutput.EscapeMarkup(getAcceleratorLabel(item.command.accelerator))
+		} else {
+			name = "<i>Go to</i> " + gitoutput.EscapeMarkup(item.repo.Name())
+			detail = `<span foreground="gray">` + gitoutput.EscapeMarkup(item.repo.Path()) + `</span>`
+		}
+		iter := p.store.Append()
+		err := p.store.Set(iter, []int{paletteColumnName, paletteColumnDetail}, []interface{}{name, detail})
+		if err != nil {
+			p.mainWindow.logger.Error(err)
+		}
+		p.items = append(p.items, item)
+	}
+	p.selectItem(0)
+}
+
+// selectItem selects the row at index, and scrolls to it
+func (p *commandPalette) selectItem(index int) {
+	if index < 0 || index >= len(p.items) {
+		return
+	}
+	path, err := gtk.TreePathNewFromIndicesv([]int{index})
+	if err != nil {
+		p.mainWindow.logger.Error(err)
+		return
+	}
+	selection, err := p.treeView.GetSelection()
+	if err != nil {
+		p.mainWindow.logger.Error(err)
+		return
+	}
+	selection.SelectPath(path)
+	p.treeView.ScrollToCell(path, nil, false, 0, 0)
+}
+
+// getSelectedIndex returns the index of the selected row, or -1 if no row is selected
+func (p *commandPalette) getSelectedIndex() int {
+	selection, err := p.treeView.GetSelection()
+	if err != nil {
+		p.mainWindow.logger.Error(err)
+		return -1
+	}
+	_, iter, ok := selection.GetSelected()
+	if !ok {
+		return -1
+	}
+	path, err := p.store.GetPath(iter)
+	if err != nil {
+		p.mainWindow.logger.Error(err)
+		return -1
+	}
+	return path.GetIndices()[0]
+}
+
+// entryKeyPressed moves the selection with the arrow keys, while the focus is in the entry
+func (p *commandPalette) entryKeyPressed(_ *gtk.SearchEntry, event *gdk.Event) bool {
+	key := gdk.EventKeyNewFromEvent(event)
+	switch key.KeyVal() {
+	case uint(gdk.KEY_Up):
+		p.selectItem(p.getSelectedIndex() - 1)
+		return true
+	case uint(gdk.KEY_Down):
+		p.selectItem(p.getSelectedIndex() + 1)
+		return true
+	default:
+		return false
+	}
+}
+
+// activateSelectedItem runs the selected command, or selects the selected repository
+func (p *commandPalette) activateSelectedItem() {
+	index := p.getSelectedIndex()
+	if index < 0 {
+		return
+	}
+	item := p.items[index]
+
+	if item.repo != nil {
+		// The palette stays open, so that a command can be run on the repository
+		if !p.mainWindow.selectRepository(item.repo) {
+			p.repoLabel.SetMarkup(`<span foreground="red">` + gitoutput.EscapeMarkup(item.repo.Name()) +
+				` is hidden by the filter...</span>`)
+			return
+		}
+		p.updateRepoLabel()
+		p.entry.SetText("")
+		return
+	}
+
+	// The command can open another modal window, so the palette is closed first
+	p.closeWindow()
+	item.command.action()
+}
@@ -0,0 +1,411 @@
+package gitdiscover_gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+	"github.com/hultan/gitdiscover/internal/gitoutput"
+)
+
+// The columns in the file list store
+const (
+	commitColumnStaged = iota
+	// commitColumnPartly is true if the file has both staged and unstaged changes
+	commitColumnPartly
+	commitColumnStatus
+	commitColumnPath
+	// commitColumnIndex is the index of the file in commitWindow.files
+	commitColumnIndex
+)
+
+type commitWindow struct {
+	gitWindow
+
+	store         *gtk.ListStore
+	treeView      *gtk.TreeView
+	diffBuffer    *gtk.TextBuffer
+	messageBuffer *gtk.TextBuffer
+	hintsLabel    *gtk.Label
+	amend         *gtk.CheckButton
+	commitButton  *gtk.Button
+
+	files []*gitdiscover.ChangedFile
+	// selectedPath is the file that is shown in the diff preview
+	selectedPath string
+}
+
+func newCommitWindow(mainWindow *MainWindow) *commitWindow {
+	commit := new(commitWindow)
+	commit.mainWindow = mainWindow
+	return commit
+}
+
+func (c *commitWindow) openWindow(repo *gitdiscover.Repository) {
+	// Create a new softBuilder
+	builder, err := fw.Gtk.CreateBuilder("commitWindow.ui")
+	if err != nil {
+		panic(err)
+	}
+	c.builder = builder
+	c.repo = repo
+
+	window := c.builder.GetObject("commitWindow").(*gtk.Window)
+	window.Connect("destroy", c.closeWindow)
+	window.SetTitle("Commit...")
+	window.HideOnDelete()
+	window.SetModal(true)
+	window.SetKeepAbove(true)
+	window.SetPosition(gtk.WIN_POS_CENTER_ALWAYS)
+
+	label := c.builder.GetObject("labelHeader").(*gtk.Label)
+	label.SetText("Commit changes in " + repo.Path())
+
+	button := c.builder.GetObject("closeButton").(*gtk.Button)
+	button.Connect("clicked", c.closeWindow)
+
+	button = c.builder.GetObject("refreshButton").(*gtk.Button)
+	button.Connect("clicked", c.loadFiles)
+
+	c.commitButton = c.builder.GetObject("commitButton").(*gtk.Button)
+	c.commitButton.Connect("clicked", c.commit)
+
+	c.amend = c.builder.GetObject("checkBoxAmend").(*gtk.CheckButton)
+	c.amend.Connect("toggled", c.amendToggled)
+
+	c.hintsLabel = c.builder.GetObject("labelHints").(*gtk.Label)
+	c.stateLabel = c.builder.GetObject("labelState").(*gtk.Label)
+
+	c.diffBuffer, err = gtk.TextBufferNew(nil)
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return
+	}
+	textView := c.builder.GetObject("diffTextView").(*gtk.TextView)
+	textView.SetBuffer(c.diffBuffer)
+
+	c.messageBuffer, err = gtk.TextBufferNew(nil)
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return
+	}
+	textView = c.builder.GetObject("messageTextView").(*gtk.TextView)
+	textView.SetBuffer(c.messageBuffer)
+	c.messageBuffer.Connect("changed", c.updateHints)
+	c.updateHints()
+
+	if !c.setupTreeView() {
+		return
+	}
+
+	c.window = window
+	window.ShowAll()
+
+	c.loadFiles()
+}
+
+func (c *commitWindow) setupTreeView() bool {
+	store, err := gtk.ListStoreNew(glib.TYPE_BOOLEAN, glib.TYPE_BOOLEAN, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_INT)
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return false
+	}
+	c.store = store
+
+	c.treeView = c.builder.GetObject("fileTreeView").(*gtk.TreeView)
+	c.treeView.SetModel(store)
+
+	toggle, err := gtk.CellRendererToggleNew()
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return false
+	}
+	toggle.SetActivatable(true)
+	toggle.Connect("toggled", c.fileToggled)
+	column, err := gtk.TreeViewColumnNewWithAttribute("Staged", toggle, "active", commitColumnStaged)
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return false
+	}
+	column.AddAttribute(toggle, "inconsistent", commitColumnPartly)
+	c.treeView.AppendColumn(column)
+
+	for _, col := range []struct {
+		title  string
+		column int
+	}{{"Status", commitColumnStatus}, {"Path", commitColumnPath}} {
+		renderer, err := gtk.CellRendererTextNew()
+		if err != nil {
+			c.mainWindow.logger.Error(err)
+			return false
+		}
+		column, err := gtk.TreeViewColumnNewWithAttribute(col.title, renderer, "text", col.column)
+		if err != nil {
+			c.mainWindow.logger.Error(err)
+			return false
+		}
+		column.SetResizable(true)
+		c.treeView.AppendColumn(column)
+	}
+
+	selection, err := c.treeView.GetSelection()
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return false
+	}
+	selection.Connect("changed", c.selectionChanged)
+	return true
+}
+
+func (c *commitWindow) closeWindow() {
+	c.window.Hide()
+	c.window = nil
+
+	// Update the status of the repository in the main window
+	c.mainWindow.refreshSingleRepository(c.repo.Path())
+}
+
+// loadFiles loads the changed files in the background
+func (c *commitWindow) loadFiles() {
+	path := c.repo.Path()
+	go func() {
+		files, err := gitdiscover.GetChangedFiles(context.Background(), path)
+		glib.IdleAdd(func() {
+			// The window is closed
+			if c.window == nil {
+				return
+			}
+			if err != nil {
+				c.showError(err)
+				return
+			}
+			c.filesLoaded(files)
+		})
+	}()
+}
+
+func (c *commitWindow) filesLoaded(files []*gitdiscover.ChangedFile) {
+	c.files = files
+	c.store.Clear()
+
+	selectedPath := c.selectedPath
+	c.selectedPath = ""
+	c.diffBuffer.SetText("")
+
+	selection, err := c.treeView.GetSelection()
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return
+	}
+	for i, file := range files {
+		iter := c.store.Append()
+		err := c.store.Set(iter,
+			[]int{commitColumnStaged, commitColumnPartly, commitColumnStatus, commitColumnPath, commitColumnIndex},
+			[]interface{}{file.IsStaged(), file.IsStaged() && file.IsUnstaged(), file.Status(),
+				c.getDisplayPath(file), i})
+		if err != nil {
+			c.mainWindow.logger.Error(err)
+			continue
+		}
+		// Keep the selected file selected
+		if file.Path == selectedPath {
+			selection.SelectIter(iter)
+		}
+	}
+
+	if len(files) == 0 {
+		c.diffBuffer.SetText("No changes")
+	}
+}
+
+// getDisplayPath returns the path of the file, and the original path for renamed files
+func (c *commitWindow) getDisplayPath(file *gitdiscover.ChangedFile) string {
+	if file.OrigPath != "" {
+		return file.OrigPath + " -> " + file.Path
+	}
+	return file.Path
+}
+
+// getFile returns the file in the row at iter
+func (c *commitWindow) getFile(iter *gtk.TreeIter) *gitdiscover.ChangedFile {
+	value, err := c.store.GetValue(iter, commitColumnIndex)
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return nil
+	}
+	index, err := value.GoValue()
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return nil
+	}
+	i, ok := index.(int)
+	if !ok || i < 0 || i >= len(c.files) {
+		return nil
+	}
+	return c.files[i]
+}
+
+// fileToggled stages the file if it has unstaged changes, and unstages it otherwise
+func (c *commitWindow) fileToggled(_ *gtk.CellRendererToggle, treePath string) {
+	iter, err := c.store.GetIterFromString(treePath)
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return
+	}
+	file := c.getFile(iter)
+	if file == nil {
+		return
+	}
+
+	paths := []string{file.Path}
+	if file.OrigPath != "" {
+		paths = append(paths, file.OrigPath)
+	}
+	stage := file.IsUnstaged()
+	repoPath := c.repo.Path()
+	go func() {
+		var err error
+		if stage {
+			err = gitdiscover.StageFiles(context.Background(), repoPath, paths)
+		} else {
+			err = gitdiscover.UnstageFiles(context.Background(), repoPath, paths)
+		}
+		glib.IdleAdd(func() {
+			// The window is closed
+			if c.window == nil {
+				return
+			}
+			if err != nil {
+				c.showError(err)
+			}
+			c.loadFiles()
+		})
+	}()
+}
+
+func (c *commitWindow) selectionChanged(selection *gtk.TreeSelection) {
+	_, iter, ok := selection.GetSelected()
+	if !ok {
+		return
+	}
+	file := c.getFile(iter)
+	if file == nil || file.Path == c.selectedPath {
+		return
+	}
+	c.selectedPath = file.Path
+	c.diffBuffer.SetText("Loading...")
+
+	repoPath := c.repo.Path()
+	go func() {
+		staged, unstaged, err := gitdiscover.GetFileDiff(context.Background(), repoPath, file)
+		glib.IdleAdd(func() {
+			// The window is closed, or another file is selected
+			if c.window == nil || c.selectedPath != file.Path {
+				return
+			}
+			c.showDiff(staged, unstaged, err)
+		})
+	}()
+}
+
+func (c *commitWindow) showDiff(staged, unstaged string, err error) {
+	c.diffBuffer.SetText("")
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		c.diffBuffer.SetText(err.Error())
+		return
+	}
+
+	var text string
+	if staged != "" {
+		text += `<span weight="bold" color="green">Staged changes</span>` + "\n\n" + gitoutput.DiffToPango(staged)
+	}
+	if unstaged != "" {
+		if text != "" {
+			text += "\n"
+		}
+		text += `<span weight="bold" color="red">Unstaged changes</span>` + "\n\n" + gitoutput.DiffToPango(unstaged)
+	}
+	c.diffBuffer.InsertMarkup(c.diffBuffer.GetStartIter(), text)
+}
+
+func (c *commitWindow) getMessage() string {
+	start, end := c.messageBuffer.GetBounds()
+	message, err := c.messageBuffer.GetText(start, end, false)
+	if err != nil {
+		c.mainWindow.logger.Error(err)
+		return ""
+	}
+	return message
+}
+
+// updateHints shows the length of the subject, and hints about the format of the message
+func (c *commitWindow) updateHints() {
+	message := c.getMessage()
+	subject := strings.SplitN(message, "\n", 2)[0]
+	text := fmt.Sprintf("Subject : %d characters", len([]rune(subject)))
+	for _, hint := range gitdiscover.CheckCommitMessage(message) {
+		text += `   <span color="orange">` + gitoutput.EscapeMarkup(hint) + `</span>`
+	}
+	c.hintsLabel.SetMarkup(text)
+}
+
+// amendToggled loads the message of the last commit, when amend is checked
+// and no message has been entered
+func (c *commitWindow) amendToggled() {
+	if !c.amend.GetActive() || strings.TrimSpace(c.getMessage()) != "" {
+		return
+	}
+
+	repoPath := c.repo.Path()
+	go func() {
+		message, err := gitdiscover.GetLastCommitMessage(context.Background(), repoPath)
+		glib.IdleAdd(func() {
+			// The window is closed
+			if c.window == nil {
+				return
+			}
+			if err != nil {
+				c.showError(err)
+				return
+			}
+			if strings.TrimSpace(c.getMessage()) == "" {
+				c.messageBuffer.SetText(message)
+			}
+		})
+	}()
+}
+
+func (c *commitWindow) commit() {
+	message := c.getMessage()
+	amend := c.amend.GetActive()
+	repoPath := c.repo.Path()
+
+	c.commitButton.SetSensitive(false)
+	c.showProgress("Committing")
+	go func() {
+		output, err := gitdiscover.CommitChanges(context.Background(), repoPath, message, amend)
+		glib.IdleAdd(func() {
+			// The window is closed
+			if c.window == nil {
+				return
+			}
+			c.commitButton.SetSensitive(true)
+			if err != nil {
+				c.showError(err)
+				return
+			}
+
+			// Show the first line, like "[main 1a2b3c4] Fix the refresh"
+			output = strings.SplitN(output, "\n", 2)[0]
+			c.showDone(output)
+			c.messageBuffer.SetText("")
+			c.amend.SetActive(false)
+			c.loadFiles()
+		})
+	}()
+}
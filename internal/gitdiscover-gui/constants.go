@@ -6,14 +6,6 @@ const (
 	applicationCopyRight = "©SoftTeam AB, 2021"
 )
 
-type sortByColumnType int
-
-const (
-	sortByName sortByColumnType = iota
-	sortByModifiedDate
-	sortByChanges
-)
-
 type externalApplicationModeType int
 
 const (
@@ -26,5 +18,5 @@ type gitCommandType uint
 const (
 	outputGitStatus gitCommandType = iota
 	outputGitDiff
-	outputGitLog
+	outputBulkSummary
 )
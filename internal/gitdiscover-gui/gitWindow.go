@@ -0,0 +1,68 @@
+package gitdiscover_gui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+	"github.com/hultan/gitdiscover/internal/gitoutput"
+	"github.com/hultan/softteam/framework"
+)
+
+// gitWindow contains the fields, and the helpers, that are shared by the
+// windows that run git actions on a repository (branches, commits, stashes
+// and tags). The state of the actions is shown in stateLabel.
+type gitWindow struct {
+	mainWindow *MainWindow
+	window     *gtk.Window
+	builder    *framework.GtkBuilder
+	repo       *gitdiscover.Repository
+	stateLabel *gtk.Label
+	// reload reloads the window when an action is done
+	reload func()
+}
+
+// runAction runs a git action in the background, and reloads the window when it is done
+func (w *gitWindow) runAction(description string, action func(ctx context.Context, repoPath string) error) {
+	w.showProgress(description)
+	path := w.repo.Path()
+	go func() {
+		err := action(context.Background(), path)
+		glib.IdleAdd(func() {
+			// The window is closed
+			if w.window == nil {
+				return
+			}
+			if err != nil {
+				w.showError(err)
+			} else {
+				w.showDone(description)
+			}
+			w.reload()
+		})
+	}()
+}
+
+// showProgress shows that an action is running, like "Committing..."
+func (w *gitWindow) showProgress(description string) {
+	w.stateLabel.SetMarkup(`<span color="orange">` + gitoutput.EscapeMarkup(description) + `...</span>`)
+	w.stateLabel.SetTooltipText("")
+}
+
+// showDone shows that an action is done, like "Deleted main"
+func (w *gitWindow) showDone(description string) {
+	w.stateLabel.SetMarkup(`<span color="green">` + gitoutput.EscapeMarkup(description) + `</span>`)
+	w.stateLabel.SetTooltipText("")
+}
+
+// showError shows the first line of the error from git in the
+// window, with the full text in the tooltip
+func (w *gitWindow) showError(err error) {
+	w.mainWindow.logger.Error(err)
+	firstLine := strings.SplitN(err.Error(), "\n", 2)[0]
+	w.stateLabel.SetMarkup(`<span color="red">` + gitoutput.EscapeMarkup(firstLine) + `</span>`)
+	w.stateLabel.SetTooltipText(err.Error())
+}
@@ -0,0 +1,321 @@
+package gitdiscover_gui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+	"github.com/hultan/gitdiscover/internal/gitoutput"
+	"github.com/hultan/softteam/framework"
+)
+
+// historyPageSize is the number of commits on each page in the history window
+const historyPageSize = 100
+
+// The columns in the commit list store
+const (
+	historyColumnHash = iota
+	historyColumnShortHash
+	historyColumnDate
+	historyColumnAuthor
+	historyColumnSubject
+	historyColumnRefs
+)
+
+type historyWindow struct {
+	mainWindow *MainWindow
+	window     *gtk.Window
+	builder    *framework.GtkBuilder
+	repo       *gitdiscover.Repository
+
+	store          *gtk.ListStore
+	treeView       *gtk.TreeView
+	detailsBuffer  *gtk.TextBuffer
+	searchField    *gtk.ComboBoxText
+	searchEntry    *gtk.SearchEntry
+	pageLabel      *gtk.Label
+	previousButton *gtk.Button
+	nextButton     *gtk.Button
+
+	query gitdiscover.HistoryQuery
+	// selectedHash is the commit that is shown in the detail pane
+	selectedHash string
+	// cancel stops loading the history, or the details of a commit
+	cancel context.CancelFunc
+}
+
+func newHistoryWindow(mainWindow *MainWindow) *historyWindow {
+	history := new(historyWindow)
+	history.mainWindow = mainWindow
+	return history
+}
+
+func (h *historyWindow) openWindow(repo *gitdiscover.Repository) {
+	// Create a new softBuilder
+	builder, err := fw.Gtk.CreateBuilder("historyWindow.ui")
+	if err != nil {
+		panic(err)
+	}
+	h.builder = builder
+	h.repo = repo
+	h.query = gitdiscover.HistoryQuery{Limit: historyPageSize}
+
+	window := h.builder.GetObject("historyWindow").(*gtk.Window)
+	window.Connect("destroy", h.closeWindow)
+	window.SetTitle("History...")
+	window.HideOnDelete()
+	window.SetModal(true)
+	window.SetKeepAbove(true)
+	window.SetPosition(gtk.WIN_POS_CENTER_ALWAYS)
+
+	label := h.builder.GetObject("labelHeader").(*gtk.Label)
+	label.SetText("History of " + repo.Path())
+
+	button := h.builder.GetObject("closeButton").(*gtk.Button)
+	button.Connect("clicked", h.closeWindow)
+
+	h.previousButton = h.builder.GetObject("previousButton").(*gtk.Button)
+	h.previousButton.Connect("clicked", func() {
+		h.query.Skip -= historyPageSize
+		if h.query.Skip < 0 {
+			h.query.Skip = 0
+		}
+		h.loadHistory()
+	})
+
+	h.nextButton = h.builder.GetObject("nextButton").(*gtk.Button)
+	h.nextButton.Connect("clicked", func() {
+		h.query.Skip += historyPageSize
+		h.loadHistory()
+	})
+
+	h.pageLabel = h.builder.GetObject("labelPage").(*gtk.Label)
+	h.searchField = h.builder.GetObject("searchFieldCombo").(*gtk.ComboBoxText)
+	h.searchEntry = h.builder.GetObject("searchEntry").(*gtk.SearchEntry)
+	h.searchEntry.Connect("activate", h.search)
+
+	textView := h.builder.GetObject("detailsTextView").(*gtk.TextView)
+	h.detailsBuffer, err = gtk.TextBufferNew(nil)
+	if err != nil {
+		h.mainWindow.logger.Error(err)
+		return
+	}
+	textView.SetBuffer(h.detailsBuffer)
+
+	if !h.setupTreeView() {
+		return
+	}
+
+	h.window = window
+	window.ShowAll()
+
+	h.loadHistory()
+}
+
+func (h *historyWindow) setupTreeView() bool {
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		h.mainWindow.logger.Error(err)
+		return false
+	}
+	h.store = store
+
+	h.treeView = h.builder.GetObject("commitTreeView").(*gtk.TreeView)
+	h.treeView.SetModel(store)
+
+	columns := []struct {
+		title  string
+		column int
+	}{
+		{"Hash", historyColumnShortHash},
+		{"Date", historyColumnDate},
+		{"Author", historyColumnAuthor},
+		{"Subject", historyColumnSubject},
+		{"Refs", historyColumnRefs},
+	}
+	for _, c := range columns {
+		renderer, err := gtk.CellRendererTextNew()
+		if err != nil {
+			h.mainWindow.logger.Error(err)
+			return false
+		}
+		column, err := gtk.TreeViewColumnNewWithAttribute(c.title, renderer, "text", c.column)
+		if err != nil {
+			h.mainWindow.logger.Error(err)
+			return false
+		}
+		column.SetResizable(true)
+		column.SetExpand(c.column == historyColumnSubject)
+		h.treeView.AppendColumn(column)
+	}
+
+	selection, err := h.treeView.GetSelection()
+	if err != nil {
+		h.mainWindow.logger.Error(err)
+		return false
+	}
+	selection.Connect("changed", h.selectionChanged)
+	return true
+}
+
+func (h *historyWindow) closeWindow() {
+	h.cancelLoading()
+	h.window.Hide()
+	h.window = nil
+}
+
+func (h *historyWindow) cancelLoading() {
+	if h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+}
+
+// search starts over on the first page, with the search text in the selected field
+func (h *historyWindow) search() {
+	text, err := h.searchEntry.GetText()
+	if err != nil {
+		h.mainWindow.logger.Error(err)
+		return
+	}
+	text = strings.TrimSpace(text)
+
+	h.query = gitdiscover.HistoryQuery{Limit: historyPageSize}
+	switch h.searchField.GetActiveText() {
+	case "Author":
+		h.query.Author = text
+	case "Path":
+		h.query.Path = text
+	default:
+		h.query.Message = text
+	}
+	h.loadHistory()
+}
+
+// loadHistory loads the current page of commits in the background
+func (h *historyWindow) loadHistory() {
+	h.cancelLoading()
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	// The list is cleared while loading, since selecting a commit would cancel the loading
+	h.store.Clear()
+	h.selectedHash = ""
+	h.detailsBuffer.SetText("")
+	h.previousButton.SetSensitive(false)
+	h.nextButton.SetSensitive(false)
+	h.pageLabel.SetText("Loading...")
+
+	path, query := h.repo.Path(), h.query
+	go func() {
+		commits, err := gitdiscover.GetHistory(ctx, path, query)
+		glib.IdleAdd(func() {
+			// The window is closed, or another page is loading
+			if h.window == nil || ctx.Err() != nil {
+				return
+			}
+			h.cancel = nil
+			cancel()
+			h.historyLoaded(commits, err)
+		})
+	}()
+}
+
+func (h *historyWindow) historyLoaded(commits []*gitdiscover.Commit, err error) {
+	h.store.Clear()
+	h.selectedHash = ""
+	h.detailsBuffer.SetText("")
+
+	if err != nil {
+		h.mainWindow.logger.Error(err)
+		h.pageLabel.SetText("Failed to load history")
+		h.detailsBuffer.SetText(err.Error())
+		return
+	}
+
+	dateFormat := h.mainWindow.discover.GetDateFormat()
+	for _, commit := range commits {
+		iter := h.store.Append()
+		err = h.store.Set(iter,
+			[]int{historyColumnHash, historyColumnShortHash, historyColumnDate,
+				historyColumnAuthor, historyColumnSubject, historyColumnRefs},
+			[]interface{}{commit.Hash, commit.ShortHash, commit.Date.Format(dateFormat),
+				commit.Author, commit.Subject, strings.Join(commit.Refs, ", ")})
+		if err != nil {
+			h.mainWindow.logger.Error(err)
+		}
+	}
+
+	switch {
+	case len(commits) == 0:
+		h.pageLabel.SetText("No commits")
+	default:
+		h.pageLabel.SetText(fmt.Sprintf("Commits %d - %d", h.query.Skip+1, h.query.Skip+len(commits)))
+	}
+	h.previousButton.SetSensitive(h.query.Skip > 0)
+	h.nextButton.SetSensitive(len(commits) == historyPageSize)
+}
+
+func (h *historyWindow) selectionChanged(selection *gtk.TreeSelection) {
+	_, iter, ok := selection.GetSelected()
+	if !ok {
+		return
+	}
+	value, err := h.store.GetValue(iter, historyColumnHash)
+	if err != nil {
+		h.mainWindow.logger.Error(err)
+		return
+	}
+	hash, err := value.GetString()
+	if err != nil {
+		h.mainWindow.logger.Error(err)
+		return
+	}
+	if hash == h.selectedHash {
+		return
+	}
+	h.selectedHash = hash
+	h.detailsBuffer.SetText("Loading...")
+
+	// Stop loading the details of the previously selected commit
+	h.cancelLoading()
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+
+	path := h.repo.Path()
+	go func() {
+		message, diff, err := gitdiscover.GetCommitDetails(ctx, path, hash)
+		glib.IdleAdd(func() {
+			// The window is closed, or another commit is selected
+			if h.window == nil || ctx.Err() != nil {
+				return
+			}
+			h.cancel = nil
+			cancel()
+			h.showDetails(message, diff, err)
+		})
+	}()
+}
+
+// showDetails shows the message, with the subject in bold, and the diff of a commit
+func (h *historyWindow) showDetails(message, diff string, err error) {
+	h.detailsBuffer.SetText("")
+	if err != nil {
+		h.mainWindow.logger.Error(err)
+		h.detailsBuffer.SetText(err.Error())
+		return
+	}
+
+	subject, body := message, ""
+	if i := strings.Index(message, "\n"); i >= 0 {
+		subject, body = message[:i], message[i:]
+	}
+	text := `<span weight="bold">` + gitoutput.EscapeMarkup(subject) + "</span>" +
+		gitoutput.EscapeMarkup(body) + "\n\n" + gitoutput.DiffToPango(diff)
+	h.detailsBuffer.InsertMarkup(h.detailsBuffer.GetStartIter(), text)
+}
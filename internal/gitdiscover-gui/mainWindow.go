@@ -1,9 +1,12 @@
 package gitdiscover_gui
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"time"
 
+	"github.com/gotk3/gotk3/glib"
 	"github.com/gotk3/gotk3/gtk"
 	"github.com/sirupsen/logrus"
 
@@ -22,16 +25,41 @@ type MainWindow struct {
 	config   *config.Config
 	discover *gitdiscover.Discover
 
-	builder           *framework.GtkBuilder
-	window            *gtk.ApplicationWindow
-	repositoryListBox *gtk.ListBox
-	infoBar           *infoBarHandler
-	toolBar           *gtk.Toolbar
-
-	sortBy             sortByColumnType
-	sortByName         *gtk.RadioMenuItem
-	sortByModifiedDate *gtk.RadioMenuItem
-	sortByChanges      *gtk.RadioMenuItem
+	builder            *framework.GtkBuilder
+	window             *gtk.ApplicationWindow
+	repositoryTreeView *gtk.TreeView
+	repositoryStore    *gtk.TreeStore
+	repositoryFilter   *gtk.TreeModelFilter
+	// repositoryIters is the row of each repository path in repositoryStore
+	repositoryIters map[string]*gtk.TreeIter
+	// columns are the columns of the repository list, by name
+	columns       map[string]*gtk.TreeViewColumn
+	infoBar       *infoBarHandler
+	toolBar       *gtk.Toolbar
+	refreshCancel context.CancelFunc
+	watcher       *gitdiscover.Watcher
+	fetchCancel   context.CancelFunc
+	fetchTimer    glib.SourceHandle
+	failedFetches map[string]time.Time
+
+	sortOrder *gitdiscover.SortOrder
+	// sortMenuItems are the items in the sort menu, by the column they sort by
+	sortMenuItems map[gitdiscover.SortColumn]*gtk.RadioMenuItem
+	// sortByOther is a hidden item in the sort menu, that is active when
+	// the list is sorted by a column that is not in the sort menu
+	sortByOther        *gtk.RadioMenuItem
+	favoritesFirstItem *gtk.CheckMenuItem
+	// updatingSortMenu is true while the sort menu is changed by code, and not by the user
+	updatingSortMenu bool
+	filterCategory   *gitdiscover.ChangeCategory
+	filterBar        *filterBar
+	filter           *gitdiscover.Filter
+	// sectionKeys is the section (like "favorites") of each repository path in the list
+	sectionKeys map[string]string
+	// updatingList is true while the list is changed by code, and not by the user
+	updatingList bool
+	// loaded is true when the first refresh is done
+	loaded bool
 }
 
 // NewMainWindow creates a new MainWindow object
@@ -64,8 +92,8 @@ func (m *MainWindow) OpenMainWindow(app *gtk.Application) {
 	m.window.SetTitle(fmt.Sprintf("%s - %s", applicationTitle, applicationVersion))
 	_ = m.window.Connect("destroy", m.closeMainWindow)
 
-	// Discover
-	m.discover = gitdiscover.NewDiscover(m.config)
+	// Discover (the repositories are loaded by refreshRepositoryList)
+	m.discover = gitdiscover.NewEmptyDiscover(m.config)
 
 	// Toolbar
 	m.toolBar = m.builder.GetObject("toolbar").(*gtk.Toolbar)
@@ -83,21 +111,62 @@ func (m *MainWindow) OpenMainWindow(app *gtk.Application) {
 	labelInfoBar := m.builder.GetObject("labelInfoBar").(*gtk.Label)
 	m.infoBar = newInfoBar(infoBar, labelInfoBar)
 
-	// Repository list box
-	m.repositoryListBox = m.builder.GetObject("repositoryListBox").(*gtk.ListBox)
+	// Repository list
+	m.repositoryTreeView = m.builder.GetObject("repositoryTreeView").(*gtk.TreeView)
+	m.setupRepositoryList()
+	m.setupSections()
+	m.setupDragAndDrop()
+
+	// Filter bar
+	m.setupFilterBar()
+
+	// Keyboard shortcuts and the command palette
+	m.setupShortcuts()
 
 	// Refresh repository list
 	m.refreshRepositoryList()
 
+	// Watch the repositories for changes
+	if m.config.AutoUpdate {
+		m.startWatcher()
+	}
+
+	// Fetch repositories with remotes in the background
+	m.startFetchTimer()
+
 	// Popup menu
 	popup := newPopupMenu(m)
 	popup.setupPopupMenu()
 
 	// Show the main window
+	if m.config.StartMaximized {
+		m.window.Maximize()
+	}
 	m.window.ShowAll()
 	m.infoBar.hideInfoBar()
 }
 
+func (m *MainWindow) openSettingsWindow() {
+	settings := newSettingsWindow(m)
+	settings.openWindow()
+}
+
+// applySettings applies changed settings to the main window
+func (m *MainWindow) applySettings() {
+	if m.config.AutoUpdate {
+		m.startWatcher()
+	} else {
+		m.stopWatcher()
+	}
+
+	// Date format and path column width (a running
+	// refresh will show the new settings when it is done)
+	m.applyPathColumnWidth()
+	if m.refreshCancel == nil {
+		m.showRepositoryList()
+	}
+}
+
 func (m *MainWindow) openAboutDialog() {
 	about := newAboutDialog(m.logger, m.window)
 	about.openAboutDialog()
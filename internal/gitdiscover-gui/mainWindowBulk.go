@@ -0,0 +1,113 @@
+package gitdiscover_gui
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+	"github.com/hultan/softteam/framework"
+)
+
+// Operations in the order of the menu items
+var bulkOperations = []struct {
+	name      string
+	operation gitdiscover.BulkOperation
+}{
+	{"Fetch", gitdiscover.BulkFetch},
+	{"Pull", gitdiscover.BulkPull},
+	{"Push", gitdiscover.BulkPush},
+	{"Stash", gitdiscover.BulkStash},
+	{"Checkout", gitdiscover.BulkCheckout},
+}
+
+// setupBulkMenu connects the bulk operation menu items, that
+// have ids like prefix + "Fetch", to runBulkOperation
+func (m *MainWindow) setupBulkMenu(builder *framework.GtkBuilder, prefix string) {
+	for _, bulk := range bulkOperations {
+		operation := bulk.operation
+		item := builder.GetObject(prefix + bulk.name).(*gtk.MenuItem)
+		_ = item.Connect("activate", func() {
+			m.runBulkOperation(operation)
+		})
+	}
+}
+
+// getSelectedRepos returns the selected git repositories
+func (m *MainWindow) getSelectedRepos() gitdiscover.Repositories {
+	var repos gitdiscover.Repositories
+	for _, repo := range m.getSelectedRepositories() {
+		if repo.IsGit() {
+			repos = append(repos, repo)
+		}
+	}
+	return repos
+}
+
+// runBulkOperation runs the operation in all the selected git repositories in
+// the background, and shows a summary in an output window when it is done.
+func (m *MainWindow) runBulkOperation(operation gitdiscover.BulkOperation) {
+	repos := m.getSelectedRepos()
+	if len(repos) == 0 {
+		m.infoBar.showInfoWithTimeout("Please select one or more git repositories (use Ctrl+click)...", 5)
+		return
+	}
+
+	var branch string
+	if operation == gitdiscover.BulkCheckout {
+		var ok bool
+		branch, ok = m.askForBranch(len(repos))
+		if !ok {
+			return
+		}
+	}
+
+	var paths []string
+	for _, repo := range repos {
+		paths = append(paths, repo.Path())
+	}
+	header := fmt.Sprintf("%s in %d repositories", operation, len(paths))
+	if branch != "" {
+		header = fmt.Sprintf("%s %s in %d repositories", operation, branch, len(paths))
+	}
+	m.infoBar.showInfo("Running " + header + "...")
+
+	go func() {
+		results, err := gitdiscover.RunBulkOperation(context.Background(), paths, operation, branch, nil)
+		glib.IdleAdd(func() {
+			// The main window is closed
+			if m.window == nil {
+				return
+			}
+			if err != nil {
+				m.logger.Error(err)
+				m.infoBar.showError(err.Error())
+				return
+			}
+			m.infoBar.hideInfoBar()
+
+			output := newOutputWindow(m.builder, m.logger)
+			output.openWindow(header, gitdiscover.FormatBulkResults(results), outputBulkSummary)
+
+			for _, path := range paths {
+				m.refreshSingleRepository(path)
+			}
+		})
+	}()
+}
+
+// askForBranch asks the user for the name of the branch to check out
+func (m *MainWindow) askForBranch(count int) (string, bool) {
+	branch, ok := m.askForText(m.window, "Checkout branch...",
+		fmt.Sprintf("Branch to check out in %d repositories :", count), "Checkout", "")
+	if !ok {
+		return "", false
+	}
+	if branch == "" {
+		m.infoBar.showInfoWithTimeout("Please enter a branch name...", 5)
+		return "", false
+	}
+	return branch, true
+}
@@ -0,0 +1,102 @@
+package gitdiscover_gui
+
+import (
+	"strings"
+
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// Get the display name of a change category, like "Untracked"
+func getChangeCategoryLabel(category gitdiscover.ChangeCategory) string {
+	name := category.String()
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// setupSortByChangeCategoryMenu adds an item to the sort menu for each change category
+func (m *MainWindow) setupSortByChangeCategoryMenu(group *gtk.RadioMenuItem) {
+	menu := m.builder.GetObject("mnuSortByChangeCategoryMenu").(*gtk.Menu)
+
+	// The total number of changes is already in the sort menu
+	for _, category := range gitdiscover.ChangeCategories[1:] {
+		item, err := gtk.RadioMenuItemNewWithLabelFromWidget(group, getChangeCategoryLabel(category))
+		if err != nil {
+			m.logger.Error(err)
+			continue
+		}
+		m.addSortMenuItem(item, gitdiscover.ChangeCategorySortColumn(category))
+		menu.Append(item)
+		item.Show()
+	}
+}
+
+func (m *MainWindow) setupFilterMenu() {
+	menu := m.builder.GetObject("mnuFilterMenu").(*gtk.Menu)
+
+	all, err := gtk.RadioMenuItemNewWithLabel(nil, "All repositories")
+	if err != nil {
+		m.logger.Error(err)
+		return
+	}
+	all.SetActive(true)
+	_ = all.Connect("activate", func(radio *gtk.RadioMenuItem) {
+		if !radio.GetActive() {
+			return
+		}
+		m.setFilterCategory(nil)
+	})
+	menu.Append(all)
+	all.Show()
+
+	for _, category := range gitdiscover.ChangeCategories {
+		category := category
+		label := "With " + category.String()
+		if category == gitdiscover.ChangesTotal {
+			label = "With changes"
+		}
+		item, err := gtk.RadioMenuItemNewWithLabelFromWidget(all, label)
+		if err != nil {
+			m.logger.Error(err)
+			continue
+		}
+		_ = item.Connect("activate", func(radio *gtk.RadioMenuItem) {
+			if !radio.GetActive() {
+				return
+			}
+			m.setFilterCategory(&category)
+		})
+		menu.Append(item)
+		item.Show()
+	}
+}
+
+func (m *MainWindow) setFilterCategory(category *gitdiscover.ChangeCategory) {
+	m.filterCategory = category
+	m.refilterRepositoryList()
+}
+
+// refilterRepositoryList shows and hides the rows after the filter has changed
+func (m *MainWindow) refilterRepositoryList() {
+	m.updatingList = true
+	m.repositoryFilter.Refilter()
+	m.updatingList = false
+	// Sections that lose all their rows are collapsed by the tree view
+	m.expandSections()
+}
+
+// Returns true if the row should be visible in the repository list
+func (m *MainWindow) filterRepositoryRow(model *gtk.TreeModel, iter *gtk.TreeIter) bool {
+	repo := m.getRepoFromIter(model, iter)
+	if repo == nil {
+		// Sections and rows that are still loading
+		return true
+	}
+	if !m.matchFilter(repo) {
+		return false
+	}
+	if m.filterCategory == nil {
+		return true
+	}
+	return repo.IsGit() && repo.ChangeSummary().Count(*m.filterCategory) > 0
+}
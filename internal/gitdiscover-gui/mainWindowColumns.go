@@ -0,0 +1,284 @@
+package gitdiscover_gui
+
+import (
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/config"
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// The columns in the repository tree store
+const (
+	repositoryColumnIcon = iota
+	repositoryColumnFavorite
+	repositoryColumnDate
+	repositoryColumnPath
+	repositoryColumnStashes
+	repositoryColumnLatestTag
+	repositoryColumnGitStatus
+	repositoryColumnGoStatus
+	repositoryColumnRemote
+	repositoryColumnTooltip
+	// repositoryColumnRepoPath is the path of the repository, it is empty
+	// for the section rows and the rows that are still loading
+	repositoryColumnRepoPath
+	// repositoryColumnSection is the key of the section that the row is in
+	repositoryColumnSection
+)
+
+// listColumn is a column in the repository list
+type listColumn struct {
+	// name identifies the column in the config
+	name    string
+	title   string
+	tooltip string
+	column  int
+	pixbuf  bool
+	// visible is the default visibility of the column
+	visible bool
+	// sortColumn is the column that the list is sorted by when the header
+	// is clicked, the header can not be clicked if it is empty
+	sortColumn gitdiscover.SortColumn
+}
+
+// listColumns are the columns of the repository list, in the default order
+var listColumns = []listColumn{
+	{name: "icon", title: "Icon", column: repositoryColumnIcon, pixbuf: true, visible: true,
+		tooltip: "Repository icon, should be called application.png in the assets folder."},
+	{name: "favorite", title: "Favorite", column: repositoryColumnFavorite, pixbuf: true, visible: true,
+		tooltip: "Is the repository marked as a user favorite?"},
+	{name: "date", title: "Date", column: repositoryColumnDate, visible: true,
+		tooltip: "Modified date of the git repository folder.", sortColumn: gitdiscover.SortByModifiedDate},
+	{name: "path", title: "Path", column: repositoryColumnPath, visible: true,
+		tooltip: "Repository path", sortColumn: gitdiscover.SortByName},
+	{name: "stashes", title: "Stashes", column: repositoryColumnStashes, visible: true,
+		tooltip:    "The number of stashes in the repository.",
+		sortColumn: gitdiscover.ChangeCategorySortColumn(gitdiscover.ChangesStashes)},
+	{name: "tag", title: "Latest tag", column: repositoryColumnLatestTag,
+		tooltip:    "The latest version tag, and the number of commits since it.",
+		sortColumn: gitdiscover.SortByLatestTag},
+	{name: "git-status", title: "Git status", column: repositoryColumnGitStatus, visible: true,
+		tooltip:    "The branch and the status of the git branch (modified,added, deleted, etc...).",
+		sortColumn: gitdiscover.ChangeCategorySortColumn(gitdiscover.ChangesTotal)},
+	{name: "go-status", title: "Go status", column: repositoryColumnGoStatus, visible: true,
+		tooltip: "The go version set in the go.mod file.", sortColumn: gitdiscover.SortByGoStatus},
+	{name: "remote", title: "Remote", column: repositoryColumnRemote, visible: true,
+		tooltip: "The hosting provider of the remote repository.", sortColumn: gitdiscover.SortByRemote},
+}
+
+// setupRepositoryList creates the tree store of the repository list, and
+// the columns, in the order and with the widths from the config
+func (m *MainWindow) setupRepositoryList() {
+	store, err := gtk.TreeStoreNew(gdk.PixbufGetType(), gdk.PixbufGetType(), glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		m.logger.Panic(err)
+		panic(err)
+	}
+	m.repositoryStore = store
+
+	// The filter hides the rows that do not match the filter bar and the filter menu
+	filter, err := store.FilterNew(nil)
+	if err != nil {
+		m.logger.Panic(err)
+		panic(err)
+	}
+	filter.SetVisibleFunc(m.filterRepositoryRow)
+	m.repositoryFilter = filter
+
+	m.repositoryTreeView.SetModel(filter)
+	m.repositoryTreeView.SetTooltipColumn(repositoryColumnTooltip)
+	selection, err := m.repositoryTreeView.GetSelection()
+	if err != nil {
+		m.logger.Panic(err)
+		panic(err)
+	}
+	selection.SetMode(gtk.SELECTION_MULTIPLE)
+
+	// Columns in the config first, in the order they were in
+	// when GitDiscover was closed, then the new columns
+	m.columns = make(map[string]*gtk.TreeViewColumn)
+	for _, settings := range m.config.Columns {
+		if c := getListColumn(settings.Name); c != nil && m.columns[c.name] == nil {
+			m.appendColumn(*c)
+		}
+	}
+	for _, c := range listColumns {
+		if m.columns[c.name] == nil {
+			m.appendColumn(c)
+		}
+	}
+	m.updateSortIndicators()
+	m.setupColumnsMenu()
+	m.applyPathColumnWidth()
+}
+
+func getListColumn(name string) *listColumn {
+	for i := range listColumns {
+		if listColumns[i].name == name {
+			return &listColumns[i]
+		}
+	}
+	return nil
+}
+
+func (m *MainWindow) appendColumn(c listColumn) {
+	var column *gtk.TreeViewColumn
+	var err error
+	switch {
+	case c.pixbuf:
+		var renderer *gtk.CellRendererPixbuf
+		renderer, err = gtk.CellRendererPixbufNew()
+		if err != nil {
+			m.logger.Error(err)
+			return
+		}
+		column, err = gtk.TreeViewColumnNewWithAttribute(c.title, renderer, "pixbuf", c.column)
+	case c.column == repositoryColumnPath:
+		// The path renderer is in the glade file, since it ellipsizes the path
+		renderer := m.builder.GetObject("pathRenderer").(*gtk.CellRendererText)
+		column, err = gtk.TreeViewColumnNewWithAttribute(c.title, renderer, "markup", c.column)
+	default:
+		var renderer *gtk.CellRendererText
+		renderer, err = gtk.CellRendererTextNew()
+		if err != nil {
+			m.logger.Error(err)
+			return
+		}
+		column, err = gtk.TreeViewColumnNewWithAttribute(c.title, renderer, "markup", c.column)
+	}
+	if err != nil {
+		m.logger.Error(err)
+		return
+	}
+
+	// The header is a label, since columns can not have tooltips
+	label, err := gtk.LabelNew("")
+	if err != nil {
+		m.logger.Error(err)
+		return
+	}
+	label.SetMarkup(m.getMarkup(c.title, headerColor))
+	label.SetTooltipText(c.tooltip)
+	label.Show()
+	column.SetWidget(label)
+
+	column.SetResizable(true)
+	column.SetReorderable(true)
+	column.SetExpand(c.column == repositoryColumnPath)
+	if c.sortColumn != "" {
+		column.SetClickable(true)
+		_ = column.Connect("clicked", func() {
+			m.sortByColumn(c)
+		})
+	}
+
+	visible := c.visible
+	if settings := m.config.GetColumnByName(c.name); settings != nil {
+		visible = settings.Visible
+		if settings.Width > 0 {
+			column.SetFixedWidth(settings.Width)
+		}
+	}
+	column.SetVisible(visible)
+
+	m.columns[c.name] = column
+	m.repositoryTreeView.AppendColumn(column)
+}
+
+// setupColumnsMenu creates a check menu item for each column in View > Columns
+func (m *MainWindow) setupColumnsMenu() {
+	menu := m.builder.GetObject("menuViewColumnsMenu").(*gtk.Menu)
+	for _, c := range listColumns {
+		column := m.columns[c.name]
+		if column == nil {
+			continue
+		}
+		item, err := gtk.CheckMenuItemNewWithLabel(c.title)
+		if err != nil {
+			m.logger.Error(err)
+			continue
+		}
+		item.SetTooltipText(c.tooltip)
+		item.SetActive(column.GetVisible())
+		_ = item.Connect("toggled", func(item *gtk.CheckMenuItem) {
+			column.SetVisible(item.GetActive())
+			m.saveColumns()
+		})
+		menu.Append(item)
+		item.Show()
+	}
+}
+
+// saveColumns saves the order, widths and visibility of the columns in the config
+func (m *MainWindow) saveColumns() {
+	names := make(map[*gtk.TreeViewColumn]string)
+	for name, column := range m.columns {
+		names[column] = name
+	}
+
+	var columns []*config.Column
+	m.repositoryTreeView.GetColumns().Foreach(func(item interface{}) {
+		column, ok := item.(*gtk.TreeViewColumn)
+		if !ok {
+			return
+		}
+		// The list wraps the columns in new objects, so we compare the native pointers
+		for known, name := range names {
+			if known.Native() != column.Native() {
+				continue
+			}
+			// The fixed width is only set when the user has resized the column
+			width := known.GetFixedWidth()
+			if width < 0 {
+				width = 0
+			}
+			columns = append(columns, &config.Column{Name: name, Width: width, Visible: known.GetVisible()})
+		}
+	})
+	m.config.Columns = columns
+	m.saveConfig()
+}
+
+// applyPathColumnWidth sets the width of the path column (in characters) from the
+// settings, it is only used until the user resizes the column
+func (m *MainWindow) applyPathColumnWidth() {
+	renderer := m.builder.GetObject("pathRenderer").(*gtk.CellRendererText)
+	width := -1
+	if m.config.PathColumnWidth > 0 {
+		width = m.config.PathColumnWidth
+	}
+	if err := renderer.SetProperty("width-chars", width); err != nil {
+		m.logger.Error(err)
+	}
+}
+
+// sortByColumn sorts the list by a column when the column header is clicked,
+// clicking the header again reverses the sort order
+func (m *MainWindow) sortByColumn(c listColumn) {
+	m.sortBy(c.sortColumn)
+}
+
+// updateSortIndicators shows the sort indicator on the column of the first sort key
+func (m *MainWindow) updateSortIndicators() {
+	primary, hasPrimary := m.sortOrder.Primary()
+	for _, c := range listColumns {
+		column := m.columns[c.name]
+		if column == nil {
+			continue
+		}
+		sorted := hasPrimary && c.sortColumn != "" && c.sortColumn == primary.Column
+		column.SetSortIndicator(sorted)
+		if !sorted {
+			continue
+		}
+		if primary.Descending {
+			column.SetSortOrder(gtk.SortType(gtk.SORT_DESCENDING))
+		} else {
+			column.SetSortOrder(gtk.SortType(gtk.SORT_ASCENDING))
+		}
+	}
+}
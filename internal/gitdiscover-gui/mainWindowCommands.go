@@ -0,0 +1,227 @@
+package gitdiscover_gui
+
+import (
+	"fmt"
+
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+	"github.com/hultan/gitdiscover/internal/gitrunner"
+)
+
+// commandPaletteAccelerator opens the command palette
+const commandPaletteAccelerator = "<Control><Shift>p"
+
+// The external applications at the first positions in the list can be
+// opened with Ctrl+1 to Ctrl+9
+const maxExternalApplicationShortcuts = 9
+
+// command is an action in the command palette, that can also have a keyboard shortcut
+type command struct {
+	name string
+	// accelerator is the keyboard shortcut, like "<Control>n" (see gtk.AcceleratorParse)
+	accelerator string
+	action      func()
+}
+
+// getCommands returns the commands in the command palette. The commands
+// that use a repository run on the selected repository.
+func (m *MainWindow) getCommands() []command {
+	return append(m.getMainCommands(), m.getExternalApplicationCommands()...)
+}
+
+func (m *MainWindow) getMainCommands() []command {
+	return []command{
+		{"Refresh", "F5", m.refreshRepositoryList},
+		{"Fetch all", "", m.fetchAllButtonClicked},
+		{"Add folder...", "<Control>n", m.addRepositoryButtonClicked},
+		{"Edit folder...", "<Control>e", m.editRepositoryButtonClicked},
+		{"Remove folder", "<Control><Shift>Delete", m.removeRepositoryButtonClicked},
+		{"Toggle favorite", "<Control>d", m.toggleFavorite},
+		{"Git status...", "<Control><Shift>s", m.showGitStatus},
+		{"Git diff...", "<Control><Shift>d", m.showGitDiff},
+		{"Git history...", "<Control><Shift>l", m.openHistoryWindow},
+		{"Git commit...", "", m.openCommitWindow},
+		{"Git branches...", "", m.openBranchWindow},
+		{"Git stashes...", "", m.openStashWindow},
+		{"Git tags...", "", m.openTagWindow},
+		{"Filter", "<Control>f", m.focusFilterBar},
+		{"Sort order...", "", m.openSortWindow},
+		{"Export...", "", m.exportRepositories},
+		{"Settings...", "", m.openSettingsWindow},
+	}
+}
+
+func (m *MainWindow) getExternalApplicationCommands() []command {
+	var commands []command
+	for i, app := range m.discover.ExternalApplications {
+		name := app.Name
+		commands = append(commands, command{"Open in " + name, getExternalApplicationAccelerator(i), func() {
+			m.openInExternalApplication(name, m.getSelectedRepo())
+		}})
+	}
+	return commands
+}
+
+// Returns the keyboard shortcut of the external application at index, or
+// an empty string if it does not have a shortcut
+func getExternalApplicationAccelerator(index int) string {
+	if index >= maxExternalApplicationShortcuts {
+		return ""
+	}
+	return fmt.Sprintf("<Control>%d", index+1)
+}
+
+// getAcceleratorLabel returns the keyboard shortcut for display, like "Ctrl+N"
+func getAcceleratorLabel(accelerator string) string {
+	if accelerator == "" {
+		return ""
+	}
+	return gtk.AcceleratorGetLabel(gtk.AcceleratorParse(accelerator))
+}
+
+// setupShortcuts connects the keyboard shortcuts of the commands, and of the
+// command palette. The shortcuts of the external applications open the
+// application at that position, so they work when the applications are changed.
+func (m *MainWindow) setupShortcuts() {
+	group, err := gtk.AccelGroupNew()
+	if err != nil {
+		m.logger.Error(err)
+		return
+	}
+	m.window.AddAccelGroup(group)
+
+	for _, c := range m.getMainCommands() {
+		if c.accelerator != "" {
+			m.connectShortcut(group, c.accelerator, c.action)
+		}
+	}
+	for i := 0; i < maxExternalApplicationShortcuts; i++ {
+		index := i
+		m.connectShortcut(group, getExternalApplicationAccelerator(index), func() {
+			if index >= len(m.discover.ExternalApplications) {
+				return
+			}
+			app := m.discover.GetExternalApplicationByIndex(index)
+			m.openInExternalApplication(app.Name, m.getSelectedRepo())
+		})
+	}
+
+	// The menu item shows the shortcut of the command palette
+	item := m.builder.GetObject("menuViewCommandPalette").(*gtk.MenuItem)
+	_ = item.Connect("activate", m.openCommandPalette)
+	key, mods := gtk.AcceleratorParse(commandPaletteAccelerator)
+	item.AddAccelerator("activate", group, key, mods, gtk.ACCEL_VISIBLE)
+}
+
+func (m *MainWindow) connectShortcut(group *gtk.AccelGroup, accelerator string, action func()) {
+	key, mods := gtk.AcceleratorParse(accelerator)
+	group.Connect(key, mods, gtk.ACCEL_VISIBLE, func() bool {
+		action()
+		return true
+	})
+}
+
+func (m *MainWindow) openCommandPalette() {
+	palette := newCommandPalette(m)
+	palette.openWindow()
+}
+
+// focusFilterBar moves the keyboard focus to the filter entry
+func (m *MainWindow) focusFilterBar() {
+	m.filterBar.entry.GrabFocus()
+}
+
+// getSelectedRepoOrShowInfo returns the selected repository, or shows
+// an info message and returns nil if no repository is selected
+func (m *MainWindow) getSelectedRepoOrShowInfo() *gitdiscover.Repository {
+	repo := m.getSelectedRepo()
+	if repo == nil {
+		m.infoBar.showInfoWithTimeout("Please select a repo...", 5)
+	}
+	return repo
+}
+
+// getSelectedGitRepo returns the selected repository, or shows an info message
+// and returns nil if it is not a git repository, or if it is a bare repository
+// and needsWorkTree is true. The popup menu disables these items instead.
+func (m *MainWindow) getSelectedGitRepo(needsWorkTree bool) *gitdiscover.Repository {
+	repo := m.getSelectedRepoOrShowInfo()
+	switch {
+	case repo == nil:
+		return nil
+	case !repo.IsGit():
+		m.infoBar.showInfoWithTimeout(repo.Name()+" is not a git repository...", 5)
+		return nil
+	case needsWorkTree && repo.Kind() == gitdiscover.RepositoryKindBare:
+		m.infoBar.showInfoWithTimeout(repo.Name()+" is a bare repository, without a working tree...", 5)
+		return nil
+	default:
+		return repo
+	}
+}
+
+func (m *MainWindow) toggleFavorite() {
+	repo := m.getSelectedRepoOrShowInfo()
+	if repo == nil {
+		return
+	}
+	repo.SetIsFavorite(!repo.IsFavorite())
+	m.discover.Save()
+	m.refreshRepositoryList()
+}
+
+func (m *MainWindow) showGitStatus() {
+	m.runGitCommand(outputGitStatus, "status")
+}
+
+func (m *MainWindow) showGitDiff() {
+	m.runGitCommand(outputGitDiff, "diff")
+}
+
+// runGitCommand : Run a GIT command in the selected repository, and show the output while it is running
+func (m *MainWindow) runGitCommand(outputType gitCommandType, args ...string) {
+	repo := m.getSelectedGitRepo(true)
+	if repo == nil {
+		return
+	}
+
+	runner := gitrunner.NewRunner(repo.Path())
+	output := newOutputWindow(m.builder, m.logger)
+	output.runCommand("", outputType, runner, args...)
+}
+
+func (m *MainWindow) openHistoryWindow() {
+	if repo := m.getSelectedGitRepo(false); repo != nil {
+		history := newHistoryWindow(m)
+		history.openWindow(repo)
+	}
+}
+
+func (m *MainWindow) openCommitWindow() {
+	if repo := m.getSelectedGitRepo(true); repo != nil {
+		commit := newCommitWindow(m)
+		commit.openWindow(repo)
+	}
+}
+
+func (m *MainWindow) openBranchWindow() {
+	if repo := m.getSelectedGitRepo(false); repo != nil {
+		branches := newBranchWindow(m)
+		branches.openWindow(repo)
+	}
+}
+
+func (m *MainWindow) openStashWindow() {
+	if repo := m.getSelectedGitRepo(true); repo != nil {
+		stashes := newStashWindow(m)
+		stashes.openWindow(repo)
+	}
+}
+
+func (m *MainWindow) openTagWindow() {
+	if repo := m.getSelectedGitRepo(false); repo != nil {
+		tags := newTagWindow(m)
+		tags.openWindow(repo)
+	}
+}
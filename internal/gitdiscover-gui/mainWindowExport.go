@@ -0,0 +1,87 @@
+package gitdiscover_gui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+func (m *MainWindow) exportRepositories() {
+	// Create and show the save file dialog
+	dialog, err := gtk.FileChooserDialogNewWith2Buttons("Export repositories...",
+		m.window,
+		gtk.FILE_CHOOSER_ACTION_SAVE,
+		"Export",
+		gtk.RESPONSE_OK,
+		"Cancel",
+		gtk.RESPONSE_CANCEL)
+	if err != nil {
+		m.logger.Error(err)
+		m.infoBar.showError(err.Error())
+		return
+	}
+	defer dialog.Destroy()
+
+	m.addExportFilter(dialog, "JSON files", "*.json")
+	m.addExportFilter(dialog, "CSV files", "*.csv")
+	m.addExportFilter(dialog, "YAML files", "*.yaml", "*.yml")
+	dialog.SetCurrentName("gitdiscover.json")
+	dialog.SetDoOverwriteConfirmation(true)
+	dialog.SetModal(true)
+
+	response := dialog.Run()
+	if response != gtk.RESPONSE_OK {
+		return
+	}
+
+	// The export format is decided by the file extension
+	fileName := dialog.GetFilename()
+	extension := strings.TrimPrefix(filepath.Ext(fileName), ".")
+	format, err := gitdiscover.ParseExportFormat(extension)
+	if err != nil {
+		m.infoBar.showError("Please use a file name ending with .json, .csv or .yaml.")
+		return
+	}
+
+	err = m.exportRepositoriesToFile(fileName, format)
+	if err != nil {
+		m.logger.Error(err)
+		m.infoBar.showError(err.Error())
+		return
+	}
+
+	m.infoBar.showInfoWithTimeout(fmt.Sprintf("Exported repositories to %s.", fileName), 5)
+}
+
+func (m *MainWindow) exportRepositoriesToFile(fileName string, format gitdiscover.ExportFormat) error {
+	file, err := os.Create(fileName)
+	if err != nil {
+		return err
+	}
+
+	err = m.discover.Repositories.Export(file, format)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+func (m *MainWindow) addExportFilter(dialog *gtk.FileChooserDialog, name string, patterns ...string) {
+	filter, err := gtk.FileFilterNew()
+	if err != nil {
+		m.logger.Error(err)
+		return
+	}
+	filter.SetName(name)
+	for _, pattern := range patterns {
+		filter.AddPattern(pattern)
+	}
+	dialog.AddFilter(filter)
+}
@@ -0,0 +1,140 @@
+package gitdiscover_gui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gotk3/gotk3/glib"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// How often (in seconds) we check if any repository needs a background fetch
+const fetchCheckInterval = 60
+
+func (m *MainWindow) startFetchTimer() {
+	m.fetchTimer = glib.TimeoutSecondsAdd(fetchCheckInterval, func() bool {
+		if m.window == nil {
+			return false
+		}
+		m.fetchRepositories(false)
+		return true
+	})
+}
+
+func (m *MainWindow) stopFetchTimer() {
+	if m.fetchTimer == 0 {
+		return
+	}
+	glib.SourceRemove(m.fetchTimer)
+	m.fetchTimer = 0
+}
+
+func (m *MainWindow) cancelFetch() {
+	if m.fetchCancel != nil {
+		m.fetchCancel()
+		m.fetchCancel = nil
+	}
+}
+
+func (m *MainWindow) fetchAllButtonClicked() {
+	m.fetchRepositories(true)
+}
+
+// fetchRepositories fetches the repositories that have remotes in the background.
+// If force is false, only repositories that has not been fetched (or failed to be
+// fetched) during the last Config.FetchInterval minutes are fetched.
+func (m *MainWindow) fetchRepositories(force bool) {
+	if !force && m.config.FetchInterval <= 0 {
+		return
+	}
+
+	interval := time.Duration(m.config.FetchInterval) * time.Minute
+	if force {
+		interval = 0
+	}
+	now := time.Now()
+	var paths []string
+	for _, repo := range m.discover.Repositories {
+		if !repo.NeedsFetch(interval, now) || now.Sub(m.failedFetches[repo.Path()]) < interval {
+			continue
+		}
+		paths = append(paths, repo.Path())
+	}
+	m.startFetch(paths, force)
+}
+
+// startFetch fetches the repositories at paths in the background. If force
+// is true, the user has asked for the fetch, and the progress is shown.
+func (m *MainWindow) startFetch(paths []string, force bool) {
+	if m.fetchCancel != nil || m.refreshCancel != nil {
+		if force {
+			m.infoBar.showInfoWithTimeout("Please wait for the current refresh or fetch to finish...", 5)
+		}
+		return
+	}
+	if len(paths) == 0 {
+		if force {
+			m.infoBar.showInfoWithTimeout("There are no repositories with remotes to fetch!", 5)
+		}
+		return
+	}
+
+	if force {
+		m.infoBar.showInfo(fmt.Sprintf("Fetching %d repositories...", len(paths)))
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.fetchCancel = cancel
+	go func() {
+		results := gitdiscover.FetchRepositories(ctx, paths, nil)
+		glib.IdleAdd(func() {
+			// Cancelled, or the main window is closed
+			if ctx.Err() != nil || m.window == nil {
+				return
+			}
+			m.fetchCancel = nil
+			cancel()
+			m.fetchDone(results, force)
+		})
+	}()
+}
+
+// fetchDone stores the fetch times, refreshes the fetched
+// repositories and reports the repositories that failed
+func (m *MainWindow) fetchDone(results []gitdiscover.FetchResult, force bool) {
+	if m.failedFetches == nil {
+		m.failedFetches = make(map[string]time.Time)
+	}
+
+	var failed []string
+	for _, result := range results {
+		if errors.Is(result.Err, context.Canceled) {
+			continue
+		}
+		if result.Err != nil {
+			// Don't try again until the next fetch interval
+			m.failedFetches[result.Path] = result.Time
+			m.logger.Error(fmt.Sprintf("Failed to fetch %s : %s", result.Path, result.Err))
+			failed = append(failed, filepath.Base(result.Path))
+			continue
+		}
+		delete(m.failedFetches, result.Path)
+
+		m.discover.SetFetchTime(result.Path, result.Time)
+		m.refreshSingleRepository(result.Path)
+	}
+	// Only the fetch times have changed
+	m.saveConfig()
+
+	switch {
+	case len(failed) > 0:
+		m.infoBar.showError(fmt.Sprintf("Failed to fetch %d of %d repositories : %s",
+			len(failed), len(results), strings.Join(failed, ", ")))
+	case force:
+		m.infoBar.showInfoWithTimeout(fmt.Sprintf("Fetched %d repositories.", len(results)), 5)
+	}
+}
@@ -0,0 +1,97 @@
+package gitdiscover_gui
+
+import (
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/config"
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// filterBar is the filter entry and the filter toggles above the repository list
+type filterBar struct {
+	entry          *gtk.SearchEntry
+	regexButton    *gtk.CheckButton
+	dirtyButton    *gtk.ToggleButton
+	aheadButton    *gtk.ToggleButton
+	noRemoteButton *gtk.ToggleButton
+	goButton       *gtk.ToggleButton
+}
+
+// setupFilterBar sets up the filter bar, with the last filter from the config
+func (m *MainWindow) setupFilterBar() {
+	bar := new(filterBar)
+	bar.entry = m.builder.GetObject("filterEntry").(*gtk.SearchEntry)
+	bar.regexButton = m.builder.GetObject("filterRegexCheckButton").(*gtk.CheckButton)
+	bar.dirtyButton = m.builder.GetObject("filterDirtyButton").(*gtk.ToggleButton)
+	bar.aheadButton = m.builder.GetObject("filterAheadButton").(*gtk.ToggleButton)
+	bar.noRemoteButton = m.builder.GetObject("filterNoRemoteButton").(*gtk.ToggleButton)
+	bar.goButton = m.builder.GetObject("filterGoButton").(*gtk.ToggleButton)
+	m.filterBar = bar
+
+	filter := m.config.Filter
+	bar.entry.SetText(filter.Text)
+	bar.regexButton.SetActive(filter.Regex)
+	bar.dirtyButton.SetActive(filter.OnlyDirty)
+	bar.aheadButton.SetActive(filter.OnlyAhead)
+	bar.noRemoteButton.SetActive(filter.OnlyWithoutRemote)
+	bar.goButton.SetActive(filter.OnlyGoModules)
+	m.setFilter(filter)
+
+	_ = bar.entry.Connect("search-changed", m.filterChanged)
+	_ = bar.regexButton.Connect("toggled", m.filterChanged)
+	for _, button := range []*gtk.ToggleButton{bar.dirtyButton, bar.aheadButton, bar.noRemoteButton, bar.goButton} {
+		_ = button.Connect("toggled", m.filterChanged)
+	}
+}
+
+// filterChanged applies the filter in the filter bar. The filter is stored in
+// the config, and saved when the main window is closed. Changes are ignored until
+// the first refresh is done, and refreshRepositoryList applies the filter bar then.
+func (m *MainWindow) filterChanged() {
+	if !m.loaded {
+		return
+	}
+	text, err := m.filterBar.entry.GetText()
+	if err != nil {
+		m.logger.Error(err)
+		return
+	}
+	filter := config.Filter{
+		Text:              text,
+		Regex:             m.filterBar.regexButton.GetActive(),
+		OnlyDirty:         m.filterBar.dirtyButton.GetActive(),
+		OnlyAhead:         m.filterBar.aheadButton.GetActive(),
+		OnlyWithoutRemote: m.filterBar.noRemoteButton.GetActive(),
+		OnlyGoModules:     m.filterBar.goButton.GetActive(),
+	}
+	if !m.setFilter(filter) {
+		return
+	}
+	m.config.Filter = filter
+	m.refilterRepositoryList()
+}
+
+// setFilter sets the filter of the repository list. An invalid regular
+// expression is shown in the filter entry, and the previous filter is kept.
+func (m *MainWindow) setFilter(filter config.Filter) bool {
+	style, err := m.filterBar.entry.GetStyleContext()
+	if err != nil {
+		m.logger.Error(err)
+		return false
+	}
+	f, err := gitdiscover.NewFilter(filter)
+	if err != nil {
+		style.AddClass("error")
+		m.filterBar.entry.SetTooltipText("Invalid regular expression : " + err.Error())
+		return false
+	}
+	style.RemoveClass("error")
+	m.filterBar.entry.SetTooltipText("Filter the repositories by name or path")
+	m.filter = f
+	return true
+}
+
+// Returns true if the repository matches the filter in the filter bar
+func (m *MainWindow) matchFilter(repo *gitdiscover.Repository) bool {
+	return m.filter == nil || m.filter.Match(repo)
+}
@@ -0,0 +1,279 @@
+package gitdiscover_gui
+
+import (
+	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// repositoryDragTarget is the drag and drop target for moving
+// repositories between sections, the data is the repository path
+const repositoryDragTarget = "application/x-gitdiscover-repository"
+
+func (m *MainWindow) isSectionCollapsed(key string) bool {
+	for _, collapsed := range m.config.CollapsedSections {
+		if collapsed == key {
+			return true
+		}
+	}
+	return false
+}
+
+// setSectionCollapsed saves whether a section is collapsed in the config
+func (m *MainWindow) setSectionCollapsed(key string, collapsed bool) {
+	if m.isSectionCollapsed(key) == collapsed {
+		return
+	}
+	var sections []string
+	for _, section := range m.config.CollapsedSections {
+		if section != key {
+			sections = append(sections, section)
+		}
+	}
+	if collapsed {
+		sections = append(sections, key)
+	}
+	m.config.CollapsedSections = sections
+	m.discover.Save()
+}
+
+// setupSections saves the collapsed sections when the user collapses or expands
+// them (not when the tree view does it), and toggles a section when it is double clicked
+func (m *MainWindow) setupSections() {
+	_ = m.repositoryTreeView.Connect("row-collapsed", func(_ *gtk.TreeView, iter *gtk.TreeIter) {
+		if key := m.getSectionFromIter(iter); key != "" && !m.updatingList {
+			m.setSectionCollapsed(key, true)
+		}
+	})
+	_ = m.repositoryTreeView.Connect("row-expanded", func(_ *gtk.TreeView, iter *gtk.TreeIter) {
+		if key := m.getSectionFromIter(iter); key != "" && !m.updatingList {
+			m.setSectionCollapsed(key, false)
+		}
+	})
+	_ = m.repositoryTreeView.Connect("row-activated", func(_ *gtk.TreeView, path *gtk.TreePath) {
+		if path.GetDepth() != 1 {
+			return
+		}
+		if m.repositoryTreeView.RowExpanded(path) {
+			m.repositoryTreeView.CollapseRow(path)
+		} else {
+			m.repositoryTreeView.ExpandRow(path, false)
+		}
+	})
+}
+
+// expandSections expands the sections that are not collapsed in the config
+func (m *MainWindow) expandSections() {
+	m.updatingList = true
+	defer func() {
+		m.updatingList = false
+	}()
+
+	model := &m.repositoryFilter.TreeModel
+	for iter, ok := model.GetIterFirst(); ok; ok = model.IterNext(iter) {
+		key := m.getStringFromIter(model, iter, repositoryColumnSection)
+		if key == "" || m.isSectionCollapsed(key) {
+			continue
+		}
+		path, err := model.GetPath(iter)
+		if err != nil {
+			m.logger.Error(err)
+			continue
+		}
+		m.repositoryTreeView.ExpandRow(path, false)
+	}
+}
+
+// getSectionFromIter returns the key of the section that a row (in the filtered list) is in
+func (m *MainWindow) getSectionFromIter(iter *gtk.TreeIter) string {
+	return m.getStringFromIter(&m.repositoryFilter.TreeModel, iter, repositoryColumnSection)
+}
+
+// getSelectedSection returns the key of the selected section row, or an
+// empty string if no section row is selected
+func (m *MainWindow) getSelectedSection() string {
+	selection, err := m.repositoryTreeView.GetSelection()
+	if err != nil {
+		m.logger.Error(err)
+		return ""
+	}
+	key := ""
+	selection.GetSelectedRows(nil).Foreach(func(item interface{}) {
+		path := item.(*gtk.TreePath)
+		if key != "" || path.GetDepth() != 1 {
+			return
+		}
+		if iter, err := m.repositoryFilter.GetIter(path); err == nil {
+			key = m.getSectionFromIter(iter)
+		}
+	})
+	return key
+}
+
+// getSectionPaths returns the paths of the repositories in a section
+func (m *MainWindow) getSectionPaths(key string) []string {
+	var paths []string
+	for _, repo := range m.discover.Repositories {
+		if m.sectionKeys[repo.Path()] == key {
+			paths = append(paths, repo.Path())
+		}
+	}
+	return paths
+}
+
+// showSectionMenu shows a popup menu with actions for the repositories in a section
+func (m *MainWindow) showSectionMenu(key string, event *gdk.Event) {
+	menu, err := gtk.MenuNew()
+	if err != nil {
+		m.logger.Error(err)
+		return
+	}
+	paths := m.getSectionPaths(key)
+	for _, action := range []struct {
+		label string
+		run   func([]string)
+	}{
+		{"Refresh section", m.refreshGroup},
+		{"Fetch section", m.fetchGroup},
+	} {
+		action := action
+		item, err := gtk.MenuItemNewWithLabel(action.label)
+		if err != nil {
+			m.logger.Error(err)
+			continue
+		}
+		item.Connect("activate", func() {
+			action.run(paths)
+		})
+		menu.Add(item)
+	}
+	menu.ShowAll()
+	menu.PopupAtPointer(event)
+}
+
+// refreshGroup refreshes the repositories in a section
+func (m *MainWindow) refreshGroup(paths []string) {
+	for _, path := range paths {
+		m.refreshSingleRepository(path)
+	}
+}
+
+// fetchGroup fetches the repositories with remotes in a section
+func (m *MainWindow) fetchGroup(paths []string) {
+	var fetchPaths []string
+	for _, path := range paths {
+		if repo := m.discover.GetRepositoryByPath(path); repo != nil && repo.HasRemote() {
+			fetchPaths = append(fetchPaths, path)
+		}
+	}
+	m.startFetch(fetchPaths, true)
+}
+
+// setupDragAndDrop makes the repository list a drag source and a drop
+// target, for moving repositories to another section. Repositories that
+// are dropped on a row are moved to the section of that row.
+func (m *MainWindow) setupDragAndDrop() {
+	target, err := gtk.TargetEntryNew(repositoryDragTarget, gtk.TARGET_SAME_APP, 0)
+	if err != nil {
+		m.logger.Error(err)
+		return
+	}
+	targets := []gtk.TargetEntry{*target}
+	m.repositoryTreeView.DragSourceSet(gdk.ModifierType(gdk.BUTTON1_MASK), targets, gdk.ACTION_MOVE)
+	m.repositoryTreeView.Connect("drag-data-get",
+		func(_ *gtk.TreeView, _ *gdk.DragContext, data *gtk.SelectionData, _, _ uint) {
+			repo := m.getSelectedRepo()
+			if repo == nil {
+				return
+			}
+			data.SetData(gdk.GdkAtomIntern(repositoryDragTarget, false), []byte(repo.Path()))
+		})
+
+	m.repositoryTreeView.DragDestSet(gtk.DEST_DEFAULT_ALL, targets, gdk.ACTION_MOVE)
+	m.repositoryTreeView.Connect("drag-data-received",
+		func(_ *gtk.TreeView, _ *gdk.DragContext, x, y int, data *gtk.SelectionData, _, _ uint) {
+			path, _, ok := m.repositoryTreeView.GetDestRowAtPos(x, y)
+			if !ok {
+				return
+			}
+			iter, err := m.repositoryFilter.GetIter(path)
+			if err != nil {
+				return
+			}
+			key := m.getSectionFromIter(iter)
+			if key == "" {
+				return
+			}
+			m.moveToSection(string(data.GetData()), key)
+		})
+}
+
+// moveToSection moves a repository to another section, by
+// changing whether it is a favorite, and its group
+func (m *MainWindow) moveToSection(path, key string) {
+	// Repositories can not be moved while the list is being refreshed
+	if m.refreshCancel != nil {
+		return
+	}
+	repo := m.discover.GetRepositoryByPath(path)
+	if repo == nil || m.sectionKeys[path] == key {
+		return
+	}
+	repo.MoveToSection(key)
+	m.discover.Save()
+	// The list is recreated when the drag and drop is done, since the dragged row is in it
+	glib.IdleAdd(m.showRepositoryList)
+}
+
+// setupGroupMenu creates the items in the "Move to group" sub menu, for the selected repository
+func (m *MainWindow) setupGroupMenu(menuItem *gtk.MenuItem, repo *gitdiscover.Repository) {
+	menu, err := gtk.MenuNew()
+	if err != nil {
+		m.logger.Error(err)
+		return
+	}
+
+	addItem := func(label string, action func()) {
+		item, err := gtk.MenuItemNewWithLabel(label)
+		if err != nil {
+			m.logger.Error(err)
+			return
+		}
+		item.Connect("activate", action)
+		menu.Add(item)
+	}
+
+	path := repo.Path()
+	for _, group := range m.discover.Repositories.Groups() {
+		if group == repo.Group() {
+			continue
+		}
+		key := gitdiscover.GroupSectionKey(group)
+		addItem(group, func() {
+			m.moveToSection(path, key)
+		})
+	}
+	addItem("New group...", func() {
+		group, ok := m.askForText(m.window, "New group...", "Group name :", "Move", "")
+		if !ok || group == "" {
+			return
+		}
+		m.moveToSection(path, gitdiscover.GroupSectionKey(group))
+	})
+	if repo.Group() != "" {
+		addItem("Remove from group", func() {
+			// Like in moveToSection, but it keeps the favorite
+			if m.refreshCancel != nil {
+				return
+			}
+			repo.SetGroup("")
+			m.discover.Save()
+			m.showRepositoryList()
+		})
+	}
+
+	menuItem.SetSubmenu(menu)
+	menuItem.ShowAll()
+}
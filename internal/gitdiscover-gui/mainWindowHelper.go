@@ -5,16 +5,29 @@ import (
 )
 
 func (m *MainWindow) closeMainWindow() {
+	m.cancelRefresh()
+	m.stopWatcher()
+	m.stopFetchTimer()
+	m.cancelFetch()
+	// Only the settings (like the columns and the filter) are saved,
+	// the repositories are saved when they are changed
+	m.saveColumns()
 	m.logger = nil
 	m.window.Close()
-	m.repositoryListBox.Destroy()
-	m.repositoryListBox = nil
+	m.repositoryTreeView.Destroy()
+	m.repositoryTreeView = nil
 	m.discover = nil
 	m.window.Destroy()
 	m.window = nil
 	m.builder = nil
 }
 
+// saveConfig saves the settings in the config, like the columns, without
+// merging the repositories into it, since they might not be loaded yet
+func (m *MainWindow) saveConfig() {
+	m.config.Save("")
+}
+
 func (m *MainWindow) setupToolBar() {
 	// Quit button
 	button := m.builder.GetObject("toolbarQuitButton").(*gtk.ToolButton)
@@ -36,28 +49,34 @@ func (m *MainWindow) setupToolBar() {
 	button = m.builder.GetObject("toolbarRefreshButton").(*gtk.ToolButton)
 	_ = button.Connect("clicked", m.refreshRepositoryList)
 
+	// Fetch all button
+	button = m.builder.GetObject("toolbarFetchButton").(*gtk.ToolButton)
+	_ = button.Connect("clicked", m.fetchAllButtonClicked)
+
 	m.refreshExternalApplications(m.toolBar)
 }
 
 func (m *MainWindow) setupMenuBar() {
 	// File menu
-	button := m.builder.GetObject("menuFileQuit").(*gtk.MenuItem)
+	button := m.builder.GetObject("menuFileExport").(*gtk.MenuItem)
+	_ = button.Connect("activate", m.exportRepositories)
+	button = m.builder.GetObject("menuFileQuit").(*gtk.MenuItem)
 	_ = button.Connect("activate", m.window.Close)
 
-	// View menu
-	m.sortByName = m.builder.GetObject("mnuSortByName").(*gtk.RadioMenuItem)
-	m.sortByName.SetActive(true)
-	_ = m.sortByName.Connect("activate", m.toggleSortBy)
-	m.sortByModifiedDate = m.builder.GetObject("mnuSortByModifiedDate").(*gtk.RadioMenuItem)
-	m.sortByModifiedDate.JoinGroup(m.sortByName)
-	_ = m.sortByModifiedDate.Connect("activate", m.toggleSortBy)
-	m.sortByChanges = m.builder.GetObject("mnuSortByChanges").(*gtk.RadioMenuItem)
-	m.sortByChanges.JoinGroup(m.sortByName)
-	_ = m.sortByChanges.Connect("activate", m.toggleSortBy)
+	// Sort menu
+	m.setupSortMenu()
+
+	// Filter menu
+	m.setupFilterMenu()
+
+	// Repositories menu
+	m.setupBulkMenu(m.builder, "menuBulk")
 
 	// Edit menu
 	button = m.builder.GetObject("menuEditExternalApplications").(*gtk.MenuItem)
 	_ = button.Connect("activate", m.openExternalToolsDialog)
+	button = m.builder.GetObject("menuEditSettings").(*gtk.MenuItem)
+	_ = button.Connect("activate", m.openSettingsWindow)
 	button = m.builder.GetObject("menuEditConfig").(*gtk.MenuItem)
 	_ = button.Connect("activate", m.openConfig)
 	button = m.builder.GetObject("menuEditLog").(*gtk.MenuItem)
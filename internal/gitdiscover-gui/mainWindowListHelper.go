@@ -1,19 +1,22 @@
 package gitdiscover_gui
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
-	"sort"
 	"strings"
 
 	"github.com/gotk3/gotk3/gdk"
+	"github.com/gotk3/gotk3/glib"
 	"github.com/gotk3/gotk3/gtk"
 
+	"github.com/hultan/gitdiscover/internal/config"
 	"github.com/hultan/gitdiscover/internal/gitdiscover"
+	"github.com/hultan/gitdiscover/internal/gitoutput"
 )
 
 // Column :                  Path      Date      GitStatus GoStatus  Yes       No
-var columnColors = []string{"8DB38B", "8DB38B", "D2AB99", "8DB38B", "8DB38B", "4D934B"}
+var columnColors = []string{"8DB38B", "8DB38B", "D2AB99", "8DB38B", "8DB38B", "4D934B", "DD9933"}
 var headerColor = "00002C"
 
 func (m *MainWindow) addRepositoryButtonClicked() {
@@ -66,6 +69,14 @@ func (m *MainWindow) removeRepositoryButtonClicked() {
 		return
 	}
 
+	// Scanned repositories will be found again on the next refresh
+	if repo.IsScanned() && !repo.IsFavorite() {
+		m.infoBar.showInfoWithTimeout(
+			fmt.Sprintf("The repository was found by scanning %s, add it to the exclude patterns to hide it.",
+				repo.ScanRoot()), 10)
+		return
+	}
+
 	// Remove the selected repo
 	trimmedPath := strings.Trim(repo.Path(), " ")
 	m.discover.RemoveRepository(trimmedPath)
@@ -76,232 +87,177 @@ func (m *MainWindow) removeRepositoryButtonClicked() {
 }
 
 func (m *MainWindow) refreshRepositoryList() {
+	// Cancel the previous refresh, if it is still running
+	m.cancelRefresh()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.refreshCancel = cancel
+
 	// Clear list
 	m.clearList()
 
-	// Refresh repository list
-	m.discover.Refresh()
+	// Take a copy of the config repositories, since the
+	// config might be changed while we are refreshing
+	configRepos := append([]*config.Repository(nil), m.config.Repositories...)
+	scanRoots := append([]*config.ScanRoot(nil), m.config.ScanRoots...)
+	loaded := 0
+	m.infoBar.showInfo("Scanning for repositories...")
+
+	// Refresh repository list in the background, and show
+	// each repository in the list as soon as it is ready
+	go func() {
+		configRepos, err := gitdiscover.AddScannedRepositories(ctx, configRepos, scanRoots)
+		if err != nil {
+			// Cancelled, a new refresh has been started
+			return
+		}
+
+		total := len(configRepos)
+		repos, err := gitdiscover.LoadRepositories(ctx, configRepos, func(_ int, repo *gitdiscover.Repository) {
+			glib.IdleAdd(func() {
+				if ctx.Err() != nil {
+					return
+				}
+				loaded++
+				m.addLoadingListItem(repo)
+				m.infoBar.showInfo(fmt.Sprintf("Refreshing repositories (%d/%d)...", loaded, total))
+			})
+		})
+		if err != nil {
+			// Cancelled, a new refresh has been started
+			return
+		}
+
+		glib.IdleAdd(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			m.refreshCancel = nil
+			m.discover.SetRepositories(repos)
+			m.discover.RefreshExternalApplications()
+			m.showRepositoryList()
+			m.updateWatchedRepositories()
+			if !m.loaded {
+				// Apply the changes that were made in the filter bar during the first refresh
+				m.loaded = true
+				m.filterChanged()
+			}
+		})
+	}()
+}
+
+func (m *MainWindow) cancelRefresh() {
+	if m.refreshCancel != nil {
+		m.refreshCancel()
+		m.refreshCancel = nil
+	}
+}
+
+func (m *MainWindow) showRepositoryList() {
+	// Clear list
+	m.clearList()
 
 	// Sort tracked folders in the order the user have selected
 	m.sortRepositories()
 
-	// Fill list
+	// Fill list, with a row for each section, that contains its repositories
 	m.fillRepositoryList()
 
-	// Add separators between git and non-git repositories
-	m.addSeparators()
-
-	m.repositoryListBox.ShowAll()
 	m.infoBar.hideInfoBar()
 }
 
-func (m *MainWindow) addSeparators() {
-	// Add the favorites separator
-	sepItem := m.createListSeparator("FAVORITES")
-	m.repositoryListBox.Insert(sepItem, 0)
-
-	// Add favorites header
-	hdrItem := m.createHeader()
-	m.repositoryListBox.Insert(hdrItem, 1)
-
-	// Add git repository separator and header
-	index := m.getFavoritesBoundaryIndex()
-	if index != -1 {
-		sepItem = m.createListSeparator("GIT REPOSITORIES")
-		m.repositoryListBox.Insert(sepItem, index+2)
-		hdrItem = m.createHeader()
-		m.repositoryListBox.Insert(hdrItem, index+3)
-	}
-
-	// Addd non-git repository separator and header
-	index = m.getGitRepositoryBoundaryIndex()
-	if index != -1 {
-		sepItem = m.createListSeparator("NON-GIT FOLDERS")
-		m.repositoryListBox.Insert(sepItem, index+4)
-		hdrItem = m.createHeader()
-		m.repositoryListBox.Insert(hdrItem, index+5)
-	}
+// addLoadingListItem adds a repository to the end of the list while the
+// list is being refreshed. The row does not point to the repository,
+// since the final (sorted) list has not been created yet.
+func (m *MainWindow) addLoadingListItem(repo *gitdiscover.Repository) {
+	iter := m.repositoryStore.Append(nil)
+	m.setListItem(iter, repo, "")
 }
 
 func (m *MainWindow) fillRepositoryList() {
-	// Loop through the list of repos and add them to the list
-	sgDate, _ := gtk.SizeGroupNew(gtk.SIZE_GROUP_BOTH)
-	for i := range m.discover.Repositories {
-		repo := m.discover.GetRepositoryByIndex(i)
-		listItem := m.createListItem(i, m.discover.GetDateFormat(), repo, sgDate)
-		m.repositoryListBox.Add(listItem)
-	}
-}
-
-func (m *MainWindow) getFavoritesBoundaryIndex() int {
-	// Find where to insert the "Git repositores" separator for non-git repos
-	var index = -1
-	for i, repo := range m.discover.Repositories {
-		if !repo.IsFavorite() {
-			index = i
-			break
+	// Loop through the sections, and add their repos to the list. The
+	// repositories are stored in the order they are shown in.
+	sections := gitdiscover.GroupRepositories(m.discover.Repositories, m.sortOrder.FavoritesFirst)
+	m.discover.Repositories = nil
+	m.sectionKeys = make(map[string]string)
+	m.repositoryIters = make(map[string]*gtk.TreeIter)
+	for _, section := range sections {
+		parent := m.repositoryStore.Append(nil)
+		m.setSectionItem(parent, section)
+
+		for _, repo := range section.Repositories {
+			m.discover.Repositories = append(m.discover.Repositories, repo)
+			m.sectionKeys[repo.Path()] = section.Key
+			iter := m.repositoryStore.Append(parent)
+			m.repositoryIters[repo.Path()] = iter
+			m.setListItem(iter, repo, section.Key)
 		}
 	}
-	return index
-}
-
-func (m *MainWindow) getGitRepositoryBoundaryIndex() int {
-	// Find where to insert the "Non-git repositories" separator for non-git repos
-	var index = -1
-	for i, repo := range m.discover.Repositories {
-		if !repo.IsGit() {
-			index = i
-			break
-		}
-	}
-	return index
+	m.expandSections()
 }
 
 func (m *MainWindow) sortRepositories() {
-	// Sort repos by [Name|ModifiedDate|Changes] and then [IsGit]
-	switch m.sortBy {
-	case sortByName:
-		sort.Sort(gitdiscover.ByName{Repositories: m.discover.Repositories})
-	case sortByModifiedDate:
-		sort.Sort(gitdiscover.ByModifiedDate{Repositories: m.discover.Repositories})
-	case sortByChanges:
-		sort.Sort(gitdiscover.ByChanges{Repositories: m.discover.Repositories})
-	}
+	m.discover.Repositories.Sort(m.sortOrder)
 }
 
 func (m *MainWindow) clearList() {
-	// Get the list of repos
-	children := m.repositoryListBox.GetChildren()
-	if children == nil {
-		return
-	}
+	m.updatingList = true
+	m.repositoryStore.Clear()
+	m.updatingList = false
+	m.repositoryIters = nil
+}
 
-	// Remove all elements from the list
-	var i uint = 0
-	for i < children.Length() {
-		widget, _ := children.NthData(i).(*gtk.Widget)
-		m.repositoryListBox.Remove(widget)
-		widget.Destroy()
-		i++
-	}
+// setSectionItem sets the values of the row at the top of a section
+func (m *MainWindow) setSectionItem(iter *gtk.TreeIter, section *gitdiscover.RepositorySection) {
+	title := fmt.Sprintf(`<span font="Sans Regular 14" foreground="#8C8C00">%s (%d)</span>`,
+		gitoutput.EscapeMarkup(section.Title), len(section.Repositories))
+	tooltip := "Click the arrow to collapse or expand the section, and right click it to refresh or fetch it.\n" +
+		"Drag repositories here to move them to the section."
+	m.setStoreValues(iter, map[int]interface{}{
+		repositoryColumnPath:    title,
+		repositoryColumnTooltip: tooltip,
+		repositoryColumnSection: section.Key,
+	})
 }
 
-func (m *MainWindow) createListSeparator(text string) *gtk.Box {
-	// Create main box
-	box, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+// setListItem sets the values of the row that shows a repository. The rows
+// that are still loading have no section, and do not point to the repository.
+func (m *MainWindow) setListItem(iter *gtk.TreeIter, repo *gitdiscover.Repository, section string) {
+	values := map[int]interface{}{
+		repositoryColumnIcon:      m.getRepositoryIcon(repo),
+		repositoryColumnFavorite:  m.getFavoriteIcon(repo),
+		repositoryColumnDate:      m.getMarkup(gitoutput.EscapeMarkup(repo.ModifiedDate().Format(m.discover.GetDateFormat())), columnColors[1]),
+		repositoryColumnPath:      m.getPathMarkup(repo),
+		repositoryColumnStashes:   "",
+		repositoryColumnLatestTag: m.getMarkup(gitoutput.EscapeMarkup(m.getLatestTagText(repo)), columnColors[3]),
+		repositoryColumnGitStatus: m.getMarkup(gitoutput.EscapeMarkup(repo.GitStatus()), columnColors[2]),
+		repositoryColumnGoStatus:  m.getMarkup(gitoutput.EscapeMarkup(repo.GoStatus()), columnColors[3]),
+		repositoryColumnTooltip:   m.getRepositoryTooltip(repo),
+		repositoryColumnRepoPath:  "",
+		repositoryColumnSection:   section,
 	}
-	box.SetName("sep")
-
-	// Date
-	label, err := gtk.LabelNew("")
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+	if repo.Stashes() > 0 {
+		values[repositoryColumnStashes] = m.getMarkup(fmt.Sprintf("⚑ %d", repo.Stashes()), columnColors[6])
 	}
-	label.SetMarkup(`<span font="Sans Regular 14" foreground="#8C8C00">` + text + `</span>`)
-	box.PackStart(label, true, true, 10)
-
-	return box
-}
-
-func (m *MainWindow) createHeaderItem(name, text, tooltip string) *gtk.Label {
-	label, err := gtk.LabelNew("")
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+	if remote := repo.DefaultRemote(); remote != nil {
+		values[repositoryColumnRemote] = m.getMarkup(remote.Provider().String(), columnColors[4])
+	} else {
+		values[repositoryColumnRemote] = m.getMarkup("none", columnColors[5])
 	}
-	label.SetMarkup(m.getMarkup(text, headerColor))
-	label.SetName(name)
-	label.SetTooltipText(tooltip)
-
-	return label
-}
-
-func (m *MainWindow) createHeader() *gtk.Box {
-	// Create main box
-	box, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+	if section != "" {
+		values[repositoryColumnRepoPath] = repo.Path()
 	}
-
-	// TODO : create a createHeaderItem function taking arguments
-	// text, name and tooltip
-
-	// Icon
-	label := m.createHeaderItem(
-		"hdrIcon",
-		"Icon",
-		"Repository icon, should be called application.png in the assets folder.",
-	)
-	box.PackStart(label, false, false, 5)
-
-	// Date
-	label = m.createHeaderItem(
-		"hdrDate",
-		"Date                       ",
-		"Modified date of the git repository folder.",
-	)
-	box.PackStart(label, false, false, 10)
-
-	// Favorite icon
-	label = m.createHeaderItem(
-		"hdrFavorite",
-		"Favorite",
-		"Is the repository marked as a user favorite?",
-	)
-	box.PackStart(label, false, false, 0)
-
-	// Path
-	label = m.createHeaderItem(
-		"hdrPath",
-		"Path",
-		"Repository path",
-	)
-	box.PackStart(label, false, false, 10)
-
-	// HasRemote
-	label = m.createHeaderItem(
-		"hdrHasRemote",
-		"Remote",
-		"Does the repository have a remote repository?",
-	)
-	box.PackEnd(label, false, false, 0)
-
-	// GoStatus
-	label = m.createHeaderItem(
-		"hdrGoStatus",
-		"Go status",
-		"The go version set in the go.mod file.",
-	)
-	box.PackEnd(label, false, false, 2)
-
-	// GitStatus
-	label = m.createHeaderItem(
-		"hdrGitStatus",
-		"Git status",
-		"The branch and the status of the git branch (modified,added, deleted, etc...).",
-	)
-	box.PackEnd(label, false, false, 15)
-
-	return box
+	m.setStoreValues(iter, values)
 }
 
-func (m *MainWindow) createListItem(index int, dateFormat string, repo *gitdiscover.Repository,
-	sgDate *gtk.SizeGroup) *gtk.Box {
-
-	// Create main box
-	box, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 10)
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+func (m *MainWindow) setStoreValues(iter *gtk.TreeIter, values map[int]interface{}) {
+	for column, value := range values {
+		if err := m.repositoryStore.SetValue(iter, column, value); err != nil {
+			m.logger.Error(err)
+		}
 	}
-	box.SetName(fmt.Sprintf("box_%v", index))
+}
 
-	// Icon
+func (m *MainWindow) getRepositoryIcon(repo *gitdiscover.Repository) *gdk.Pixbuf {
 	iconPath := repo.ImagePath()
 	if !fw.IO.FileExists(iconPath) {
 		// General icon for project that don't have one
@@ -316,132 +272,134 @@ func (m *MainWindow) createListItem(index int, dateFormat string, repo *gitdisco
 		m.logger.Panic(err)
 		panic(err)
 	}
-	image, err := gtk.ImageNewFromPixbuf(pix)
-	image.SetTooltipText("Repository icon, should be called application.png in the assets folder.")
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+	return pix
+}
+
+func (m *MainWindow) getFavoriteIcon(repo *gitdiscover.Repository) *gdk.Pixbuf {
+	if repo.IsFavorite() {
+		pix, err := gdk.PixbufNewFromFileAtSize(fw.Resource.GetResourcePath("favorite.png"), 16, 16)
+		if err != nil {
+			m.logger.Panic(err)
+			panic(err)
+		}
+		return pix
 	}
-	box.PackStart(image, false, false, 10)
 
-	// Date
-	label, err := gtk.LabelNew("")
+	// An empty icon, so that the row changes when a favorite is removed
+	pix, err := gdk.PixbufNew(gdk.COLORSPACE_RGB, true, 8, 16, 16)
 	if err != nil {
 		m.logger.Panic(err)
 		panic(err)
 	}
-	label.SetMarkup(m.getMarkup(repo.ModifiedDate().Format(dateFormat), columnColors[1]))
-	label.SetName("lblDate")
-	label.SetTooltipText("Modified date of the git repository folder.")
-	sgDate.AddWidget(label)
-	label.SetXAlign(0.0)
-	box.PackStart(label, false, false, 10)
-
-	// HasRemote
-	label, err = gtk.LabelNew("")
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+	pix.Fill(0)
+	return pix
+}
+
+func (m *MainWindow) getPathMarkup(repo *gitdiscover.Repository) string {
+	pathMarkup := m.getMarkup(gitoutput.EscapeMarkup(repo.Path()), columnColors[0])
+	if repo.IsScanned() {
+		pathMarkup = `<i>` + pathMarkup + `</i>`
 	}
-	if strings.Trim(repo.HasRemote(), " ") == "yes" {
-		label.SetMarkup(m.getMarkup(repo.HasRemote(), columnColors[4]))
-	} else {
-		label.SetMarkup(m.getMarkup(repo.HasRemote(), columnColors[5]))
+	switch repo.Kind() {
+	case gitdiscover.RepositoryKindWorktree, gitdiscover.RepositoryKindSubmodule, gitdiscover.RepositoryKindBare:
+		pathMarkup += m.getMarkup(" ["+repo.Kind().String()+"]", columnColors[5])
 	}
+	return pathMarkup
+}
 
-	label.SetName("lblHasRemote")
-	label.SetTooltipText("Does the repository have a remote repository?")
-	label.SetHAlign(gtk.ALIGN_START)
-	box.PackEnd(label, false, false, 10)
-
-	// GoStatus
-	label, err = gtk.LabelNew("")
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+// getRepositoryTooltip returns the tooltip of a repository row, as markup
+func (m *MainWindow) getRepositoryTooltip(repo *gitdiscover.Repository) string {
+	tooltips := []string{m.getPathTooltip(repo)}
+	if repo.IsGit() {
+		tooltips = append(tooltips, m.getGitStatusTooltip(repo), m.getRemoteTooltip(repo))
+		if m.columns["tag"] != nil && m.columns["tag"].GetVisible() {
+			tooltips = append(tooltips, m.getLatestTagTooltip(repo))
+		}
 	}
-	// label.SetMarkup(`<span font="Sans Regular 10" foreground="#6666DD">` + repo.GoStatus() + `</span>`)
-	label.SetMarkup(m.getMarkup(repo.GoStatus(), columnColors[3]))
-	label.SetName("lblGoStatus")
-	label.SetTooltipText("The go version set in the go.mod file.")
-	label.SetHAlign(gtk.ALIGN_START)
-	box.PackEnd(label, false, false, 10)
-
-	// GitStatus
-	label, err = gtk.LabelNew("")
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+	if repo.Stashes() > 0 {
+		tooltips = append(tooltips, fmt.Sprintf("The repository has %d stashes, use Git > Stashes... to see them.",
+			repo.Stashes()))
 	}
-	// label.SetMarkup(`<span font="Sans Regular 10" foreground="#22BB88">` + repo.GitStatus() + `</span>`)
-	label.SetMarkup(m.getMarkup(repo.GitStatus(), columnColors[2]))
-	label.SetName("lblStatus")
-	label.SetTooltipText("The branch and the status of the git branch (modified,added, deleted, etc...).")
-	label.SetHAlign(gtk.ALIGN_START)
-	box.PackEnd(label, false, false, 10)
-
-	// Path
-	label, err = gtk.LabelNew(repo.Path())
-	if err != nil {
-		m.logger.Panic(err)
-		panic(err)
+	return gitoutput.EscapeMarkup(strings.Join(tooltips, "\n\n"))
+}
+
+func (m *MainWindow) getGitStatusTooltip(repo *gitdiscover.Repository) string {
+	tooltip := "The branch and the status of the git branch (modified,added, deleted, etc...)."
+	if !repo.IsGit() {
+		return tooltip
 	}
-	label.SetMarkup(m.getMarkup(repo.Path(), columnColors[0]))
-	label.SetName("lblPath")
-	label.SetTooltipText("Repository path")
-	label.SetHAlign(gtk.ALIGN_START)
-	box.PackEnd(label, true, true, 10)
 
-	// Favorite icon
-	if repo.IsFavorite() {
-		iconPath = fw.Resource.GetResourcePath("favorite.png")
-		pix, err = gdk.PixbufNewFromFileAtSize(iconPath, 16, 16)
-		if err != nil {
-			m.logger.Panic(err)
-			panic(err)
-		}
-		image, err = gtk.ImageNewFromPixbuf(pix)
-		image.SetTooltipText("Is the repository marked as a user favorite?")
-		if err != nil {
-			m.logger.Panic(err)
-			panic(err)
+	switch {
+	case repo.IsDetached():
+		tooltip += "\nHEAD is detached."
+	case repo.Upstream() == "":
+		tooltip += fmt.Sprintf("\nBranch %s has no upstream.", repo.Branch())
+	default:
+		tooltip += fmt.Sprintf("\nBranch %s is %d ahead and %d behind %s.",
+			repo.Branch(), repo.Ahead(), repo.Behind(), repo.Upstream())
+	}
+	tooltip += "\n\n" + repo.ChangeSummary().Breakdown()
+	return tooltip
+}
+
+func (m *MainWindow) getPathTooltip(repo *gitdiscover.Repository) string {
+	lines := []string{"Repository path"}
+	if repo.IsScanned() {
+		lines[0] = fmt.Sprintf("Repository path (found by scanning %s)", repo.ScanRoot())
+	}
+	switch repo.Kind() {
+	case gitdiscover.RepositoryKindWorktree:
+		lines = append(lines, "Linked worktree of "+repo.ParentPath())
+	case gitdiscover.RepositoryKindSubmodule:
+		lines = append(lines, "Submodule of "+repo.ParentPath())
+	case gitdiscover.RepositoryKindBare:
+		lines = append(lines, "Bare repository (without a working tree)")
+	}
+	if len(repo.Worktrees()) > 0 {
+		lines = append(lines, "Linked worktrees :")
+		for _, worktree := range repo.Worktrees() {
+			lines = append(lines, "    "+worktree)
 		}
-	} else {
-		iconPath = fw.Resource.GetResourcePath("favorite.png")
-		pix, err = gdk.PixbufNew(gdk.COLORSPACE_RGB, true, 8, 16, 16)
-		if err != nil {
-			m.logger.Panic(err)
-			panic(err)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m *MainWindow) getRemoteTooltip(repo *gitdiscover.Repository) string {
+	if !repo.HasRemote() {
+		return "The repository has no remote repositories."
+	}
+	var lines []string
+	for _, remote := range repo.Remotes() {
+		lines = append(lines, fmt.Sprintf("%s (%s)", remote.Name, remote.Provider()))
+		for _, url := range remote.FetchURLs {
+			lines = append(lines, "    fetch : "+url)
 		}
-		pix.Fill(0)
-		image, err = gtk.ImageNewFromPixbuf(pix)
-		image.SetTooltipText("Is the repository marked as a user favorite?")
-		if err != nil {
-			m.logger.Panic(err)
-			panic(err)
+		for _, url := range remote.PushURLs {
+			lines = append(lines, "    push : "+url)
 		}
 	}
-	box.PackStart(image, false, false, 10)
-
-	return box
+	return strings.Join(lines, "\n")
 }
 
-func (m *MainWindow) toggleSortBy(radio *gtk.RadioMenuItem) {
-	// Only sort by the selected radio button
-	if !radio.GetActive() {
-		return
+// getLatestTagText returns the latest tag, and the number of commits since it, like "v1.2.0 +3"
+func (m *MainWindow) getLatestTagText(repo *gitdiscover.Repository) string {
+	switch {
+	case repo.LatestTag() == "":
+		return ""
+	case repo.CommitsSinceTag() == 0:
+		return repo.LatestTag()
+	default:
+		return fmt.Sprintf("%s +%d", repo.LatestTag(), repo.CommitsSinceTag())
 	}
+}
 
-	// Sort by the selected radio button
-	name := radio.GetLabel()
-	switch name {
-	case "Name":
-		m.sortBy = sortByName
-	case "Modified date":
-		m.sortBy = sortByModifiedDate
-	case "Changes":
-		m.sortBy = sortByChanges
+func (m *MainWindow) getLatestTagTooltip(repo *gitdiscover.Repository) string {
+	switch {
+	case !repo.IsGit():
+		return "The latest version tag, and the number of commits since it."
+	case repo.LatestTag() == "":
+		return "The repository has no version tags (like v1.2.3)."
+	default:
+		return fmt.Sprintf("%d unreleased commits since %s.", repo.CommitsSinceTag(), repo.LatestTag())
 	}
-
-	m.refreshRepositoryList()
 }
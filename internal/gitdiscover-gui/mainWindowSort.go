@@ -0,0 +1,112 @@
+package gitdiscover_gui
+
+import (
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// setupSortMenu sets up the sort menu, and the sort items in the view
+// menu, with the sort order from the config
+func (m *MainWindow) setupSortMenu() {
+	order, err := gitdiscover.NewSortOrder(m.config.Sort)
+	if err != nil {
+		m.logger.Error(err)
+		order = gitdiscover.DefaultSortOrder()
+	}
+	m.sortOrder = order
+
+	m.sortMenuItems = make(map[gitdiscover.SortColumn]*gtk.RadioMenuItem)
+	group := m.builder.GetObject("mnuSortByName").(*gtk.RadioMenuItem)
+	items := []struct {
+		id     string
+		column gitdiscover.SortColumn
+	}{
+		{"mnuSortByName", gitdiscover.SortByName},
+		{"mnuSortByModifiedDate", gitdiscover.SortByModifiedDate},
+		{"mnuSortByChanges", gitdiscover.ChangeCategorySortColumn(gitdiscover.ChangesTotal)},
+		{"mnuSortByAhead", gitdiscover.SortByAhead},
+		{"mnuSortByBehind", gitdiscover.SortByBehind},
+	}
+	for n, i := range items {
+		item := m.builder.GetObject(i.id).(*gtk.RadioMenuItem)
+		if n > 0 {
+			item.JoinGroup(group)
+		}
+		m.addSortMenuItem(item, i.column)
+	}
+	m.setupSortByChangeCategoryMenu(group)
+
+	m.sortByOther, err = gtk.RadioMenuItemNewFromWidget(group)
+	if err != nil {
+		m.logger.Panic(err)
+		panic(err)
+	}
+
+	// View menu
+	m.favoritesFirstItem = m.builder.GetObject("menuViewFavoritesFirst").(*gtk.CheckMenuItem)
+	_ = m.favoritesFirstItem.Connect("toggled", func(item *gtk.CheckMenuItem) {
+		if m.updatingSortMenu {
+			return
+		}
+		m.sortOrder.FavoritesFirst = item.GetActive()
+		m.sortChanged()
+	})
+	button := m.builder.GetObject("menuViewSortOrder").(*gtk.MenuItem)
+	_ = button.Connect("activate", m.openSortWindow)
+
+	m.updateSortMenu()
+}
+
+// addSortMenuItem makes the item sort the list by the column. Activating
+// the item again reverses the sort order.
+func (m *MainWindow) addSortMenuItem(item *gtk.RadioMenuItem, column gitdiscover.SortColumn) {
+	m.sortMenuItems[column] = item
+	_ = item.Connect("activate", func(radio *gtk.RadioMenuItem) {
+		if m.updatingSortMenu || !radio.GetActive() {
+			return
+		}
+		m.sortBy(column)
+	})
+}
+
+// sortBy makes the column the first sort key, or reverses it if the
+// list already is sorted by the column
+func (m *MainWindow) sortBy(column gitdiscover.SortColumn) {
+	m.sortOrder.SetPrimary(column)
+	m.sortChanged()
+}
+
+// sortChanged saves the sort order, and sorts the list again
+func (m *MainWindow) sortChanged() {
+	m.config.Sort = m.sortOrder.ToConfig()
+	m.discover.Save()
+
+	m.updateSortMenu()
+	m.updateSortIndicators()
+
+	// A running refresh sorts the list when it is done
+	if m.refreshCancel == nil {
+		m.showRepositoryList()
+	}
+}
+
+// updateSortMenu activates the sort menu item of the first sort key
+func (m *MainWindow) updateSortMenu() {
+	m.updatingSortMenu = true
+	defer func() {
+		m.updatingSortMenu = false
+	}()
+
+	item := m.sortByOther
+	if primary, ok := m.sortOrder.Primary(); ok && m.sortMenuItems[primary.Column] != nil {
+		item = m.sortMenuItems[primary.Column]
+	}
+	item.SetActive(true)
+	m.favoritesFirstItem.SetActive(m.sortOrder.FavoritesFirst)
+}
+
+func (m *MainWindow) openSortWindow() {
+	window := newSortWindow(m)
+	window.openWindow()
+}
@@ -3,8 +3,9 @@ package gitdiscover_gui
 import (
 	"fmt"
 	"os/exec"
-	"strconv"
+	"strings"
 
+	"github.com/gotk3/gotk3/glib"
 	"github.com/gotk3/gotk3/gtk"
 
 	"github.com/hultan/gitdiscover/internal/gitdiscover"
@@ -17,38 +18,80 @@ func (m *MainWindow) getMarkup(text, color string) string {
 	return markup
 }
 
+// getSelectedRepo returns the first selected repository
 func (m *MainWindow) getSelectedRepo() *gitdiscover.Repository {
-	row := m.repositoryListBox.GetSelectedRow()
-	if row == nil {
+	repos := m.getSelectedRepositories()
+	if len(repos) == 0 {
 		return nil
 	}
-	boxObj, err := row.GetChild()
+	return repos[0]
+}
+
+// getSelectedRepositories returns the selected repositories, in the order they are shown in
+func (m *MainWindow) getSelectedRepositories() gitdiscover.Repositories {
+	selection, err := m.repositoryTreeView.GetSelection()
 	if err != nil {
-		m.infoBar.showError(err.Error())
+		m.logger.Error(err)
 		return nil
 	}
-	box, ok := boxObj.(*gtk.Box)
+	var repos gitdiscover.Repositories
+	selection.GetSelectedRows(nil).Foreach(func(item interface{}) {
+		iter, err := m.repositoryFilter.GetIter(item.(*gtk.TreePath))
+		if err != nil {
+			return
+		}
+		if repo := m.getRepoFromIter(&m.repositoryFilter.TreeModel, iter); repo != nil {
+			repos = append(repos, repo)
+		}
+	})
+	return repos
+}
+
+// selectRepository selects the repository in the list, and scrolls to it. It returns
+// false if the repository is not in the list, or if it is hidden by the filter.
+func (m *MainWindow) selectRepository(repo *gitdiscover.Repository) bool {
+	iter := m.repositoryIters[repo.Path()]
+	if iter == nil {
+		return false
+	}
+	filterIter, ok := m.repositoryFilter.ConvertChildIterToIter(iter)
 	if !ok {
-		m.infoBar.showError("Failed to convert to *gtk.Widget")
-		return nil
+		return false
 	}
-	name, err := box.GetName()
+	path, err := m.repositoryFilter.GetPath(filterIter)
 	if err != nil {
-		m.infoBar.showError(err.Error())
-		return nil
+		m.logger.Error(err)
+		return false
 	}
-	if name == "sep" {
+	// Collapsed sections are expanded, so that the repository can be selected
+	m.repositoryTreeView.ExpandToPath(path)
+	m.repositoryTreeView.SetCursor(path, nil, false)
+	m.repositoryTreeView.ScrollToCell(path, nil, false, 0, 0)
+	return true
+}
+
+// getRepoFromIter returns the repository of a row, or nil for the
+// section rows and the rows that are still loading
+func (m *MainWindow) getRepoFromIter(model *gtk.TreeModel, iter *gtk.TreeIter) *gitdiscover.Repository {
+	path := m.getStringFromIter(model, iter, repositoryColumnRepoPath)
+	if path == "" {
 		return nil
 	}
-	indexString := name[4:]
-	index, err := strconv.Atoi(indexString)
+	return m.discover.GetRepositoryByPath(path)
+}
+
+func (m *MainWindow) getStringFromIter(model *gtk.TreeModel, iter *gtk.TreeIter, column int) string {
+	value, err := model.GetValue(iter, column)
 	if err != nil {
-		m.infoBar.showError(err.Error())
-		return nil
+		m.logger.Error(err)
+		return ""
 	}
-	repo := m.discover.Repositories[index]
-
-	return repo
+	text, err := value.GetString()
+	if err != nil {
+		// The value is not set yet
+		return ""
+	}
+	return text
 }
 
 func (m *MainWindow) openConfig() {
@@ -65,6 +108,22 @@ func (m *MainWindow) openLog() {
 	}()
 }
 
+// openWebPage opens a web page in the default browser. The browser is started
+// in the background, and only the error is shown in the GTK main loop.
+func (m *MainWindow) openWebPage(url string) {
+	go func() {
+		out, err := exec.Command("xdg-open", url).CombinedOutput()
+		if err == nil {
+			return
+		}
+		glib.IdleAdd(func() {
+			m.logger.Error("Failed to open web page: ", url, " ", strings.TrimSpace(string(out)))
+			m.logger.Error(err)
+			m.infoBar.showError(err.Error())
+		})
+	}()
+}
+
 func (m *MainWindow) executeCommand(command, arguments string) string {
 	cmd := exec.Command(command, arguments)
 	// Forces the new process to detach from the GitDiscover process
@@ -86,3 +145,55 @@ func (m *MainWindow) executeCommand(command, arguments string) string {
 
 	return string(out)
 }
+
+// askForText shows a dialog with a text entry, and returns the trimmed
+// text, and false if the user cancels
+func (m *MainWindow) askForText(parent gtk.IWindow, title, prompt, okLabel, text string) (string, bool) {
+	dialog, err := gtk.DialogNewWithButtons(title, parent, gtk.DIALOG_MODAL,
+		[]interface{}{"Cancel", gtk.RESPONSE_CANCEL}, []interface{}{okLabel, gtk.RESPONSE_OK})
+	if err != nil {
+		m.logger.Error(err)
+		return "", false
+	}
+	defer dialog.Destroy()
+	dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	content, err := dialog.GetContentArea()
+	if err != nil {
+		m.logger.Error(err)
+		return "", false
+	}
+	label, err := gtk.LabelNew(prompt)
+	if err != nil {
+		m.logger.Error(err)
+		return "", false
+	}
+	entry, err := gtk.EntryNew()
+	if err != nil {
+		m.logger.Error(err)
+		return "", false
+	}
+	entry.SetText(text)
+	entry.SetActivatesDefault(true)
+	content.SetSpacing(5)
+	content.PackStart(label, false, false, 5)
+	content.PackStart(entry, false, false, 5)
+	dialog.ShowAll()
+
+	if dialog.Run() != gtk.RESPONSE_OK {
+		return "", false
+	}
+	text, err = entry.GetText()
+	if err != nil {
+		m.logger.Error(err)
+		return "", false
+	}
+	return strings.TrimSpace(text), true
+}
+
+// askQuestion shows a yes/no dialog, and returns true if the user answers yes
+func (m *MainWindow) askQuestion(parent gtk.IWindow, question string) bool {
+	dialog := gtk.MessageDialogNew(parent, gtk.DIALOG_MODAL, gtk.MESSAGE_QUESTION, gtk.BUTTONS_YES_NO, "%s", question)
+	defer dialog.Destroy()
+	return dialog.Run() == gtk.RESPONSE_YES
+}
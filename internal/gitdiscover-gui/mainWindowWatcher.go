@@ -0,0 +1,114 @@
+package gitdiscover_gui
+
+import (
+	"context"
+	"time"
+
+	"github.com/gotk3/gotk3/glib"
+
+	"github.com/hultan/gitdiscover/internal/config"
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// How long a repository must be left alone before it is refreshed
+const watcherDebounce = time.Second
+
+func (m *MainWindow) startWatcher() {
+	if m.watcher != nil {
+		return
+	}
+
+	watcher, err := gitdiscover.NewWatcher(watcherDebounce, func(repoPath string) {
+		// Called from the watcher goroutine
+		glib.IdleAdd(func() {
+			m.refreshSingleRepository(repoPath)
+		})
+	})
+	if err != nil {
+		m.logger.Error(err)
+		m.infoBar.showError(err.Error())
+		return
+	}
+	m.watcher = watcher
+	m.updateWatchedRepositories()
+}
+
+func (m *MainWindow) stopWatcher() {
+	if m.watcher == nil {
+		return
+	}
+
+	err := m.watcher.Close()
+	if err != nil {
+		m.logger.Error(err)
+	}
+	m.watcher = nil
+}
+
+// updateWatchedRepositories makes sure that we watch all the
+// git repositories in the list, and nothing else.
+func (m *MainWindow) updateWatchedRepositories() {
+	if m.watcher == nil {
+		return
+	}
+
+	var paths []string
+	for _, repo := range m.discover.Repositories {
+		if repo.IsGit() {
+			paths = append(paths, repo.Path())
+		}
+	}
+
+	err := m.watcher.SetRepositories(paths)
+	if err != nil {
+		m.logger.Error(err)
+		m.infoBar.showError(err.Error())
+	}
+}
+
+// refreshSingleRepository refreshes a single repository in the
+// background, and then updates only that repository's row.
+func (m *MainWindow) refreshSingleRepository(repoPath string) {
+	// A full refresh is running, or the main window is closed
+	if m.refreshCancel != nil || m.window == nil {
+		return
+	}
+
+	// Scanned repositories are not in the config, so
+	// we create the config from the current repository
+	var configRepo *config.Repository
+	for _, repo := range m.discover.Repositories {
+		if repo.Path() == repoPath {
+			configRepo = repo.ToConfig()
+			break
+		}
+	}
+	if configRepo == nil {
+		return
+	}
+
+	go func() {
+		repo := gitdiscover.LoadRepository(context.Background(), configRepo)
+		glib.IdleAdd(func() {
+			if m.refreshCancel != nil || m.window == nil {
+				return
+			}
+			if m.discover.ReplaceRepository(repo) == -1 {
+				return
+			}
+			m.updateListItem(repo)
+		})
+	}()
+}
+
+// updateListItem updates the row that shows the repository,
+// keeping the row (and the selection) intact.
+func (m *MainWindow) updateListItem(repo *gitdiscover.Repository) {
+	iter, ok := m.repositoryIters[repo.Path()]
+	if !ok {
+		return
+	}
+	// The changed repository might not match the filter anymore,
+	// the filter checks the row again when it is changed
+	m.setListItem(iter, repo, m.sectionKeys[repo.Path()])
+}
@@ -2,18 +2,42 @@ package gitdiscover_gui
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"fmt"
 	"strings"
+	"sync"
 
+	"github.com/gotk3/gotk3/glib"
 	"github.com/gotk3/gotk3/gtk"
 	"github.com/sirupsen/logrus"
 
+	"github.com/hultan/gitdiscover/internal/gitoutput"
+	"github.com/hultan/gitdiscover/internal/gitrunner"
 	"github.com/hultan/softteam/framework"
 )
 
 type outputWindow struct {
-	builder *framework.GtkBuilder
-	logger  *logrus.Logger
-	window  *gtk.Window
+	builder      *framework.GtkBuilder
+	logger       *logrus.Logger
+	window       *gtk.Window
+	buffer       *gtk.TextBuffer
+	stateLabel   *gtk.Label
+	cancelButton *gtk.Button
+	gitCommand   gitCommandType
+
+	// cancel stops the running git command
+	cancel context.CancelFunc
+
+	// Lines that git has written, but that are not in the buffer yet
+	mutex        sync.Mutex
+	pending      []gitrunner.Line
+	flushPending bool
+
+	// Formatters for the streamed output, that keep state between lines
+	statusFormatter *gitoutput.StatusFormatter
+	diffParser      *gitoutput.DiffParser
+	ansiConverter   *gitoutput.ANSIConverter
 }
 
 func newOutputWindow(builder *framework.GtkBuilder, logger *logrus.Logger) *outputWindow {
@@ -23,18 +47,59 @@ func newOutputWindow(builder *framework.GtkBuilder, logger *logrus.Logger) *outp
 	return output
 }
 
+// openWindow shows text, that is the output of an already finished command
 func (o *outputWindow) openWindow(header, text string, gitCommand gitCommandType) {
+	if !o.createWindow(header, gitCommand) {
+		return
+	}
+	o.setTextForTextView(text, gitCommand, o.buffer)
+	o.stateLabel.Hide()
+	o.cancelButton.Hide()
+}
+
+// runCommand runs git in the background, and shows its output line by line
+// while it is running. The command is killed if the user clicks cancel.
+func (o *outputWindow) runCommand(header string, gitCommand gitCommandType, runner *gitrunner.Runner,
+	args ...string) {
+
+	if !o.createWindow(header, gitCommand) {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	o.cancel = cancel
+	o.stateLabel.SetMarkup(`<span color="orange">Running...</span>`)
+
+	go func() {
+		result, err := runner.RunStreaming(ctx, o.addLine, args...)
+		cancel()
+		glib.IdleAdd(func() {
+			o.commandDone(result, err)
+		})
+	}()
+}
+
+func (o *outputWindow) createWindow(header string, gitCommand gitCommandType) bool {
 	// Create a new softBuilder
 	builder, err := fw.Gtk.CreateBuilder("outputWindow.ui")
 	if err != nil {
 		panic(err)
 	}
 	o.builder = builder
+	o.gitCommand = gitCommand
+	o.statusFormatter = gitoutput.NewStatusFormatter()
+	o.diffParser = gitoutput.NewDiffParser()
+	o.ansiConverter = gitoutput.NewANSIConverter()
 
 	window := o.builder.GetObject("outputWindow").(*gtk.Window)
 	window.Connect("destroy", o.closeWindow)
+	// Closing the window with the title bar also kills the command, so
+	// we hide the window in closeWindow instead of using HideOnDelete
+	window.Connect("delete-event", func() bool {
+		o.closeWindow()
+		return true
+	})
 	window.SetTitle("Output window...")
-	window.HideOnDelete()
 	window.SetModal(true)
 	window.SetKeepAbove(true)
 	window.SetPosition(gtk.WIN_POS_CENTER_ALWAYS)
@@ -42,6 +107,11 @@ func (o *outputWindow) openWindow(header, text string, gitCommand gitCommandType
 	button := o.builder.GetObject("closeButton").(*gtk.Button)
 	button.Connect("clicked", o.closeWindow)
 
+	o.cancelButton = o.builder.GetObject("cancelButton").(*gtk.Button)
+	o.cancelButton.Connect("clicked", o.cancelCommand)
+
+	o.stateLabel = o.builder.GetObject("labelState").(*gtk.Label)
+
 	label := o.builder.GetObject("labelHeader").(*gtk.Label)
 	if header == "" {
 		header = o.getHeader(gitCommand)
@@ -52,38 +122,137 @@ func (o *outputWindow) openWindow(header, text string, gitCommand gitCommandType
 	buffer, err := gtk.TextBufferNew(nil)
 	if err != nil {
 		o.logger.Error(err)
-		return
+		return false
 	}
 
 	textView.SetBuffer(buffer)
-	o.setTextForTextView(text, gitCommand, buffer)
 	textView.SetEditable(false)
+	o.buffer = buffer
 
 	o.window = window
 	window.ShowAll()
+	return true
 }
 
 func (o *outputWindow) closeWindow() {
+	o.cancelCommand()
+	if o.window == nil {
+		return
+	}
 	o.window.Hide()
 	o.window = nil
 }
 
+func (o *outputWindow) cancelCommand() {
+	if o.cancel != nil {
+		o.cancel()
+		o.cancel = nil
+	}
+}
+
+// addLine is called from the gitrunner goroutines for each line of output.
+// The lines are added to the buffer in batches, in the GTK main loop.
+func (o *outputWindow) addLine(line gitrunner.Line) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	o.pending = append(o.pending, line)
+	if !o.flushPending {
+		o.flushPending = true
+		glib.IdleAdd(o.flushLines)
+	}
+}
+
+func (o *outputWindow) flushLines() {
+	o.mutex.Lock()
+	lines := o.pending
+	o.pending = nil
+	o.flushPending = false
+	o.mutex.Unlock()
+
+	// The window is closed
+	if o.window == nil {
+		return
+	}
+
+	var text strings.Builder
+	for _, line := range lines {
+		text.WriteString(o.formatLine(line))
+	}
+	o.buffer.InsertMarkup(o.buffer.GetEndIter(), text.String())
+}
+
+func (o *outputWindow) commandDone(result *gitrunner.Result, err error) {
+	o.cancel = nil
+
+	// The window is closed
+	if o.window == nil {
+		return
+	}
+	o.flushLines()
+	var text strings.Builder
+	for _, line := range o.diffParser.Flush() {
+		text.WriteString(gitoutput.FormatDiffLine(line))
+	}
+	o.buffer.InsertMarkup(o.buffer.GetEndIter(), text.String())
+	o.cancelButton.SetSensitive(false)
+
+	var exitErr *gitrunner.ExitError
+	switch {
+	case errors.Is(err, context.Canceled):
+		o.stateLabel.SetMarkup(`<span color="orange">Cancelled</span>`)
+	case errors.Is(err, context.DeadlineExceeded):
+		o.stateLabel.SetMarkup(`<span color="red">Timed out</span>`)
+	case errors.As(err, &exitErr):
+		o.stateLabel.SetMarkup(fmt.Sprintf(`<span color="red">Failed (exit code %d)</span>`, result.ExitCode))
+	case err != nil:
+		o.logger.Error(err)
+		o.stateLabel.SetMarkup(`<span color="red">Failed : ` + gitoutput.EscapeMarkup(err.Error()) + `</span>`)
+	default:
+		o.stateLabel.SetMarkup(fmt.Sprintf(`<span color="green">Finished (exit code 0) in %.1f s</span>`,
+			result.Duration.Seconds()))
+	}
+}
+
+// formatLine formats a single line of streamed output, as Pango markup
+func (o *outputWindow) formatLine(line gitrunner.Line) string {
+	if line.Stream == gitrunner.Stderr {
+		return `<span color="orange">` + gitoutput.EscapeMarkup(line.Text) + "</span>\n"
+	}
+	if gitoutput.HasANSI(line.Text) {
+		return o.ansiConverter.Convert(line.Text) + "\n"
+	}
+
+	switch o.gitCommand {
+	case outputGitStatus:
+		return o.statusFormatter.FormatLine(line.Text)
+	case outputGitDiff:
+		var text strings.Builder
+		for _, diffLine := range o.diffParser.ParseLine(line.Text) {
+			text.WriteString(gitoutput.FormatDiffLine(diffLine))
+		}
+		return text.String()
+	default:
+		return gitoutput.EscapeMarkup(line.Text) + "\n"
+	}
+}
+
 func (o *outputWindow) setTextForTextView(text string, gitCommand gitCommandType, buffer *gtk.TextBuffer) {
-	// Remove illegal characters
-	text = o.formatTextGeneral(text)
+	// Colored output, like git -c color.ui=always
+	if gitoutput.HasANSI(text) {
+		buffer.InsertMarkup(buffer.GetStartIter(), gitoutput.ANSIToPango(text))
+		return
+	}
 
-	// Fix specific formatting
 	switch gitCommand {
 	case outputGitStatus:
-		text = o.formatTextGitStatus(text)
-		break
+		text = gitoutput.StatusToPango(text)
 	case outputGitDiff:
-		text = o.formatTextGitDiff(text)
-		break
-	case outputGitLog:
-		text = o.formatTextGitLog(text)
-		break
-
+		text = gitoutput.DiffToPango(text)
+	case outputBulkSummary:
+		text = o.formatTextBulkSummary(gitoutput.EscapeMarkup(text))
+	default:
+		text = gitoutput.EscapeMarkup(text)
 	}
 	buffer.InsertMarkup(buffer.GetStartIter(), text)
 }
@@ -92,8 +261,6 @@ func (o *outputWindow) getHeader(gitCommand gitCommandType) string {
 	switch gitCommand {
 	case outputGitStatus:
 		return "git status"
-	case outputGitLog:
-		return "git log"
 	case outputGitDiff:
 		return "git diff"
 	default:
@@ -101,85 +268,19 @@ func (o *outputWindow) getHeader(gitCommand gitCommandType) string {
 	}
 }
 
-func (o *outputWindow) formatTextGeneral(text string) string {
-	text = strings.Replace(text, "&", "&amp;", -1)
-	text = strings.Replace(text, "<", "&lt;", -1)
-	text = strings.Replace(text, ">", "&gt;", -1)
-
-	return text
-}
-
-func (o *outputWindow) formatTextGitStatus(text string) string {
+func (o *outputWindow) formatTextBulkSummary(text string) string {
 	var result = ""
 	scanner := bufio.NewScanner(strings.NewReader(text))
 	for scanner.Scan() {
 		line := scanner.Text()
-		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmedLine, "modified:") {
-			result += `<span color="red">` + line + "</span>\n"
-			continue
-		}
-		if strings.HasPrefix(trimmedLine, "deleted:") {
-			result += `<span color="red">` + line + "</span>\n"
-			continue
-		}
-		if strings.HasPrefix(trimmedLine, "new file:") {
+		if strings.HasPrefix(line, "OK ") {
 			result += `<span color="green">` + line + "</span>\n"
 			continue
 		}
-		result += line + "\n"
-	}
-
-	if strings.Index(result, `nothing to commit, working tree clean`) >= 0 {
-		return result
-	}
-
-	if strings.Index(result, `no changes added to commit (use "git add" and/or "git commit -a")`) >= 0 {
-		return result
-	}
-
-	result = strings.Replace(result, `(use "git add &lt;file&gt;..." to include in what will be committed)`,
-		`(use "git add &lt;file&gt;..." to include in what will be committed)<span color="red">`, -1)
-
-	result += "</span>"
-
-	return result
-}
-
-func (o *outputWindow) formatTextGitDiff(text string) string {
-	var result = ""
-	scanner := bufio.NewScanner(strings.NewReader(text))
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmedLine, "+++") {
-			result += `<span color="red">` + line + "</span>\n"
-			continue
-		}
-		if strings.HasPrefix(trimmedLine, "-") {
+		if strings.HasPrefix(line, "FAILED ") {
 			result += `<span color="red">` + line + "</span>\n"
 			continue
 		}
-		if strings.HasPrefix(trimmedLine, "+") {
-			result += `<span color="green">` + line + "</span>\n"
-			continue
-		}
-		result += line + "\n"
-	}
-
-	return result
-}
-
-func (o *outputWindow) formatTextGitLog(text string) string {
-	var result = ""
-	scanner := bufio.NewScanner(strings.NewReader(text))
-	for scanner.Scan() {
-		line := scanner.Text()
-		trimmedLine := strings.TrimSpace(line)
-		if strings.HasPrefix(trimmedLine, "commit ") {
-			result += `<span color="yellow">` + line + "</span>\n"
-			continue
-		}
 		result += line + "\n"
 	}
 
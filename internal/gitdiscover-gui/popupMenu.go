@@ -1,11 +1,10 @@
 package gitdiscover_gui
 
 import (
-	"io/ioutil"
-	"os"
-
 	"github.com/gotk3/gotk3/gdk"
 	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
 )
 
 type popupMenu struct {
@@ -16,10 +15,16 @@ type popupMenu struct {
 	popupEditFolder           *gtk.MenuItem
 	popupRemoveFolder         *gtk.MenuItem
 	popupFavorite             *gtk.MenuItem
+	popupGroup                *gtk.MenuItem
 	popupExternalApplications *gtk.MenuItem
+	popupOpenRemote           *gtk.MenuItem
 	popupGitStatus            *gtk.MenuItem
 	popupGitDiff              *gtk.MenuItem
 	popupGitLog               *gtk.MenuItem
+	popupGitCommit            *gtk.MenuItem
+	popupGitBranches          *gtk.MenuItem
+	popupGitStashes           *gtk.MenuItem
+	popupGitTags              *gtk.MenuItem
 	popupGit                  *gtk.MenuItem
 }
 
@@ -42,11 +47,18 @@ func (p *popupMenu) setupPopupMenu() {
 	p.popupEditFolder = builder.GetObject("popupEditFolder").(*gtk.MenuItem)
 	p.popupRemoveFolder = builder.GetObject("popupRemoveFolder").(*gtk.MenuItem)
 	p.popupFavorite = builder.GetObject("popupFavorite").(*gtk.MenuItem)
+	p.popupGroup = builder.GetObject("popupGroup").(*gtk.MenuItem)
 	p.popupExternalApplications = builder.GetObject("popupExternalApplications").(*gtk.MenuItem)
+	p.popupOpenRemote = builder.GetObject("popupOpenRemote").(*gtk.MenuItem)
 	p.popupGit = builder.GetObject("popupGit").(*gtk.MenuItem)
 	p.popupGitStatus = builder.GetObject("popupGitStatus").(*gtk.MenuItem)
 	p.popupGitDiff = builder.GetObject("popupGitDiff").(*gtk.MenuItem)
 	p.popupGitLog = builder.GetObject("popupGitLog").(*gtk.MenuItem)
+	p.popupGitCommit = builder.GetObject("popupGitCommit").(*gtk.MenuItem)
+	p.popupGitBranches = builder.GetObject("popupGitBranches").(*gtk.MenuItem)
+	p.popupGitStashes = builder.GetObject("popupGitStashes").(*gtk.MenuItem)
+	p.popupGitTags = builder.GetObject("popupGitTags").(*gtk.MenuItem)
+	p.mainWindow.setupBulkMenu(builder, "popupBulk")
 
 	p.setupEvents()
 }
@@ -61,13 +73,23 @@ func (p *popupMenu) setupEvents() {
 
 		// Get the currently selected repo
 		repo := p.mainWindow.getSelectedRepo()
+		if key := p.mainWindow.getSelectedSection(); repo == nil && key != "" {
+			p.mainWindow.showSectionMenu(key, event)
+			return
+		}
 		if repo == nil {
 			p.mainWindow.infoBar.showInfoWithTimeout("Please select a repo...", 5)
 			return
 		}
 
-		// Disable the git menu for non-git folders
+		// Disable the git menu for non-git folders, and the
+		// working tree items for bare repositories
 		p.popupGit.SetSensitive(repo.IsGit())
+		hasWorkTree := repo.Kind() != gitdiscover.RepositoryKindBare
+		p.popupGitStatus.SetSensitive(hasWorkTree)
+		p.popupGitDiff.SetSensitive(hasWorkTree)
+		p.popupGitCommit.SetSensitive(hasWorkTree)
+		p.popupGitStashes.SetSensitive(hasWorkTree)
 
 		// Create a sub menu for external applications
 		menu, err := gtk.MenuNew()
@@ -94,6 +116,9 @@ func (p *popupMenu) setupEvents() {
 		p.popupExternalApplications.SetSubmenu(menu)
 		p.popupExternalApplications.ShowAll()
 
+		p.setupOpenRemoteMenu(repo)
+		p.mainWindow.setupGroupMenu(p.popupGroup, repo)
+
 		p.popupMenu.PopupAtPointer(event)
 	})
 
@@ -109,91 +134,44 @@ func (p *popupMenu) setupEvents() {
 		p.mainWindow.removeRepositoryButtonClicked()
 	})
 
-	p.popupFavorite.Connect("activate", func() {
-		// Get the currently selected repo
-		repo := p.mainWindow.getSelectedRepo()
-
-		if repo == nil {
-			p.mainWindow.infoBar.showInfoWithTimeout("Please select a repo...", 5)
-			return
-		}
-
-		repo.SetIsFavorite(!repo.IsFavorite())
-		p.mainWindow.discover.Save()
-		p.mainWindow.refreshRepositoryList()
-	})
-
-	p.popupGitStatus.Connect("activate", func() {
-		p.runGitCommand("git status", outputGitStatus)
-	})
+	p.popupFavorite.Connect("activate", p.mainWindow.toggleFavorite)
 
-	p.popupGitDiff.Connect("activate", func() {
-		p.runGitCommand("git diff", outputGitDiff)
-	})
-
-	p.popupGitLog.Connect("activate", func() {
-		p.runGitCommand("git log", outputGitLog)
-	})
+	p.popupGitStatus.Connect("activate", p.mainWindow.showGitStatus)
+	p.popupGitDiff.Connect("activate", p.mainWindow.showGitDiff)
+	p.popupGitLog.Connect("activate", p.mainWindow.openHistoryWindow)
+	p.popupGitCommit.Connect("activate", p.mainWindow.openCommitWindow)
+	p.popupGitBranches.Connect("activate", p.mainWindow.openBranchWindow)
+	p.popupGitStashes.Connect("activate", p.mainWindow.openStashWindow)
+	p.popupGitTags.Connect("activate", p.mainWindow.openTagWindow)
 }
 
-// runGitCommand : Run a GIT command
-func (p *popupMenu) runGitCommand(command string, outputType gitCommandType) {
-	// Get the currently selected repo
-	repo := p.mainWindow.getSelectedRepo()
-	if repo == nil {
-		p.mainWindow.infoBar.showInfoWithTimeout("Please select a repo...", 5)
-		return
-	}
-
-	// Create a temp bash file, with the command
-	file, err := p.createFile(repo.Path(), command)
+// setupOpenRemoteMenu creates a sub menu with the web pages of the remotes
+func (p *popupMenu) setupOpenRemoteMenu(repo *gitdiscover.Repository) {
+	menu, err := gtk.MenuNew()
 	if err != nil {
 		p.mainWindow.logger.Error(err)
-		p.mainWindow.infoBar.showError(err.Error())
 		return
 	}
 
-	// Execute the bash file
-	result := p.mainWindow.executeCommand("/bin/sh", file)
-	output := newOutputWindow(p.mainWindow.builder, p.mainWindow.logger)
-	output.openWindow("", result, outputType)
-
-	// Clean up
-	err = os.Remove(file)
-	if err != nil {
-		p.mainWindow.logger.Error(err)
-		p.mainWindow.infoBar.showError(err.Error())
-	}
-}
-
-// createFile : Create a temp bash file
-func (p *popupMenu) createFile(path, command string) (string, error) {
-	// Create the text for the bash file
-	text := "#!/bin/sh\n"
-	text += "cd " + path + "\n"
-	text += command
-
-	// Create a temp file
-	content := []byte(text)
-	tmpfile, err := ioutil.TempFile("", "gitdiscover.*.sh")
-	if err != nil {
-		p.mainWindow.logger.Error(err)
-		return "", err
-	}
-
-	// Write to the file
-	_, err = tmpfile.Write(content)
-	if err != nil {
-		p.mainWindow.logger.Error(err)
-		return "", err
-	}
-
-	// Clean up
-	err = tmpfile.Close()
-	if err != nil {
-		p.mainWindow.logger.Error(err)
-		return "", err
+	hasWebPage := false
+	for _, remote := range repo.Remotes() {
+		webURL := remote.WebURL()
+		if webURL == "" {
+			continue
+		}
+		item, err := gtk.MenuItemNewWithLabel(remote.Name + " (" + remote.Provider().String() + ")")
+		if err != nil {
+			p.mainWindow.logger.Error(err)
+			continue
+		}
+		item.SetTooltipText(webURL)
+		menu.Add(item)
+		item.Connect("activate", func() {
+			p.mainWindow.openWebPage(webURL)
+		})
+		hasWebPage = true
 	}
-
-	return tmpfile.Name(), nil
+	p.popupOpenRemote.SetSubmenu(menu)
+	p.popupOpenRemote.SetSensitive(hasWebPage)
+	p.popupOpenRemote.ShowAll()
 }
@@ -0,0 +1,112 @@
+package gitdiscover_gui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/softteam/framework"
+)
+
+type settingsWindow struct {
+	mainWindow *MainWindow
+	window     *gtk.Window
+	builder    *framework.GtkBuilder
+
+	startMaximized   *gtk.CheckButton
+	autoUpdate       *gtk.CheckButton
+	dateFormat       *gtk.Entry
+	dateFormatSample *gtk.Label
+	pathColumnWidth  *gtk.SpinButton
+	fetchInterval    *gtk.SpinButton
+}
+
+func newSettingsWindow(mainWindow *MainWindow) *settingsWindow {
+	settings := new(settingsWindow)
+	settings.mainWindow = mainWindow
+	return settings
+}
+
+func (s *settingsWindow) openWindow() {
+	// Create a new softBuilder
+	builder, err := fw.Gtk.CreateBuilder("settingsWindow.ui")
+	if err != nil {
+		panic(err)
+	}
+	s.builder = builder
+
+	window := s.builder.GetObject("settingsWindow").(*gtk.Window)
+	window.Connect("destroy", s.closeWindow)
+	window.SetTitle("Settings...")
+	window.HideOnDelete()
+	window.SetModal(true)
+	window.SetKeepAbove(true)
+	window.SetPosition(gtk.WIN_POS_CENTER_ALWAYS)
+
+	button := s.builder.GetObject("cancelButton").(*gtk.Button)
+	button.Connect("clicked", s.closeWindow)
+
+	button = s.builder.GetObject("saveButton").(*gtk.Button)
+	button.Connect("clicked", s.save)
+
+	c := s.mainWindow.config
+
+	s.startMaximized = s.builder.GetObject("checkBoxStartMaximized").(*gtk.CheckButton)
+	s.startMaximized.SetActive(c.StartMaximized)
+
+	s.autoUpdate = s.builder.GetObject("checkBoxAutoUpdate").(*gtk.CheckButton)
+	s.autoUpdate.SetActive(c.AutoUpdate)
+
+	s.dateFormatSample = s.builder.GetObject("dateFormatExampleLabel").(*gtk.Label)
+	s.dateFormat = s.builder.GetObject("dateFormatEntry").(*gtk.Entry)
+	s.dateFormat.SetText(c.DateFormat)
+	s.dateFormat.Connect("changed", s.updateDateFormatSample)
+	s.updateDateFormatSample()
+
+	s.pathColumnWidth = s.builder.GetObject("pathColumnWidthSpinButton").(*gtk.SpinButton)
+	s.pathColumnWidth.SetValue(float64(c.PathColumnWidth))
+
+	s.fetchInterval = s.builder.GetObject("fetchIntervalSpinButton").(*gtk.SpinButton)
+	s.fetchInterval.SetValue(float64(c.FetchInterval))
+
+	s.window = window
+	window.ShowAll()
+}
+
+func (s *settingsWindow) save() {
+	dateFormat, err := s.dateFormat.GetText()
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return
+	}
+	dateFormat = strings.TrimSpace(dateFormat)
+	if dateFormat == "" {
+		s.dateFormatSample.SetText("Please enter a date format!")
+		return
+	}
+
+	c := s.mainWindow.config
+	c.StartMaximized = s.startMaximized.GetActive()
+	c.AutoUpdate = s.autoUpdate.GetActive()
+	c.DateFormat = dateFormat
+	c.PathColumnWidth = s.pathColumnWidth.GetValueAsInt()
+	c.FetchInterval = s.fetchInterval.GetValueAsInt()
+	s.mainWindow.discover.Save()
+
+	s.closeWindow()
+	s.mainWindow.applySettings()
+}
+
+func (s *settingsWindow) closeWindow() {
+	s.window.Hide()
+	s.window = nil
+}
+
+func (s *settingsWindow) updateDateFormatSample() {
+	dateFormat, err := s.dateFormat.GetText()
+	if err != nil {
+		return
+	}
+	s.dateFormatSample.SetText(time.Now().Format(dateFormat))
+}
@@ -0,0 +1,261 @@
+package gitdiscover_gui
+
+import (
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+	"github.com/hultan/softteam/framework"
+)
+
+// The columns in the sort key list store
+const (
+	sortKeyColumnColumn = iota
+	sortKeyColumnDirection
+)
+
+// sortWindow edits the sort order of the main window. The changes
+// are applied (and saved) immediately.
+type sortWindow struct {
+	mainWindow *MainWindow
+	window     *gtk.Window
+	builder    *framework.GtkBuilder
+
+	store          *gtk.ListStore
+	treeView       *gtk.TreeView
+	favoritesFirst *gtk.CheckButton
+	columnCombo    *gtk.ComboBoxText
+	addButton      *gtk.Button
+	removeButton   *gtk.Button
+	upButton       *gtk.Button
+	downButton     *gtk.Button
+	reverseButton  *gtk.Button
+}
+
+func newSortWindow(mainWindow *MainWindow) *sortWindow {
+	sort := new(sortWindow)
+	sort.mainWindow = mainWindow
+	return sort
+}
+
+func (s *sortWindow) openWindow() {
+	// Create a new softBuilder
+	builder, err := fw.Gtk.CreateBuilder("sortWindow.ui")
+	if err != nil {
+		panic(err)
+	}
+	s.builder = builder
+
+	window := s.builder.GetObject("sortWindow").(*gtk.Window)
+	window.Connect("destroy", s.closeWindow)
+	window.SetTitle("Sort order...")
+	window.HideOnDelete()
+	window.SetModal(true)
+	window.SetKeepAbove(true)
+	window.SetPosition(gtk.WIN_POS_CENTER_ALWAYS)
+
+	button := s.builder.GetObject("closeButton").(*gtk.Button)
+	button.Connect("clicked", s.closeWindow)
+
+	s.addButton = s.builder.GetObject("addButton").(*gtk.Button)
+	s.addButton.Connect("clicked", s.addKey)
+	s.removeButton = s.builder.GetObject("removeButton").(*gtk.Button)
+	s.removeButton.Connect("clicked", s.removeKey)
+	s.upButton = s.builder.GetObject("upButton").(*gtk.Button)
+	s.upButton.Connect("clicked", func() {
+		s.moveKey(-1)
+	})
+	s.downButton = s.builder.GetObject("downButton").(*gtk.Button)
+	s.downButton.Connect("clicked", func() {
+		s.moveKey(1)
+	})
+	s.reverseButton = s.builder.GetObject("reverseButton").(*gtk.Button)
+	s.reverseButton.Connect("clicked", s.reverseKey)
+
+	s.columnCombo = s.builder.GetObject("columnComboBox").(*gtk.ComboBoxText)
+
+	s.favoritesFirst = s.builder.GetObject("favoritesFirstCheckButton").(*gtk.CheckButton)
+	s.favoritesFirst.SetActive(s.mainWindow.sortOrder.FavoritesFirst)
+	s.favoritesFirst.Connect("toggled", func() {
+		s.mainWindow.sortOrder.FavoritesFirst = s.favoritesFirst.GetActive()
+		s.mainWindow.sortChanged()
+	})
+
+	if !s.setupTreeView() {
+		return
+	}
+
+	s.window = window
+	window.ShowAll()
+
+	s.loadKeys(0)
+}
+
+func (s *sortWindow) setupTreeView() bool {
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING)
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return false
+	}
+	s.store = store
+
+	s.treeView = s.builder.GetObject("sortKeyTreeView").(*gtk.TreeView)
+	s.treeView.SetModel(store)
+
+	for _, c := range []struct {
+		title  string
+		column int
+	}{
+		{"Column", sortKeyColumnColumn},
+		{"Direction", sortKeyColumnDirection},
+	} {
+		renderer, err := gtk.CellRendererTextNew()
+		if err != nil {
+			s.mainWindow.logger.Error(err)
+			return false
+		}
+		column, err := gtk.TreeViewColumnNewWithAttribute(c.title, renderer, "text", c.column)
+		if err != nil {
+			s.mainWindow.logger.Error(err)
+			return false
+		}
+		column.SetExpand(c.column == sortKeyColumnColumn)
+		s.treeView.AppendColumn(column)
+	}
+
+	selection, err := s.treeView.GetSelection()
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return false
+	}
+	selection.Connect("changed", s.updateButtons)
+	return true
+}
+
+func (s *sortWindow) closeWindow() {
+	s.window.Hide()
+	s.window = nil
+}
+
+// loadKeys shows the sort keys of the main window, and selects the key at index
+func (s *sortWindow) loadKeys(index int) {
+	keys := s.mainWindow.sortOrder.Keys
+	used := make(map[gitdiscover.SortColumn]bool)
+
+	s.store.Clear()
+	for _, key := range keys {
+		direction := "Ascending"
+		if key.Descending {
+			direction = "Descending"
+		}
+		iter := s.store.Append()
+		err := s.store.Set(iter, []int{sortKeyColumnColumn, sortKeyColumnDirection},
+			[]interface{}{key.Column.Title(), direction})
+		if err != nil {
+			s.mainWindow.logger.Error(err)
+		}
+		used[key.Column] = true
+	}
+
+	// Only the columns that are not in the sort order can be added
+	s.columnCombo.RemoveAll()
+	for _, column := range gitdiscover.SortColumns {
+		if !used[column] {
+			s.columnCombo.Append(string(column), column.Title())
+		}
+	}
+	s.columnCombo.SetActive(0)
+
+	if index >= 0 && index < len(keys) {
+		path, err := gtk.TreePathNewFromIndicesv([]int{index})
+		if err != nil {
+			s.mainWindow.logger.Error(err)
+		} else if selection, err := s.treeView.GetSelection(); err != nil {
+			s.mainWindow.logger.Error(err)
+		} else {
+			selection.SelectPath(path)
+		}
+	}
+	s.updateButtons()
+}
+
+// getSelectedIndex returns the index of the selected sort key, or -1 if no key is selected
+func (s *sortWindow) getSelectedIndex() int {
+	selection, err := s.treeView.GetSelection()
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return -1
+	}
+	_, iter, ok := selection.GetSelected()
+	if !ok {
+		return -1
+	}
+	path, err := s.store.GetPath(iter)
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return -1
+	}
+	return path.GetIndices()[0]
+}
+
+func (s *sortWindow) updateButtons() {
+	index := s.getSelectedIndex()
+	count := len(s.mainWindow.sortOrder.Keys)
+	s.addButton.SetSensitive(s.columnCombo.GetActiveID() != "")
+	// The last key can not be removed, since a sort order without keys is the default sort order
+	s.removeButton.SetSensitive(index >= 0 && count > 1)
+	s.upButton.SetSensitive(index > 0)
+	s.downButton.SetSensitive(index >= 0 && index < count-1)
+	s.reverseButton.SetSensitive(index >= 0)
+}
+
+// keysChanged applies the changed sort keys to the main window, and selects the key at index
+func (s *sortWindow) keysChanged(index int) {
+	s.mainWindow.sortChanged()
+	s.loadKeys(index)
+}
+
+// addKey adds the column in the combo box as the last sort key, in its default direction
+func (s *sortWindow) addKey() {
+	id := s.columnCombo.GetActiveID()
+	if id == "" {
+		return
+	}
+	order := s.mainWindow.sortOrder
+	order.Keys = append(order.Keys, gitdiscover.NewSortKey(gitdiscover.SortColumn(id)))
+	s.keysChanged(len(order.Keys) - 1)
+}
+
+func (s *sortWindow) removeKey() {
+	index := s.getSelectedIndex()
+	order := s.mainWindow.sortOrder
+	if index < 0 || len(order.Keys) < 2 {
+		return
+	}
+	order.Keys = append(order.Keys[:index], order.Keys[index+1:]...)
+	if index == len(order.Keys) {
+		index--
+	}
+	s.keysChanged(index)
+}
+
+// moveKey moves the selected sort key offset steps down (or up, if offset is negative)
+func (s *sortWindow) moveKey(offset int) {
+	index := s.getSelectedIndex()
+	keys := s.mainWindow.sortOrder.Keys
+	if index < 0 || index+offset < 0 || index+offset >= len(keys) {
+		return
+	}
+	keys[index], keys[index+offset] = keys[index+offset], keys[index]
+	s.keysChanged(index + offset)
+}
+
+func (s *sortWindow) reverseKey() {
+	index := s.getSelectedIndex()
+	if index < 0 {
+		return
+	}
+	key := &s.mainWindow.sortOrder.Keys[index]
+	key.Descending = !key.Descending
+	s.keysChanged(index)
+}
@@ -0,0 +1,299 @@
+package gitdiscover_gui
+
+import (
+	"context"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+	"github.com/hultan/gitdiscover/internal/gitoutput"
+)
+
+// The columns in the stash list store
+const (
+	stashColumnRef = iota
+	stashColumnDate
+	stashColumnMessage
+	// stashColumnIndex is the index of the stash, as in stash@{index}
+	stashColumnIndex
+)
+
+type stashWindow struct {
+	gitWindow
+
+	store       *gtk.ListStore
+	treeView    *gtk.TreeView
+	diffBuffer  *gtk.TextBuffer
+	applyButton *gtk.Button
+	popButton   *gtk.Button
+	dropButton  *gtk.Button
+
+	// selectedHash is the stash that is shown in the diff pane
+	selectedHash string
+	stashes      []*gitdiscover.Stash
+}
+
+func newStashWindow(mainWindow *MainWindow) *stashWindow {
+	stash := new(stashWindow)
+	stash.mainWindow = mainWindow
+	return stash
+}
+
+func (s *stashWindow) openWindow(repo *gitdiscover.Repository) {
+	// Create a new softBuilder
+	builder, err := fw.Gtk.CreateBuilder("stashWindow.ui")
+	if err != nil {
+		panic(err)
+	}
+	s.builder = builder
+	s.repo = repo
+
+	window := s.builder.GetObject("stashWindow").(*gtk.Window)
+	window.Connect("destroy", s.closeWindow)
+	window.SetTitle("Stashes...")
+	window.HideOnDelete()
+	window.SetModal(true)
+	window.SetKeepAbove(true)
+	window.SetPosition(gtk.WIN_POS_CENTER_ALWAYS)
+
+	label := s.builder.GetObject("labelHeader").(*gtk.Label)
+	label.SetText("Stashes in " + repo.Path())
+
+	s.stateLabel = s.builder.GetObject("labelState").(*gtk.Label)
+	s.reload = s.loadStashes
+
+	button := s.builder.GetObject("closeButton").(*gtk.Button)
+	button.Connect("clicked", s.closeWindow)
+	button = s.builder.GetObject("refreshButton").(*gtk.Button)
+	button.Connect("clicked", s.loadStashes)
+	button = s.builder.GetObject("newButton").(*gtk.Button)
+	button.Connect("clicked", s.createStash)
+
+	s.applyButton = s.builder.GetObject("applyButton").(*gtk.Button)
+	s.applyButton.Connect("clicked", s.applyStash)
+	s.popButton = s.builder.GetObject("popButton").(*gtk.Button)
+	s.popButton.Connect("clicked", s.popStash)
+	s.dropButton = s.builder.GetObject("dropButton").(*gtk.Button)
+	s.dropButton.Connect("clicked", s.dropStash)
+
+	s.diffBuffer, err = gtk.TextBufferNew(nil)
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return
+	}
+	textView := s.builder.GetObject("diffTextView").(*gtk.TextView)
+	textView.SetBuffer(s.diffBuffer)
+
+	if !s.setupTreeView() {
+		return
+	}
+
+	s.window = window
+	window.ShowAll()
+
+	s.updateButtons()
+	s.loadStashes()
+}
+
+func (s *stashWindow) setupTreeView() bool {
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_INT)
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return false
+	}
+	s.store = store
+
+	s.treeView = s.builder.GetObject("stashTreeView").(*gtk.TreeView)
+	s.treeView.SetModel(store)
+
+	columns := []struct {
+		title  string
+		column int
+	}{
+		{"Stash", stashColumnRef},
+		{"Date", stashColumnDate},
+		{"Message", stashColumnMessage},
+	}
+	for _, c := range columns {
+		renderer, err := gtk.CellRendererTextNew()
+		if err != nil {
+			s.mainWindow.logger.Error(err)
+			return false
+		}
+		column, err := gtk.TreeViewColumnNewWithAttribute(c.title, renderer, "text", c.column)
+		if err != nil {
+			s.mainWindow.logger.Error(err)
+			return false
+		}
+		column.SetResizable(true)
+		column.SetExpand(c.column == stashColumnMessage)
+		s.treeView.AppendColumn(column)
+	}
+
+	selection, err := s.treeView.GetSelection()
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return false
+	}
+	selection.Connect("changed", s.selectionChanged)
+	return true
+}
+
+func (s *stashWindow) closeWindow() {
+	s.window.Hide()
+	s.window = nil
+
+	// Update the stash count, and the changes, in the main window
+	s.mainWindow.refreshSingleRepository(s.repo.Path())
+}
+
+// loadStashes loads the stashes in the background
+func (s *stashWindow) loadStashes() {
+	path := s.repo.Path()
+	go func() {
+		stashes, err := gitdiscover.GetStashes(context.Background(), path)
+		glib.IdleAdd(func() {
+			// The window is closed
+			if s.window == nil {
+				return
+			}
+			if err != nil {
+				s.showError(err)
+				return
+			}
+			s.stashesLoaded(stashes)
+		})
+	}()
+}
+
+func (s *stashWindow) stashesLoaded(stashes []*gitdiscover.Stash) {
+	s.stashes = stashes
+	s.store.Clear()
+	s.selectedHash = ""
+	s.diffBuffer.SetText("")
+
+	dateFormat := s.mainWindow.discover.GetDateFormat()
+	for _, stash := range stashes {
+		iter := s.store.Append()
+		err := s.store.Set(iter,
+			[]int{stashColumnRef, stashColumnDate, stashColumnMessage, stashColumnIndex},
+			[]interface{}{stash.Ref(), stash.Date.Format(dateFormat), stash.Message, stash.Index})
+		if err != nil {
+			s.mainWindow.logger.Error(err)
+		}
+	}
+	if len(stashes) == 0 {
+		s.diffBuffer.SetText("No stashes")
+	}
+	s.updateButtons()
+}
+
+// getSelectedStash returns the selected stash, or nil if no stash is selected
+func (s *stashWindow) getSelectedStash() *gitdiscover.Stash {
+	selection, err := s.treeView.GetSelection()
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return nil
+	}
+	_, iter, ok := selection.GetSelected()
+	if !ok {
+		return nil
+	}
+	value, err := s.store.GetValue(iter, stashColumnIndex)
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return nil
+	}
+	index, err := value.GoValue()
+	if err != nil {
+		s.mainWindow.logger.Error(err)
+		return nil
+	}
+	for _, stash := range s.stashes {
+		if stash.Index == index {
+			return stash
+		}
+	}
+	return nil
+}
+
+func (s *stashWindow) updateButtons() {
+	selected := s.getSelectedStash() != nil
+	s.applyButton.SetSensitive(selected)
+	s.popButton.SetSensitive(selected)
+	s.dropButton.SetSensitive(selected)
+}
+
+func (s *stashWindow) selectionChanged() {
+	s.updateButtons()
+	stash := s.getSelectedStash()
+	if stash == nil || stash.Hash == s.selectedHash {
+		return
+	}
+	s.selectedHash = stash.Hash
+	s.diffBuffer.SetText("Loading...")
+
+	path := s.repo.Path()
+	go func() {
+		diff, err := gitdiscover.GetStashDiff(context.Background(), path, stash.Index)
+		glib.IdleAdd(func() {
+			// The window is closed, or another stash is selected
+			if s.window == nil || s.selectedHash != stash.Hash {
+				return
+			}
+			s.diffBuffer.SetText("")
+			if err != nil {
+				s.showError(err)
+				return
+			}
+			s.diffBuffer.InsertMarkup(s.diffBuffer.GetStartIter(), gitoutput.DiffToPango(diff))
+		})
+	}()
+}
+
+func (s *stashWindow) applyStash() {
+	stash := s.getSelectedStash()
+	if stash == nil {
+		return
+	}
+	s.runAction("Applied "+stash.Ref(), func(ctx context.Context, repoPath string) error {
+		return gitdiscover.ApplyStash(ctx, repoPath, stash)
+	})
+}
+
+func (s *stashWindow) popStash() {
+	stash := s.getSelectedStash()
+	if stash == nil {
+		return
+	}
+	s.runAction("Popped "+stash.Ref(), func(ctx context.Context, repoPath string) error {
+		return gitdiscover.PopStash(ctx, repoPath, stash)
+	})
+}
+
+func (s *stashWindow) dropStash() {
+	stash := s.getSelectedStash()
+	if stash == nil {
+		return
+	}
+	if !s.mainWindow.askQuestion(s.window, "Are you sure that you want to drop "+stash.Ref()+
+		" ("+stash.Message+")? The changes in it will be lost.") {
+		return
+	}
+	s.runAction("Dropped "+stash.Ref(), func(ctx context.Context, repoPath string) error {
+		return gitdiscover.DropStash(ctx, repoPath, stash)
+	})
+}
+
+// createStash stashes the changes in the repository, with an optional message
+func (s *stashWindow) createStash() {
+	message, ok := s.mainWindow.askForText(s.window, "New stash...", "Stash message (optional) :", "Stash", "")
+	if !ok {
+		return
+	}
+	untracked := s.mainWindow.askQuestion(s.window, "Do you want to stash untracked files too?")
+	s.runAction("Stashed the changes", func(ctx context.Context, repoPath string) error {
+		return gitdiscover.CreateStash(ctx, repoPath, message, untracked)
+	})
+}
@@ -0,0 +1,333 @@
+package gitdiscover_gui
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gotk3/gotk3/glib"
+	"github.com/gotk3/gotk3/gtk"
+
+	"github.com/hultan/gitdiscover/internal/gitdiscover"
+)
+
+// The columns in the tag list store
+const (
+	tagColumnName = iota
+	tagColumnDate
+	tagColumnCommit
+	tagColumnType
+	tagColumnMessage
+)
+
+type tagWindow struct {
+	gitWindow
+
+	store        *gtk.ListStore
+	treeView     *gtk.TreeView
+	deleteButton *gtk.Button
+
+	tags []*gitdiscover.Tag
+}
+
+func newTagWindow(mainWindow *MainWindow) *tagWindow {
+	tag := new(tagWindow)
+	tag.mainWindow = mainWindow
+	return tag
+}
+
+func (t *tagWindow) openWindow(repo *gitdiscover.Repository) {
+	// Create a new softBuilder
+	builder, err := fw.Gtk.CreateBuilder("tagWindow.ui")
+	if err != nil {
+		panic(err)
+	}
+	t.builder = builder
+	t.repo = repo
+
+	window := t.builder.GetObject("tagWindow").(*gtk.Window)
+	window.Connect("destroy", t.closeWindow)
+	window.SetTitle("Tags...")
+	window.HideOnDelete()
+	window.SetModal(true)
+	window.SetKeepAbove(true)
+	window.SetPosition(gtk.WIN_POS_CENTER_ALWAYS)
+
+	label := t.builder.GetObject("labelHeader").(*gtk.Label)
+	label.SetText("Tags in " + repo.Path())
+
+	t.stateLabel = t.builder.GetObject("labelState").(*gtk.Label)
+	t.reload = t.loadTags
+
+	button := t.builder.GetObject("closeButton").(*gtk.Button)
+	button.Connect("clicked", t.closeWindow)
+	button = t.builder.GetObject("refreshButton").(*gtk.Button)
+	button.Connect("clicked", t.loadTags)
+	button = t.builder.GetObject("newButton").(*gtk.Button)
+	button.Connect("clicked", t.createTag)
+
+	t.deleteButton = t.builder.GetObject("deleteButton").(*gtk.Button)
+	t.deleteButton.Connect("clicked", t.deleteTag)
+
+	if !t.setupTreeView() {
+		return
+	}
+
+	t.window = window
+	window.ShowAll()
+
+	t.updateButtons()
+	t.loadTags()
+}
+
+func (t *tagWindow) setupTreeView() bool {
+	store, err := gtk.ListStoreNew(glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING, glib.TYPE_STRING,
+		glib.TYPE_STRING)
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return false
+	}
+	t.store = store
+
+	t.treeView = t.builder.GetObject("tagTreeView").(*gtk.TreeView)
+	t.treeView.SetModel(store)
+
+	columns := []struct {
+		title  string
+		column int
+	}{
+		{"Tag", tagColumnName},
+		{"Date", tagColumnDate},
+		{"Commit", tagColumnCommit},
+		{"Type", tagColumnType},
+		{"Message", tagColumnMessage},
+	}
+	for _, c := range columns {
+		renderer, err := gtk.CellRendererTextNew()
+		if err != nil {
+			t.mainWindow.logger.Error(err)
+			return false
+		}
+		column, err := gtk.TreeViewColumnNewWithAttribute(c.title, renderer, "text", c.column)
+		if err != nil {
+			t.mainWindow.logger.Error(err)
+			return false
+		}
+		column.SetResizable(true)
+		column.SetExpand(c.column == tagColumnMessage)
+		t.treeView.AppendColumn(column)
+	}
+
+	selection, err := t.treeView.GetSelection()
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return false
+	}
+	selection.Connect("changed", t.updateButtons)
+	return true
+}
+
+func (t *tagWindow) closeWindow() {
+	t.window.Hide()
+	t.window = nil
+
+	// Update the latest tag in the main window
+	t.mainWindow.refreshSingleRepository(t.repo.Path())
+}
+
+// loadTags loads the tags in the background
+func (t *tagWindow) loadTags() {
+	path := t.repo.Path()
+	go func() {
+		tags, err := gitdiscover.GetTags(context.Background(), path)
+		glib.IdleAdd(func() {
+			// The window is closed
+			if t.window == nil {
+				return
+			}
+			if err != nil {
+				t.showError(err)
+				return
+			}
+			t.tagsLoaded(tags)
+		})
+	}()
+}
+
+func (t *tagWindow) tagsLoaded(tags []*gitdiscover.Tag) {
+	t.tags = tags
+	t.store.Clear()
+
+	dateFormat := t.mainWindow.discover.GetDateFormat()
+	for _, tag := range tags {
+		tagType := "lightweight"
+		if tag.Annotated {
+			tagType = "annotated"
+		}
+		commit := tag.Commit
+		if len(commit) > 7 {
+			commit = commit[:7]
+		}
+
+		iter := t.store.Append()
+		err := t.store.Set(iter,
+			[]int{tagColumnName, tagColumnDate, tagColumnCommit, tagColumnType, tagColumnMessage},
+			[]interface{}{tag.Name, tag.Date.Format(dateFormat), commit, tagType, tag.Message})
+		if err != nil {
+			t.mainWindow.logger.Error(err)
+		}
+	}
+	t.updateButtons()
+}
+
+// getSelectedTag returns the name of the selected tag, or an empty string if no tag is selected
+func (t *tagWindow) getSelectedTag() string {
+	selection, err := t.treeView.GetSelection()
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return ""
+	}
+	_, iter, ok := selection.GetSelected()
+	if !ok {
+		return ""
+	}
+	value, err := t.store.GetValue(iter, tagColumnName)
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return ""
+	}
+	name, err := value.GetString()
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return ""
+	}
+	return name
+}
+
+func (t *tagWindow) updateButtons() {
+	t.deleteButton.SetSensitive(t.getSelectedTag() != "")
+}
+
+// createTag creates an annotated tag on HEAD
+func (t *tagWindow) createTag() {
+	name, message, ok := t.askForTag()
+	if !ok {
+		return
+	}
+	t.runAction("Created "+name, func(ctx context.Context, repoPath string) error {
+		return gitdiscover.CreateTag(ctx, repoPath, name, message)
+	})
+}
+
+func (t *tagWindow) deleteTag() {
+	name := t.getSelectedTag()
+	if name == "" {
+		return
+	}
+	if !t.mainWindow.askQuestion(t.window, "Are you sure that you want to delete the tag "+name+
+		"? Tags that have been pushed must also be deleted in the remote repository.") {
+		return
+	}
+	t.runAction("Deleted "+name, func(ctx context.Context, repoPath string) error {
+		return gitdiscover.DeleteTag(ctx, repoPath, name)
+	})
+}
+
+// askForTag asks for the name and message of a new tag. The name is the
+// next patch version by default, and there are buttons for the next
+// minor and major version.
+func (t *tagWindow) askForTag() (string, string, bool) {
+	var names []string
+	for _, tag := range t.tags {
+		names = append(names, tag.Name)
+	}
+	patch, minor, major := gitdiscover.SuggestNextVersions(names)
+
+	dialog, err := gtk.DialogNewWithButtons("New tag...", t.window, gtk.DIALOG_MODAL,
+		[]interface{}{"Cancel", gtk.RESPONSE_CANCEL}, []interface{}{"Create", gtk.RESPONSE_OK})
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return "", "", false
+	}
+	defer dialog.Destroy()
+	dialog.SetDefaultResponse(gtk.RESPONSE_OK)
+
+	content, err := dialog.GetContentArea()
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return "", "", false
+	}
+	content.SetSpacing(5)
+
+	nameLabel, err := gtk.LabelNew("Tag name (on HEAD) :")
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return "", "", false
+	}
+	nameEntry, err := gtk.EntryNew()
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return "", "", false
+	}
+	nameEntry.SetText(patch)
+	nameEntry.SetActivatesDefault(true)
+
+	// Buttons that fill in the next patch, minor or major version
+	buttonBox, err := gtk.BoxNew(gtk.ORIENTATION_HORIZONTAL, 5)
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return "", "", false
+	}
+	for _, suggestion := range []struct{ label, version string }{
+		{"Patch", patch}, {"Minor", minor}, {"Major", major},
+	} {
+		version := suggestion.version
+		button, err := gtk.ButtonNewWithLabel(suggestion.label + " : " + version)
+		if err != nil {
+			t.mainWindow.logger.Error(err)
+			return "", "", false
+		}
+		button.Connect("clicked", func() {
+			nameEntry.SetText(version)
+		})
+		buttonBox.PackStart(button, true, true, 0)
+	}
+
+	messageLabel, err := gtk.LabelNew("Message (the tag name if empty) :")
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return "", "", false
+	}
+	messageEntry, err := gtk.EntryNew()
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return "", "", false
+	}
+	messageEntry.SetActivatesDefault(true)
+
+	content.PackStart(nameLabel, false, false, 5)
+	content.PackStart(nameEntry, false, false, 5)
+	content.PackStart(buttonBox, false, false, 5)
+	content.PackStart(messageLabel, false, false, 5)
+	content.PackStart(messageEntry, false, false, 5)
+	dialog.ShowAll()
+
+	if dialog.Run() != gtk.RESPONSE_OK {
+		return "", "", false
+	}
+	name, err := nameEntry.GetText()
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return "", "", false
+	}
+	message, err := messageEntry.GetText()
+	if err != nil {
+		t.mainWindow.logger.Error(err)
+		return "", "", false
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		t.stateLabel.SetMarkup(`<span color="red">Please enter a tag name...</span>`)
+		return "", "", false
+	}
+	return name, strings.TrimSpace(message), true
+}
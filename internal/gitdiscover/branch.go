@@ -0,0 +1,225 @@
+package gitdiscover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hultan/gitdiscover/internal/gitrunner"
+)
+
+// branchTimeout is the maximum time that listing or changing branches may take
+const branchTimeout = 30 * time.Second
+
+// The fields of a branch are separated by the unit separator, like in historyFormat
+const branchFormat = "--format=%(HEAD)%1f%(refname)%1f%(refname:short)%1f%(upstream:short)%1f" +
+	"%(upstream:track,nobracket)%1f%(committerdate:iso-strict)%1f%(subject)"
+
+// Branch is a local or remote branch in a repository.
+type Branch struct {
+	// Name is the short name, like "main" or "origin/main"
+	Name    string
+	Remote  bool
+	Current bool
+	// Upstream is the branch that a local branch is tracking, like "origin/main"
+	Upstream string
+	// UpstreamGone is true if the upstream branch no longer exists
+	UpstreamGone bool
+	Ahead        int
+	Behind       int
+	// Date and Subject are the committer date, and the subject, of the last commit
+	Date    time.Time
+	Subject string
+}
+
+// LocalName returns the name of the local branch to create when
+// checking out a remote branch, like "feature" for "origin/feature".
+func (b *Branch) LocalName() string {
+	if !b.Remote {
+		return b.Name
+	}
+	if i := strings.Index(b.Name, "/"); i >= 0 {
+		return b.Name[i+1:]
+	}
+	return b.Name
+}
+
+// GetBranches returns the local branches, followed by the remote branches,
+// in the repository at repoPath.
+func GetBranches(ctx context.Context, repoPath string) ([]*Branch, error) {
+	result, err := newRunner(repoPath, branchTimeout).Run(ctx, "for-each-ref", branchFormat, "refs/heads", "refs/remotes")
+	if err != nil {
+		return nil, getGitError(err)
+	}
+	return parseBranches(result.Stdout)
+}
+
+// Parse the output of git for-each-ref, using branchFormat
+func parseBranches(text string) ([]*Branch, error) {
+	var branches []*Branch
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid git for-each-ref line : %q", line)
+		}
+		// Skip symbolic refs, like refs/remotes/origin/HEAD
+		if strings.HasSuffix(fields[1], "/HEAD") {
+			continue
+		}
+
+		branch := &Branch{
+			Name:     fields[2],
+			Remote:   strings.HasPrefix(fields[1], "refs/remotes/"),
+			Current:  fields[0] == "*",
+			Upstream: fields[3],
+			Subject:  fields[6],
+		}
+		err := branch.parseTrack(fields[4])
+		if err != nil {
+			return nil, err
+		}
+		if fields[5] != "" {
+			branch.Date, err = time.Parse(time.RFC3339, fields[5])
+			if err != nil {
+				return nil, err
+			}
+		}
+		branches = append(branches, branch)
+	}
+	return branches, nil
+}
+
+// Parse the tracking information, like "ahead 1, behind 2" or "gone"
+func (b *Branch) parseTrack(track string) error {
+	if track == "gone" {
+		b.UpstreamGone = true
+		return nil
+	}
+	for _, item := range strings.Split(track, ", ") {
+		fields := strings.Fields(item)
+		if len(fields) != 2 {
+			continue
+		}
+		count, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return err
+		}
+		switch fields[0] {
+		case "ahead":
+			b.Ahead = count
+		case "behind":
+			b.Behind = count
+		}
+	}
+	return nil
+}
+
+// CheckoutBranch checks out a local branch. Remote branches are checked
+// out as a new local branch, that tracks the remote branch.
+func CheckoutBranch(ctx context.Context, repoPath string, branch *Branch) error {
+	args := []string{"checkout", "--quiet", branch.Name}
+	if branch.Remote {
+		args = []string{"checkout", "--quiet", "-b", branch.LocalName(), "--track", branch.Name}
+	}
+	_, err := newRunner(repoPath, branchTimeout).Run(ctx, args...)
+	return getGitError(err)
+}
+
+// CreateBranch creates a new branch at startPoint (HEAD if it is empty),
+// and checks it out if checkout is true.
+func CreateBranch(ctx context.Context, repoPath, name, startPoint string, checkout bool) error {
+	runner := newRunner(repoPath, branchTimeout)
+	err := checkBranchName(ctx, runner, name)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(startPoint, "-") {
+		return fmt.Errorf("invalid start point : %s", startPoint)
+	}
+
+	args := []string{"branch", "--no-track", name}
+	if checkout {
+		args = []string{"checkout", "--quiet", "--no-track", "-b", name}
+	}
+	if startPoint != "" {
+		args = append(args, startPoint)
+	}
+	_, err = runner.Run(ctx, args...)
+	return getGitError(err)
+}
+
+// RenameBranch renames a local branch.
+func RenameBranch(ctx context.Context, repoPath, oldName, newName string) error {
+	runner := newRunner(repoPath, branchTimeout)
+	err := checkBranchName(ctx, runner, newName)
+	if err != nil {
+		return err
+	}
+	_, err = runner.Run(ctx, "branch", "--move", "--", oldName, newName)
+	return getGitError(err)
+}
+
+// IsBranchMerged returns true if all commits in the local branch are in HEAD.
+func IsBranchMerged(ctx context.Context, repoPath, name string) (bool, error) {
+	_, err := newRunner(repoPath, branchTimeout).Run(ctx, "merge-base", "--is-ancestor", "refs/heads/"+name, "HEAD")
+	var exitErr *gitrunner.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode == 1 {
+		return false, nil
+	}
+	if err != nil {
+		return false, getGitError(err)
+	}
+	return true, nil
+}
+
+// DeleteBranch deletes a local branch. Branches that are not merged (into
+// their upstream, or into HEAD if they have no upstream) are only deleted
+// if force is true, git checks that when deleting the branch.
+func DeleteBranch(ctx context.Context, repoPath, name string, force bool) error {
+	args := []string{"branch", "--delete"}
+	if force {
+		args = append(args, "--force")
+	}
+	_, err := newRunner(repoPath, branchTimeout).Run(ctx, append(args, "--", name)...)
+	return getGitError(err)
+}
+
+// SetUpstream sets the upstream branch of a local branch, or
+// removes it if upstream is empty.
+func SetUpstream(ctx context.Context, repoPath, name, upstream string) error {
+	runner := newRunner(repoPath, branchTimeout)
+	if upstream == "" {
+		// git branch --unset-upstream fails if the branch has no upstream
+		_, err := runner.Run(ctx, "config", "--get", "branch."+name+".merge")
+		var exitErr *gitrunner.ExitError
+		if errors.As(err, &exitErr) && exitErr.ExitCode == 1 {
+			return nil
+		}
+		_, err = runner.Run(ctx, "branch", "--unset-upstream", "--", name)
+		return getGitError(err)
+	}
+
+	if strings.HasPrefix(upstream, "-") {
+		return fmt.Errorf("invalid upstream branch : %s", upstream)
+	}
+	_, err := runner.Run(ctx, "branch", "--set-upstream-to="+upstream, "--", name)
+	return getGitError(err)
+}
+
+// checkBranchName returns an error if name is not a valid branch name
+func checkBranchName(ctx context.Context, runner *gitrunner.Runner, name string) error {
+	if name == "" || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("invalid branch name : %q", name)
+	}
+	_, err := runner.Run(ctx, "check-ref-format", "--branch", name)
+	if err != nil {
+		return fmt.Errorf("invalid branch name : %q", name)
+	}
+	return nil
+}
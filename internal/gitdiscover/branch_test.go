@@ -0,0 +1,129 @@
+package gitdiscover
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getBranch(branches []*Branch, name string) *Branch {
+	for _, branch := range branches {
+		if branch.Name == name {
+			return branch
+		}
+	}
+	return nil
+}
+
+func Test_GetBranches(t *testing.T) {
+	_, clone := createTestClone(t)
+	ctx := context.Background()
+	runTestGit(t, clone, "fetch", "-q")
+	runTestGit(t, clone, "branch", "feature")
+
+	branches, err := GetBranches(ctx, clone)
+	assert.Nil(t, err)
+	// origin/HEAD is not a branch
+	assert.Equal(t, 3, len(branches))
+
+	main := getBranch(branches, "main")
+	assert.True(t, main.Current)
+	assert.False(t, main.Remote)
+	assert.Equal(t, "origin/main", main.Upstream)
+	assert.Equal(t, 0, main.Ahead)
+	assert.Equal(t, 1, main.Behind)
+	assert.Equal(t, "Initial commit", main.Subject)
+	assert.False(t, main.Date.IsZero())
+
+	feature := getBranch(branches, "feature")
+	assert.False(t, feature.Current)
+	assert.Equal(t, "", feature.Upstream)
+
+	remote := getBranch(branches, "origin/main")
+	assert.True(t, remote.Remote)
+	assert.Equal(t, "main", remote.LocalName())
+	assert.Equal(t, "New commit", remote.Subject)
+}
+
+func Test_BranchActions(t *testing.T) {
+	setTestIdentity(t)
+	_, clone := createTestClone(t)
+	ctx := context.Background()
+
+	err := CreateBranch(ctx, clone, "-f", "", false)
+	assert.NotNil(t, err)
+	err = CreateBranch(ctx, clone, "bad..name", "", false)
+	assert.NotNil(t, err)
+
+	err = CreateBranch(ctx, clone, "feature", "", true)
+	assert.Nil(t, err)
+	writeTestFile(t, clone, "feature.txt", "feature")
+	runTestGit(t, clone, "add", "feature.txt")
+	runTestGit(t, clone, "commit", "-q", "-m", "Feature")
+
+	err = RenameBranch(ctx, clone, "feature", "feature2")
+	assert.Nil(t, err)
+	err = SetUpstream(ctx, clone, "feature2", "origin/main")
+	assert.Nil(t, err)
+
+	branches, err := GetBranches(ctx, clone)
+	assert.Nil(t, err)
+	feature := getBranch(branches, "feature2")
+	assert.True(t, feature.Current)
+	assert.Equal(t, "origin/main", feature.Upstream)
+	assert.Equal(t, 1, feature.Ahead)
+
+	err = SetUpstream(ctx, clone, "feature2", "")
+	assert.Nil(t, err)
+	// The branch has no upstream to remove
+	err = SetUpstream(ctx, clone, "feature2", "")
+	assert.Nil(t, err)
+
+	err = CheckoutBranch(ctx, clone, getBranch(branches, "main"))
+	assert.Nil(t, err)
+
+	// The feature branch is not merged into main
+	merged, err := IsBranchMerged(ctx, clone, "feature2")
+	assert.Nil(t, err)
+	assert.False(t, merged)
+	err = DeleteBranch(ctx, clone, "feature2", false)
+	assert.NotNil(t, err)
+	err = DeleteBranch(ctx, clone, "feature2", true)
+	assert.Nil(t, err)
+
+	branches, err = GetBranches(ctx, clone)
+	assert.Nil(t, err)
+	assert.Nil(t, getBranch(branches, "feature2"))
+}
+
+func Test_CheckoutBranch_Remote(t *testing.T) {
+	bare, clone := createTestClone(t)
+	ctx := context.Background()
+	runTestGit(t, bare, "branch", "remote-only", "main")
+	runTestGit(t, clone, "fetch", "-q")
+
+	err := CheckoutBranch(ctx, clone, &Branch{Name: "origin/remote-only", Remote: true})
+	assert.Nil(t, err)
+
+	branches, err := GetBranches(ctx, clone)
+	assert.Nil(t, err)
+	branch := getBranch(branches, "remote-only")
+	assert.True(t, branch.Current)
+	assert.Equal(t, "origin/remote-only", branch.Upstream)
+}
+
+func Test_parseBranches(t *testing.T) {
+	text := "*\x1frefs/heads/main\x1fmain\x1forigin/main\x1fahead 2, behind 3\x1f2022-01-02T03:04:05+01:00\x1fFix\n" +
+		" \x1frefs/heads/old\x1fold\x1forigin/old\x1fgone\x1f2022-01-02T03:04:05+01:00\x1fOld\n" +
+		" \x1frefs/remotes/origin/HEAD\x1forigin\x1f\x1f\x1f2022-01-02T03:04:05+01:00\x1fFix\n"
+	branches, err := parseBranches(text)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(branches))
+	assert.Equal(t, 2, branches[0].Ahead)
+	assert.Equal(t, 3, branches[0].Behind)
+	assert.True(t, branches[1].UpstreamGone)
+
+	_, err = parseBranches("invalid\n")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,132 @@
+package gitdiscover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// bulkTimeout is the maximum time that a bulk operation may take in a single repository
+const bulkTimeout = 2 * time.Minute
+
+// maxConcurrentBulkOperations is the maximum number of repositories
+// that a bulk operation is running in at the same time
+const maxConcurrentBulkOperations = 4
+
+// BulkOperation is a git operation that can be run in many repositories at once.
+type BulkOperation int
+
+const (
+	BulkFetch BulkOperation = iota
+	BulkPull
+	BulkPush
+	BulkStash
+	BulkCheckout
+)
+
+// String returns the git command of the operation, like "git pull --ff-only".
+func (o BulkOperation) String() string {
+	return strings.TrimSpace("git " + strings.Join(o.args(""), " "))
+}
+
+// Get the git arguments for the operation, branch is only used by BulkCheckout
+func (o BulkOperation) args(branch string) []string {
+	switch o {
+	case BulkFetch:
+		return []string{"fetch", "--all"}
+	case BulkPull:
+		return []string{"pull", "--ff-only"}
+	case BulkPush:
+		return []string{"push"}
+	case BulkStash:
+		return []string{"stash", "push"}
+	case BulkCheckout:
+		return []string{"checkout", branch}
+	default:
+		return nil
+	}
+}
+
+// BulkResult contains the result of a bulk operation in a single repository.
+type BulkResult struct {
+	Path     string
+	ExitCode int
+	Output   string
+	Err      error
+}
+
+// BulkProgress is called every time a bulk operation has finished in a repository.
+// The calls are made from the worker goroutines, in no particular order.
+type BulkProgress func(result BulkResult)
+
+// RunBulkOperation runs the operation in each of the repositories in paths, at
+// most maxConcurrentBulkOperations at a time, and returns the results in the
+// same order as paths. branch is the branch to check out, for BulkCheckout.
+func RunBulkOperation(ctx context.Context, paths []string, operation BulkOperation, branch string,
+	progress BulkProgress) ([]BulkResult, error) {
+
+	if operation == BulkCheckout && strings.TrimSpace(branch) == "" {
+		return nil, errors.New("no branch to check out")
+	}
+	if operation == BulkCheckout && strings.HasPrefix(branch, "-") {
+		return nil, fmt.Errorf("invalid branch name : %s", branch)
+	}
+
+	args := operation.args(strings.TrimSpace(branch))
+	results := make([]BulkResult, len(paths))
+	forEachPath(ctx, paths, maxConcurrentBulkOperations, func(i int, path string) {
+		if ctx.Err() != nil {
+			results[i] = BulkResult{Path: path, ExitCode: -1, Err: ctx.Err()}
+			return
+		}
+		results[i] = runBulkCommand(ctx, path, args)
+		if progress != nil {
+			progress(results[i])
+		}
+	})
+
+	return results, nil
+}
+
+func runBulkCommand(ctx context.Context, path string, args []string) BulkResult {
+	runner := newRunner(path, bulkTimeout)
+	result, err := runner.Run(ctx, args...)
+
+	return BulkResult{
+		Path:     path,
+		ExitCode: result.ExitCode,
+		Output:   strings.TrimSpace(result.Stdout + result.Stderr),
+		Err:      err,
+	}
+}
+
+// FormatBulkResults returns a summary of the results, with one line per
+// repository that starts with "OK" or "FAILED", followed by its output.
+func FormatBulkResults(results []BulkResult) string {
+	var failed int
+	var sb strings.Builder
+	for _, result := range results {
+		status := "OK"
+		if result.Err != nil {
+			failed++
+			status = "FAILED"
+			if result.ExitCode > 0 {
+				status += fmt.Sprintf(" (exit code %d)", result.ExitCode)
+			} else {
+				status += fmt.Sprintf(" (%s)", result.Err)
+			}
+		}
+		sb.WriteString(fmt.Sprintf("%s %s : %s\n", status, filepath.Base(result.Path), result.Path))
+		for _, line := range strings.Split(result.Output, "\n") {
+			if line != "" {
+				sb.WriteString("    " + line + "\n")
+			}
+		}
+	}
+	sb.WriteString(fmt.Sprintf("\n%d succeeded, %d failed\n", len(results)-failed, failed))
+
+	return sb.String()
+}
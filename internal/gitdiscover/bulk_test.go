@@ -0,0 +1,71 @@
+package gitdiscover
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+func TestBulkOperation_String(t *testing.T) {
+	assert.Equal(t, "git pull --ff-only", BulkPull.String())
+	assert.Equal(t, "git checkout", BulkCheckout.String())
+}
+
+func Test_RunBulkOperation_Pull(t *testing.T) {
+	_, clone := createTestClone(t)
+
+	results, err := RunBulkOperation(context.Background(), []string{clone}, BulkPull, "", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(results))
+	assert.Nil(t, results[0].Err)
+	assert.Equal(t, 0, results[0].ExitCode)
+	assert.FileExists(t, clone+"/new.txt")
+}
+
+func Test_RunBulkOperation_Checkout(t *testing.T) {
+	withBranch := createTestRepository(t)
+	runTestGit(t, withBranch, "branch", "feature")
+	withoutBranch := createTestRepository(t)
+
+	var mutex sync.Mutex
+	var progress []string
+	results, err := RunBulkOperation(context.Background(), []string{withBranch, withoutBranch}, BulkCheckout,
+		"feature", func(result BulkResult) {
+			mutex.Lock()
+			progress = append(progress, result.Path)
+			mutex.Unlock()
+		})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(progress))
+	assert.Nil(t, results[0].Err)
+	assert.NotNil(t, results[1].Err)
+	assert.NotEqual(t, 0, results[1].ExitCode)
+	assert.NotEmpty(t, results[1].Output)
+
+	repo := LoadRepository(context.Background(), &config.Repository{Path: withBranch})
+	assert.Equal(t, "feature", repo.Branch())
+
+	_, err = RunBulkOperation(context.Background(), []string{withBranch}, BulkCheckout, " ", nil)
+	assert.NotNil(t, err)
+	_, err = RunBulkOperation(context.Background(), []string{withBranch}, BulkCheckout, "--orphan", nil)
+	assert.NotNil(t, err)
+}
+
+func Test_FormatBulkResults(t *testing.T) {
+	results := []BulkResult{
+		{Path: "/code/a", Output: "Already up to date."},
+		{Path: "/code/b", ExitCode: 128, Output: "fatal: no remote\n", Err: errors.New("exit status 128")},
+	}
+	lines := strings.Split(FormatBulkResults(results), "\n")
+	assert.Equal(t, "OK a : /code/a", lines[0])
+	assert.Equal(t, "    Already up to date.", lines[1])
+	assert.Equal(t, "FAILED (exit code 128) b : /code/b", lines[2])
+	assert.Equal(t, "    fatal: no remote", lines[3])
+	assert.Equal(t, "1 succeeded, 1 failed", lines[5])
+}
@@ -0,0 +1,127 @@
+package gitdiscover
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChangeSummary contains the number of changes in a repository, per category.
+// A file can be counted in more than one category, for example a file that has
+// been staged, and then modified again, is both staged and modified.
+type ChangeSummary struct {
+	// Staged is the number of files with staged changes (in the index)
+	Staged int
+	// Unstaged is the number of tracked files with changes in the working tree
+	Unstaged int
+	// Untracked is the number of files that are not tracked by git
+	Untracked int
+	// Modified is the number of files that are modified in the working tree
+	Modified int
+	// Deleted is the number of files that are deleted in the working tree
+	Deleted int
+	// Renamed is the number of files that are renamed or copied
+	Renamed int
+	// Unmerged is the number of files with merge conflicts
+	Unmerged int
+	// Stashes is the number of stashes
+	Stashes int
+}
+
+// ChangeCategory is a category of changes in a ChangeSummary.
+type ChangeCategory int
+
+const (
+	ChangesTotal ChangeCategory = iota
+	ChangesStaged
+	ChangesUnstaged
+	ChangesUntracked
+	ChangesModified
+	ChangesDeleted
+	ChangesRenamed
+	ChangesUnmerged
+	ChangesStashes
+)
+
+// ChangeCategories contains all change categories, in display order.
+var ChangeCategories = []ChangeCategory{
+	ChangesTotal, ChangesStaged, ChangesUnstaged, ChangesUntracked, ChangesModified,
+	ChangesDeleted, ChangesRenamed, ChangesUnmerged, ChangesStashes,
+}
+
+var changeCategoryNames = []string{
+	"changes", "staged", "unstaged", "untracked", "modified", "deleted", "renamed", "unmerged", "stashes",
+}
+
+// String returns the (lower case) name of the category.
+func (c ChangeCategory) String() string {
+	if c < 0 || int(c) >= len(changeCategoryNames) {
+		return fmt.Sprintf("ChangeCategory(%d)", int(c))
+	}
+	return changeCategoryNames[c]
+}
+
+// ParseChangeCategory returns the change category with the given name.
+func ParseChangeCategory(name string) (ChangeCategory, error) {
+	for i, categoryName := range changeCategoryNames {
+		if strings.EqualFold(name, categoryName) {
+			return ChangeCategory(i), nil
+		}
+	}
+	return ChangesTotal, fmt.Errorf("invalid change category : %s", name)
+}
+
+// Total returns the number of changed files that are not committed
+// (untracked, modified, deleted and unmerged files).
+func (c ChangeSummary) Total() int {
+	return c.Untracked + c.Modified + c.Deleted + c.Unmerged
+}
+
+// Count returns the number of changes in the given category.
+func (c ChangeSummary) Count(category ChangeCategory) int {
+	switch category {
+	case ChangesStaged:
+		return c.Staged
+	case ChangesUnstaged:
+		return c.Unstaged
+	case ChangesUntracked:
+		return c.Untracked
+	case ChangesModified:
+		return c.Modified
+	case ChangesDeleted:
+		return c.Deleted
+	case ChangesRenamed:
+		return c.Renamed
+	case ChangesUnmerged:
+		return c.Unmerged
+	case ChangesStashes:
+		return c.Stashes
+	default:
+		return c.Total()
+	}
+}
+
+// Breakdown returns one line per category that has changes, like "Staged : 2".
+func (c ChangeSummary) Breakdown() string {
+	var lines []string
+	for _, category := range ChangeCategories[1:] {
+		if count := c.Count(category); count > 0 {
+			name := category.String()
+			lines = append(lines, fmt.Sprintf("%s : %d", strings.ToUpper(name[:1])+name[1:], count))
+		}
+	}
+	if len(lines) == 0 {
+		return "No changes"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FilterByChanges returns the git repositories that have changes in the given category.
+func (f Repositories) FilterByChanges(category ChangeCategory) Repositories {
+	var result Repositories
+	for _, repo := range f {
+		if repo.IsGit() && repo.changeSummary.Count(category) > 0 {
+			result = append(result, repo)
+		}
+	}
+	return result
+}
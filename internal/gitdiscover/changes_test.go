@@ -0,0 +1,47 @@
+package gitdiscover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangeSummary_Total(t *testing.T) {
+	c := ChangeSummary{Staged: 1, Unstaged: 2, Untracked: 3, Modified: 2, Deleted: 1, Renamed: 1, Unmerged: 1, Stashes: 5}
+	assert.Equal(t, 7, c.Total())
+	assert.Equal(t, 7, c.Count(ChangesTotal))
+	assert.Equal(t, 5, c.Count(ChangesStashes))
+	assert.Equal(t, 1, c.Count(ChangesRenamed))
+}
+
+func TestChangeSummary_Breakdown(t *testing.T) {
+	assert.Equal(t, "No changes", ChangeSummary{}.Breakdown())
+	c := ChangeSummary{Staged: 2, Untracked: 1, Stashes: 3}
+	assert.Equal(t, "Staged : 2\nUntracked : 1\nStashes : 3", c.Breakdown())
+}
+
+func TestParseChangeCategory(t *testing.T) {
+	for _, category := range ChangeCategories {
+		parsed, err := ParseChangeCategory(category.String())
+		assert.Nil(t, err)
+		assert.Equal(t, category, parsed)
+	}
+	parsed, err := ParseChangeCategory("Untracked")
+	assert.Nil(t, err)
+	assert.Equal(t, ChangesUntracked, parsed)
+	_, err = ParseChangeCategory("size")
+	assert.NotNil(t, err)
+}
+
+func TestRepositories_FilterByChanges(t *testing.T) {
+	repos := Repositories{
+		{name: "a", isGit: true, changeSummary: ChangeSummary{Staged: 1}},
+		{name: "b", isGit: true, changeSummary: ChangeSummary{Modified: 1, Unstaged: 1}},
+		{name: "c", isGit: false},
+		{name: "d", isGit: true, changeSummary: ChangeSummary{Stashes: 2}},
+	}
+	assert.Equal(t, []string{"b"}, getNames(repos.FilterByChanges(ChangesTotal)))
+	assert.Equal(t, []string{"a"}, getNames(repos.FilterByChanges(ChangesStaged)))
+	assert.Equal(t, []string{"d"}, getNames(repos.FilterByChanges(ChangesStashes)))
+	assert.Empty(t, repos.FilterByChanges(ChangesUnmerged))
+}
@@ -0,0 +1,362 @@
+package gitdiscover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hultan/gitdiscover/internal/gitrunner"
+)
+
+// commitTimeout is the maximum time that staging files, or committing, may take
+const commitTimeout = time.Minute
+
+const (
+	// maxSubjectLength is the recommended maximum length of the subject of a commit message
+	maxSubjectLength = 50
+	// maxMessageLineLength is the recommended maximum length of the other lines in a commit message
+	maxMessageLineLength = 72
+)
+
+// ChangedFile is a file in the output of git status.
+type ChangedFile struct {
+	Path string
+	// OrigPath is the path before the file was renamed or copied
+	OrigPath string
+	// IndexStatus and WorktreeStatus are the staged and unstaged status of
+	// the file, like 'M' or 'D', and '.' if unchanged
+	IndexStatus    byte
+	WorktreeStatus byte
+	Untracked      bool
+	Unmerged       bool
+}
+
+// IsStaged returns true if the file has staged changes.
+func (f *ChangedFile) IsStaged() bool {
+	return !f.Untracked && !f.Unmerged && f.IndexStatus != '.'
+}
+
+// IsUnstaged returns true if the file has changes that are not staged.
+func (f *ChangedFile) IsUnstaged() bool {
+	return f.Untracked || f.Unmerged || f.WorktreeStatus != '.'
+}
+
+// Status returns a short status of the file, like "M." or "??".
+func (f *ChangedFile) Status() string {
+	switch {
+	case f.Untracked:
+		return "??"
+	case f.Unmerged:
+		return "UU"
+	default:
+		return string([]byte{f.IndexStatus, f.WorktreeStatus})
+	}
+}
+
+// GetChangedFiles returns the changed, and untracked, files in the repository at repoPath.
+func GetChangedFiles(ctx context.Context, repoPath string) ([]*ChangedFile, error) {
+	runner := newRunner(repoPath, commitTimeout)
+	runner.Env = []string{"GIT_OPTIONAL_LOCKS=0"}
+	result, err := runner.Run(ctx, "status", "--porcelain=v2", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, getGitError(err)
+	}
+	info, err := parseGitStatus(result.Stdout)
+	if err != nil {
+		return nil, err
+	}
+	return info.files, nil
+}
+
+// StageFiles adds all changes in the files, including deletions, to the index.
+func StageFiles(ctx context.Context, repoPath string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	// --remove stages the deleted files, and --add the untracked files
+	args := append([]string{"update-index", "--add", "--remove", "--"}, paths...)
+	_, err := newRunner(repoPath, commitTimeout).Run(ctx, args...)
+	return getGitError(err)
+}
+
+// UnstageFiles removes the staged changes in the files from the index.
+func UnstageFiles(ctx context.Context, repoPath string, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	runner := newRunner(repoPath, commitTimeout)
+
+	// The files that are in HEAD get their HEAD version back in the index
+	inHead := make(map[string]bool)
+	if _, err := runner.Output(ctx, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		result, err := runner.Run(ctx, append([]string{"ls-tree", "-z", "HEAD", "--"}, paths...)...)
+		if err != nil {
+			return getGitError(err)
+		}
+		if result.Stdout != "" {
+			// The output of ls-tree is the input format of update-index --index-info
+			for _, entry := range strings.Split(strings.TrimSuffix(result.Stdout, "\x00"), "\x00") {
+				if tab := strings.IndexByte(entry, '\t'); tab >= 0 {
+					inHead[entry[tab+1:]] = true
+				}
+			}
+			indexRunner := newRunner(repoPath, commitTimeout)
+			indexRunner.Stdin = strings.NewReader(result.Stdout)
+			if _, err := indexRunner.Run(ctx, "update-index", "-z", "--index-info"); err != nil {
+				return getGitError(err)
+			}
+		}
+	}
+
+	// The other files (all files before the first commit) are removed from the index
+	var added []string
+	for _, path := range paths {
+		if !inHead[path] {
+			added = append(added, path)
+		}
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	_, err := runner.Run(ctx, append([]string{"update-index", "--force-remove", "--"}, added...)...)
+	return getGitError(err)
+}
+
+// GetFileDiff returns the staged, and unstaged, diff of a file.
+func GetFileDiff(ctx context.Context, repoPath string, file *ChangedFile) (staged, unstaged string, err error) {
+	runner := newRunner(repoPath, commitTimeout)
+	if file.Untracked {
+		// git diff --no-index returns exit code 1 if the files differ
+		result, err := runner.Run(ctx, "diff", "--no-color", "--no-index", "--", "/dev/null", file.Path)
+		var exitErr *gitrunner.ExitError
+		if err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode == 1) {
+			return "", "", getGitError(err)
+		}
+		return "", result.Stdout, nil
+	}
+
+	paths := []string{file.Path}
+	if file.OrigPath != "" {
+		paths = append(paths, file.OrigPath)
+	}
+	if file.IsStaged() {
+		result, err := runner.Run(ctx, append([]string{"diff", "--no-color", "--cached", "-M", "--"}, paths...)...)
+		if err != nil {
+			return "", "", getGitError(err)
+		}
+		staged = result.Stdout
+	}
+	if file.IsUnstaged() {
+		result, err := runner.Run(ctx, "diff", "--no-color", "--", file.Path)
+		if err != nil {
+			return "", "", getGitError(err)
+		}
+		unstaged = result.Stdout
+	}
+	return staged, unstaged, nil
+}
+
+// GetLastCommitMessage returns the message of the last commit, to amend it.
+func GetLastCommitMessage(ctx context.Context, repoPath string) (string, error) {
+	message, err := newRunner(repoPath, commitTimeout).Output(ctx, "log", "-1", "--format=%B")
+	return message, getGitError(err)
+}
+
+// CommitChanges commits the staged changes with the message, or replaces
+// the last commit if amend is true. It returns a summary of the commit,
+// like "[main 1a2b3c4] Fix the refresh".
+//
+// The commit is made with plumbing commands (write-tree, commit-tree and
+// update-ref), so the commit hooks are not run, and the commit can not be
+// signed. An error is returned if commit.gpgsign is set, or if a rebase,
+// cherry-pick or revert is in progress. A merge in progress is concluded.
+func CommitChanges(ctx context.Context, repoPath, message string, amend bool) (string, error) {
+	// Remove the comments and the extra blank lines, like git commit --cleanup=strip
+	stripRunner := newRunner(repoPath, commitTimeout)
+	stripRunner.Stdin = strings.NewReader(message)
+	message, err := stripRunner.Output(ctx, "stripspace", "--strip-comments")
+	if err != nil {
+		return "", getGitError(err)
+	}
+	if message == "" {
+		return "", errors.New("the commit message is empty")
+	}
+
+	runner := newRunner(repoPath, commitTimeout)
+	signed, err := runner.Output(ctx, "config", "--bool", "--get", "commit.gpgsign")
+	if err == nil && signed == "true" {
+		return "", errors.New("commit.gpgsign is set, but gitdiscover can not sign commits, commit in a terminal instead")
+	}
+	gitDir, mergeHeads, err := getCommitState(ctx, runner)
+	if err != nil {
+		return "", err
+	}
+
+	tree, err := runner.Output(ctx, "write-tree")
+	if err != nil {
+		return "", getGitError(err)
+	}
+	// head is empty before the first commit
+	head, _ := runner.Output(ctx, "rev-parse", "--verify", "--quiet", "HEAD")
+
+	commitArgs := []string{"commit-tree", tree}
+	reflogAction := "commit"
+	switch {
+	case amend && len(mergeHeads) > 0:
+		return "", errors.New("a merge is in progress, the last commit can not be amended")
+	case amend:
+		if head == "" {
+			return "", errors.New("there is no commit to amend")
+		}
+		// The amended commit keeps the parents, and the author, of the last commit
+		parents, err := runner.Output(ctx, "rev-parse", "HEAD^@")
+		if err != nil {
+			return "", getGitError(err)
+		}
+		for _, parent := range strings.Fields(parents) {
+			commitArgs = append(commitArgs, "-p", parent)
+		}
+		author, err := runner.Output(ctx, "log", "-1", "--format=%an%x00%ae%x00%ad", "--date=raw", "HEAD")
+		if err != nil {
+			return "", getGitError(err)
+		}
+		if fields := strings.Split(author, "\x00"); len(fields) == 3 {
+			runner.Env = []string{"GIT_AUTHOR_NAME=" + fields[0], "GIT_AUTHOR_EMAIL=" + fields[1],
+				"GIT_AUTHOR_DATE=" + fields[2]}
+		}
+		reflogAction = "commit (amend)"
+	case head == "":
+		reflogAction = "commit (initial)"
+	case len(mergeHeads) > 0:
+		// The merge commit can have the same tree as HEAD, like with git merge -s ours
+		commitArgs = append(commitArgs, "-p", head)
+		for _, mergeHead := range mergeHeads {
+			commitArgs = append(commitArgs, "-p", mergeHead)
+		}
+		reflogAction = "commit (merge)"
+	default:
+		headTree, err := runner.Output(ctx, "rev-parse", "HEAD^{tree}")
+		if err != nil {
+			return "", getGitError(err)
+		}
+		if headTree == tree {
+			return "", errors.New("nothing to commit, there are no staged changes")
+		}
+		commitArgs = append(commitArgs, "-p", head)
+	}
+
+	runner.Stdin = strings.NewReader(message + "\n")
+	commit, err := runner.Output(ctx, commitArgs...)
+	if err != nil {
+		return "", getGitError(err)
+	}
+	runner.Stdin = nil
+
+	// Moves the current branch (or a detached HEAD), if it is still at head
+	subject := strings.SplitN(message, "\n", 2)[0]
+	_, err = runner.Run(ctx, "update-ref", "-m", reflogAction+": "+subject, "HEAD", commit, head)
+	if err != nil {
+		return "", getGitError(err)
+	}
+	if len(mergeHeads) > 0 {
+		// The merge is concluded, like git commit does
+		for _, name := range []string{"MERGE_HEAD", "MERGE_MSG", "MERGE_MODE"} {
+			err = os.Remove(filepath.Join(gitDir, name))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return "", err
+			}
+		}
+	}
+
+	branch, err := runner.Output(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		branch = "detached HEAD"
+	}
+	shortCommit, err := runner.Output(ctx, "rev-parse", "--short", commit)
+	if err != nil {
+		return "", getGitError(err)
+	}
+	return fmt.Sprintf("[%s %s] %s", branch, shortCommit, subject), nil
+}
+
+// getCommitState returns the git directory, and the commits that are being
+// merged into HEAD (from MERGE_HEAD). It returns an error if a rebase,
+// cherry-pick or revert is in progress, since they can not be committed here.
+func getCommitState(ctx context.Context, runner *gitrunner.Runner) (string, []string, error) {
+	gitDir, err := runner.Output(ctx, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return "", nil, getGitError(err)
+	}
+	for _, state := range []struct{ name, operation string }{
+		{"CHERRY_PICK_HEAD", "a cherry-pick"},
+		{"REVERT_HEAD", "a revert"},
+		{"rebase-merge", "a rebase"},
+		{"rebase-apply", "a rebase (or git am)"},
+	} {
+		if _, err := os.Stat(filepath.Join(gitDir, state.name)); err == nil {
+			return "", nil, fmt.Errorf("%s is in progress, finish it in a terminal", state.operation)
+		}
+	}
+
+	mergeHead, err := os.ReadFile(filepath.Join(gitDir, "MERGE_HEAD"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return gitDir, nil, nil
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return gitDir, strings.Fields(string(mergeHead)), nil
+}
+
+// CheckCommitMessage returns hints about the format of a commit message,
+// like a subject that is too long. It returns nil if the message looks good.
+func CheckCommitMessage(message string) []string {
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	subject := strings.TrimSpace(lines[0])
+	if subject == "" {
+		return []string{"The subject (first line) is empty"}
+	}
+
+	var hints []string
+	if length := utf8.RuneCountInString(subject); length > maxSubjectLength {
+		hints = append(hints, fmt.Sprintf("The subject is %d characters, try to keep it within %d",
+			length, maxSubjectLength))
+	}
+	if strings.HasSuffix(subject, ".") {
+		hints = append(hints, "The subject should not end with a period")
+	}
+	if len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		hints = append(hints, "Separate the subject from the body with a blank line")
+	}
+	for i, line := range lines[1:] {
+		if utf8.RuneCountInString(line) > maxMessageLineLength {
+			hints = append(hints, fmt.Sprintf("Line %d is longer than %d characters", i+2, maxMessageLineLength))
+		}
+	}
+	return hints
+}
+
+// newRunner returns a git runner for the repository at repoPath, that never
+// prompts for passwords, and gives up after timeout
+func newRunner(repoPath string, timeout time.Duration) *gitrunner.Runner {
+	runner := gitrunner.NewRunner(repoPath)
+	runner.Timeout = timeout
+	runner.NoPrompt = true
+	return runner
+}
+
+// getGitError returns the standard error of git as the error, if there
+// is any, since it explains the problem better than the exit code
+func getGitError(err error) error {
+	var exitErr *gitrunner.ExitError
+	if errors.As(err, &exitErr) && strings.TrimSpace(exitErr.Stderr) != "" {
+		return errors.New(strings.TrimSpace(exitErr.Stderr))
+	}
+	return err
+}
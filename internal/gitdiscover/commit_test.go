@@ -0,0 +1,228 @@
+package gitdiscover
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setTestIdentity(t *testing.T) {
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+}
+
+func getChangedFile(files []*ChangedFile, path string) *ChangedFile {
+	for _, file := range files {
+		if file.Path == path {
+			return file
+		}
+	}
+	return nil
+}
+
+func Test_GetChangedFiles(t *testing.T) {
+	dir := createTestRepository(t)
+	ctx := context.Background()
+	writeTestFile(t, dir, "committed.txt", "changed")
+
+	files, err := GetChangedFiles(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(files))
+
+	committed := getChangedFile(files, "committed.txt")
+	assert.Equal(t, ".M", committed.Status())
+	assert.False(t, committed.IsStaged())
+	assert.True(t, committed.IsUnstaged())
+
+	untracked := getChangedFile(files, "untracked.txt")
+	assert.Equal(t, "??", untracked.Status())
+	assert.True(t, untracked.Untracked)
+
+	err = StageFiles(ctx, dir, []string{"committed.txt", "untracked.txt"})
+	assert.Nil(t, err)
+	files, err = GetChangedFiles(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "M.", getChangedFile(files, "committed.txt").Status())
+	assert.Equal(t, "A.", getChangedFile(files, "untracked.txt").Status())
+
+	err = UnstageFiles(ctx, dir, []string{"committed.txt", "untracked.txt"})
+	assert.Nil(t, err)
+	files, err = GetChangedFiles(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, ".M", getChangedFile(files, "committed.txt").Status())
+	assert.Equal(t, "??", getChangedFile(files, "untracked.txt").Status())
+
+	// Deleted files
+	assert.Nil(t, os.Remove(filepath.Join(dir, "committed.txt")))
+	assert.Nil(t, StageFiles(ctx, dir, []string{"committed.txt"}))
+	files, err = GetChangedFiles(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "D.", getChangedFile(files, "committed.txt").Status())
+	assert.Nil(t, UnstageFiles(ctx, dir, []string{"committed.txt"}))
+	files, err = GetChangedFiles(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, ".D", getChangedFile(files, "committed.txt").Status())
+}
+
+func Test_UnstageFiles_NoCommits(t *testing.T) {
+	dir := t.TempDir()
+	ctx := context.Background()
+	runTestGit(t, dir, "init", "-q")
+	writeTestFile(t, dir, "new.txt", "new")
+	runTestGit(t, dir, "add", "new.txt")
+
+	err := UnstageFiles(ctx, dir, []string{"new.txt"})
+	assert.Nil(t, err)
+	files, err := GetChangedFiles(ctx, dir)
+	assert.Nil(t, err)
+	assert.True(t, getChangedFile(files, "new.txt").Untracked)
+}
+
+func Test_GetFileDiff(t *testing.T) {
+	dir := createTestRepository(t)
+	ctx := context.Background()
+	writeTestFile(t, dir, "committed.txt", "staged")
+	runTestGit(t, dir, "add", "committed.txt")
+	writeTestFile(t, dir, "committed.txt", "unstaged")
+
+	files, err := GetChangedFiles(ctx, dir)
+	assert.Nil(t, err)
+	staged, unstaged, err := GetFileDiff(ctx, dir, getChangedFile(files, "committed.txt"))
+	assert.Nil(t, err)
+	assert.Contains(t, staged, "-committed")
+	assert.Contains(t, staged, "+staged")
+	assert.Contains(t, unstaged, "-staged")
+	assert.Contains(t, unstaged, "+unstaged")
+
+	staged, unstaged, err = GetFileDiff(ctx, dir, getChangedFile(files, "untracked.txt"))
+	assert.Nil(t, err)
+	assert.Equal(t, "", staged)
+	assert.Contains(t, unstaged, "+untracked")
+}
+
+func Test_CommitChanges(t *testing.T) {
+	setTestIdentity(t)
+	dir := createTestRepository(t)
+	ctx := context.Background()
+
+	_, err := CommitChanges(ctx, dir, "Nothing staged", false)
+	assert.NotNil(t, err)
+	_, err = CommitChanges(ctx, dir, "  \n", false)
+	assert.NotNil(t, err)
+
+	err = StageFiles(ctx, dir, []string{"untracked.txt"})
+	assert.Nil(t, err)
+	output, err := CommitChanges(ctx, dir, "Add untracked.txt\n\nWith a body.\n# A comment\n", false)
+	assert.Nil(t, err)
+	assert.Regexp(t, `^\[\S+ [0-9a-f]+\] Add untracked.txt$`, output)
+	message, err := GetLastCommitMessage(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "Add untracked.txt\n\nWith a body.", message)
+
+	// The amended commit keeps the author
+	t.Setenv("GIT_AUTHOR_NAME", "Other")
+	_, err = CommitChanges(ctx, dir, "Add the untracked file", true)
+	assert.Nil(t, err)
+	log := runTestGit(t, dir, "log", "--format=%s %an")
+	assert.Equal(t, "Add the untracked file Test\nInitial commit Test\n", log)
+	assert.Equal(t, "", runTestGit(t, dir, "status", "--porcelain", "--untracked-files=no"))
+}
+
+func Test_CommitChanges_NoCommits(t *testing.T) {
+	setTestIdentity(t)
+	dir := t.TempDir()
+	ctx := context.Background()
+	runTestGit(t, dir, "init", "-q")
+	writeTestFile(t, dir, "new.txt", "new")
+
+	_, err := CommitChanges(ctx, dir, "Amend nothing", true)
+	assert.NotNil(t, err)
+	err = StageFiles(ctx, dir, []string{"new.txt"})
+	assert.Nil(t, err)
+	_, err = CommitChanges(ctx, dir, "First commit", false)
+	assert.Nil(t, err)
+	assert.Equal(t, "First commit\n", runTestGit(t, dir, "log", "--format=%s"))
+}
+
+func Test_CommitChanges_Merge(t *testing.T) {
+	setTestIdentity(t)
+	dir := createTestRepository(t)
+	ctx := context.Background()
+	runTestGit(t, dir, "checkout", "-q", "-b", "side")
+	writeTestFile(t, dir, "side.txt", "side")
+	runTestGit(t, dir, "add", "side.txt")
+	runTestGit(t, dir, "commit", "-q", "-m", "Side commit")
+	runTestGit(t, dir, "checkout", "-q", "main")
+	runTestGit(t, dir, "merge", "-q", "--no-commit", "--no-ff", "side")
+
+	_, err := CommitChanges(ctx, dir, "Amend during the merge", true)
+	assert.NotNil(t, err)
+	_, err = CommitChanges(ctx, dir, "Merge side", false)
+	assert.Nil(t, err)
+	parents := runTestGit(t, dir, "rev-parse", "HEAD^@")
+	assert.Equal(t, 2, len(strings.Fields(parents)))
+	_, err = os.Stat(filepath.Join(dir, ".git", "MERGE_HEAD"))
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(dir, ".git", "MERGE_MSG"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func Test_CommitChanges_Refused(t *testing.T) {
+	setTestIdentity(t)
+	dir := createTestRepository(t)
+	ctx := context.Background()
+	err := StageFiles(ctx, dir, []string{"untracked.txt"})
+	assert.Nil(t, err)
+
+	// Commits can not be signed
+	runTestGit(t, dir, "config", "commit.gpgsign", "true")
+	_, err = CommitChanges(ctx, dir, "Signed", false)
+	assert.NotNil(t, err)
+	runTestGit(t, dir, "config", "commit.gpgsign", "false")
+
+	// A cherry-pick is in progress
+	head := strings.TrimSpace(runTestGit(t, dir, "rev-parse", "HEAD"))
+	writeTestFile(t, dir, ".git/CHERRY_PICK_HEAD", head+"\n")
+	_, err = CommitChanges(ctx, dir, "Cherry-pick", false)
+	assert.NotNil(t, err)
+	assert.Nil(t, os.Remove(filepath.Join(dir, ".git", "CHERRY_PICK_HEAD")))
+
+	_, err = CommitChanges(ctx, dir, "Add untracked.txt", false)
+	assert.Nil(t, err)
+}
+
+func Test_parseGitStatus_Files(t *testing.T) {
+	// The original path of a rename is not an entry, even if it looks like one
+	status := "2 R. N... 100644 100644 100644 abc abc R100 new name.txt\x00? old name.txt\x00" +
+		"u UU N... 100644 100644 100644 100644 a b c conflict.txt\x00" +
+		"? dir/new file.txt\x00"
+	info, err := parseGitStatus(status)
+	assert.Nil(t, err)
+	files := info.files
+	assert.Equal(t, 3, len(files))
+	assert.Equal(t,
+		&ChangedFile{Path: "new name.txt", OrigPath: "? old name.txt", IndexStatus: 'R', WorktreeStatus: '.'}, files[0])
+	assert.Equal(t, ChangeSummary{Staged: 1, Renamed: 1, Unmerged: 1, Untracked: 1}, info.changes)
+	assert.True(t, files[1].Unmerged)
+	assert.Equal(t, "conflict.txt", files[1].Path)
+	assert.Equal(t, "dir/new file.txt", files[2].Path)
+}
+
+func Test_CheckCommitMessage(t *testing.T) {
+	assert.Nil(t, CheckCommitMessage("Fix the refresh\n\nThe body.\n"))
+	assert.Equal(t, []string{"The subject (first line) is empty"}, CheckCommitMessage("\nBody"))
+
+	hints := CheckCommitMessage(strings.Repeat("a", 51) + ".\nNo blank line\n" + strings.Repeat("b", 73))
+	assert.Equal(t, []string{
+		"The subject is 52 characters, try to keep it within 50",
+		"The subject should not end with a period",
+		"Separate the subject from the body with a blank line",
+		"Line 3 is longer than 72 characters",
+	}, hints)
+}
@@ -1,6 +1,9 @@
 package gitdiscover
 
 import (
+	"context"
+	"time"
+
 	"github.com/hultan/gitdiscover/internal/config"
 )
 
@@ -27,20 +30,43 @@ func NewDiscover(config *config.Config) *Discover {
 	return g
 }
 
+// NewEmptyDiscover creates a new Discover object, without loading the
+// repositories. Use Refresh, RefreshContext or LoadRepositories to load them.
+func NewEmptyDiscover(config *config.Config) *Discover {
+	g := &Discover{Config: config}
+	g.RefreshExternalApplications()
+
+	return g
+}
+
 // Refresh refreshes the list of repositories.
 func (d *Discover) Refresh() {
-	// Git Repositories
-	var repositories Repositories
-	for _, configRepo := range d.Config.Repositories {
-		folder := newFolder(configRepo.Path)
-		folder.setImagePath(configRepo.ImagePath)
-		folder.SetIsFavorite(configRepo.IsFavorite)
-
-		repositories = append(repositories, folder)
+	_ = d.RefreshContext(context.Background(), nil)
+}
+
+// RefreshContext refreshes the list of repositories in parallel, see LoadRepositories.
+// If ctx is cancelled, the repositories are left as they were and the context error
+// is returned.
+func (d *Discover) RefreshContext(ctx context.Context, progress RefreshProgress) error {
+	// Git Repositories, both configured and scanned
+	configRepos, err := AddScannedRepositories(ctx, d.Config.Repositories, d.Config.ScanRoots)
+	if err != nil {
+		return err
 	}
-	d.Repositories = repositories
+	repositories, err := LoadRepositories(ctx, configRepos, progress)
+	if err != nil {
+		return err
+	}
+	d.SetRepositories(repositories)
 
 	// External applications
+	d.RefreshExternalApplications()
+
+	return nil
+}
+
+// RefreshExternalApplications refreshes the list of external applications.
+func (d *Discover) RefreshExternalApplications() {
 	var apps []*ExternalApplication
 	for _, application := range d.Config.ExternalApplications {
 		apps = append(apps, &ExternalApplication{
@@ -55,28 +81,36 @@ func (d *Discover) Refresh() {
 // saveForTest saves the Discover object to the config file
 // (FOR USE IN TESTS ONLY!!!)
 func (d *Discover) saveForTest(configPath string) {
-	d.Config.ClearRepositories()
-	for _, repository := range d.Repositories {
-		d.Config.AddRepository(repository.path, repository.imagePath, repository.isFavorite)
-	}
-
-	d.Config.ClearExternalApplications()
-	for _, application := range d.ExternalApplications {
-		d.Config.AddExternalApplication(
-			application.Name,
-			application.Command,
-			application.Argument,
-		)
-	}
+	d.updateConfig()
 	d.Config.Save(configPath)
 }
 
 // Save saves the Discover object to the config file
 // FOR USE IN PRODUCTION CODE ONLY!!!
 func (d *Discover) Save() {
-	d.Config.ClearRepositories()
+	d.updateConfig()
+	d.Config.Save("")
+}
+
+// updateConfig merges the loaded repositories (like the favorites and the
+// groups) and the external applications into the config. The repositories
+// in the config that are not loaded, because the first refresh is not done
+// yet, or because they were added after the last refresh, are kept.
+func (d *Discover) updateConfig() {
 	for _, repository := range d.Repositories {
-		d.Config.AddRepository(repository.path, repository.imagePath, repository.isFavorite)
+		if !repository.shouldSave() {
+			// A scanned repository that is no longer a favorite, or in a group
+			d.Config.RemoveRepository(repository.path)
+			continue
+		}
+		// Saved repositories are configured, even if they were found by a scan
+		configRepo := repository.ToConfig()
+		configRepo.ScanRoot = ""
+		if existing := d.Config.GetRepositoryByPath(configRepo.Path); existing != nil {
+			*existing = *configRepo
+		} else {
+			d.Config.Repositories = append(d.Config.Repositories, configRepo)
+		}
 	}
 
 	d.Config.ClearExternalApplications()
@@ -87,25 +121,53 @@ func (d *Discover) Save() {
 			application.Argument,
 		)
 	}
-	d.Config.Save("")
 }
 
 // ClearRepositories clears the slice of repositories
 func (d *Discover) ClearRepositories() {
 	d.Config.ClearRepositories()
-	d.Refresh()
+	d.Repositories = nil
 }
 
-// AddRepository adds a new repository
+// AddRepository adds a new repository to the config. It is
+// loaded by the next Refresh (or by the GUI refresh).
 func (d *Discover) AddRepository(path, imagePath string, isFavorite bool) {
 	d.Config.AddRepository(path, imagePath, isFavorite)
-	d.Refresh()
 }
 
-// RemoveRepository adds a new repository
+// RemoveRepository removes a repository from the config, and from the loaded repositories
 func (d *Discover) RemoveRepository(path string) {
 	d.Config.RemoveRepository(path)
-	d.Refresh()
+	for i, repo := range d.Repositories {
+		if repo.path == path {
+			d.Repositories = append(d.Repositories[:i], d.Repositories[i+1:]...)
+			break
+		}
+	}
+}
+
+// SetRepositories replaces the loaded repositories, and sets their last fetch
+// times from the config. The fetch times of the repositories that are gone
+// are removed from the config.
+func (d *Discover) SetRepositories(repos Repositories) {
+	fetchTimes := make(map[string]time.Time)
+	for _, repo := range repos {
+		if t := d.Config.GetFetchTime(repo.path); !t.IsZero() {
+			repo.SetLastFetch(t)
+			fetchTimes[repo.path] = t
+		}
+	}
+	d.Config.FetchTimes = fetchTimes
+	d.Repositories = repos
+}
+
+// SetFetchTime sets the last fetch time of the repository at path, both
+// in the loaded repository and in the config
+func (d *Discover) SetFetchTime(path string, t time.Time) {
+	if repo := d.GetRepositoryByPath(path); repo != nil {
+		repo.SetLastFetch(t)
+	}
+	d.Config.SetFetchTime(path, t)
 }
 
 // GetRepositoryByIndex gets an external application by index
@@ -113,6 +175,30 @@ func (d *Discover) GetRepositoryByIndex(i int) *Repository {
 	return d.Repositories[i]
 }
 
+// GetRepositoryByPath gets a repository by path, or nil if there is no such repository
+func (d *Discover) GetRepositoryByPath(path string) *Repository {
+	for _, repo := range d.Repositories {
+		if repo.path == path {
+			return repo
+		}
+	}
+	return nil
+}
+
+// ReplaceRepository replaces the repository that has the same path as repo,
+// and returns its index. If there is no such repository, -1 is returned.
+// The last fetch time of repo is set from the config.
+func (d *Discover) ReplaceRepository(repo *Repository) int {
+	for i := range d.Repositories {
+		if d.Repositories[i].path == repo.path {
+			repo.SetLastFetch(d.Config.GetFetchTime(repo.path))
+			d.Repositories[i] = repo
+			return i
+		}
+	}
+	return -1
+}
+
 // GetExternalApplicationByName gets an external application by name
 func (d *Discover) GetExternalApplicationByName(name string) *ExternalApplication {
 	ea := d.Config.GetExternalApplicationByName(name)
@@ -131,19 +217,19 @@ func (d *Discover) GetExternalApplicationByIndex(i int) *ExternalApplication {
 // ClearExternalApplications clears the slice of external applications
 func (d *Discover) ClearExternalApplications() {
 	d.Config.ClearExternalApplications()
-	d.Refresh()
+	d.RefreshExternalApplications()
 }
 
 // AddExternalApplication adds an external application
 func (d *Discover) AddExternalApplication(name, command, argument string) {
 	d.Config.AddExternalApplication(name, command, argument)
-	d.Refresh()
+	d.RefreshExternalApplications()
 }
 
 // RemoveExternalApplication adds a new extenal application
 func (d *Discover) RemoveExternalApplication(name string) {
 	d.Config.RemoveExternalApplication(name)
-	d.Refresh()
+	d.RefreshExternalApplications()
 }
 
 // GetDateFormat returns the date format
@@ -2,6 +2,7 @@ package gitdiscover
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -46,3 +47,57 @@ func getConfig() *config.Config {
 	_ = c.Load(testConfigPath)
 	return c
 }
+
+func Test_UpdateConfig(t *testing.T) {
+	c := config.NewConfig()
+	c.AddRepository("configured", "", false)
+	c.AddRepository("favorite", "", true)
+
+	// The repositories in the config are kept until they are loaded
+	d := NewEmptyDiscover(c)
+	d.updateConfig()
+	assert.Equal(t, 2, len(c.Repositories))
+
+	// Loaded repositories are merged into the config, and repositories
+	// added after the last refresh are kept
+	d.Repositories = Repositories{
+		&Repository{path: "favorite", group: "work"},
+		&Repository{path: "scanned", scanRoot: "/code"},
+		&Repository{path: "scanned-favorite", scanRoot: "/code", isFavorite: true},
+	}
+	d.updateConfig()
+	var paths []string
+	for _, repo := range c.Repositories {
+		paths = append(paths, repo.Path)
+	}
+	assert.Equal(t, []string{"configured", "favorite", "scanned-favorite"}, paths)
+	assert.Equal(t, "work", c.GetRepositoryByPath("favorite").Group)
+	assert.False(t, c.GetRepositoryByPath("favorite").IsFavorite)
+
+	d.RemoveRepository("favorite")
+	assert.Nil(t, c.GetRepositoryByPath("favorite"))
+	assert.Equal(t, 2, len(d.Repositories))
+}
+
+func Test_SetRepositories_FetchTimes(t *testing.T) {
+	now := time.Now()
+	c := config.NewConfig()
+	c.SetFetchTime("scanned", now)
+	c.SetFetchTime("gone", now)
+
+	// Scanned repositories get their fetch time from the config, and
+	// the fetch times of repositories that are gone are removed
+	d := NewEmptyDiscover(c)
+	d.SetRepositories(Repositories{
+		&Repository{path: "scanned", scanRoot: "/code"},
+		&Repository{path: "configured"},
+	})
+	assert.Equal(t, now, d.GetRepositoryByPath("scanned").LastFetch())
+	assert.True(t, d.GetRepositoryByPath("configured").LastFetch().IsZero())
+	assert.Equal(t, map[string]time.Time{"scanned": now}, c.FetchTimes)
+
+	later := now.Add(time.Minute)
+	d.SetFetchTime("configured", later)
+	assert.Equal(t, later, d.GetRepositoryByPath("configured").LastFetch())
+	assert.Equal(t, later, c.GetFetchTime("configured"))
+}
@@ -0,0 +1,221 @@
+package gitdiscover
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportFormat is a file format that repositories can be exported to.
+type ExportFormat int
+
+const (
+	ExportJSON ExportFormat = iota
+	ExportCSV
+	ExportYAML
+)
+
+// ExportSchemaVersion is the version of the export schema. It is
+// only increased when a field is removed or changes meaning, new
+// fields can be added without changing the version.
+const ExportSchemaVersion = 1
+
+// Export is the document written by Repositories.Export for JSON and YAML.
+//
+// JSON and YAML use the same keys, for example:
+//
+//	{
+//	  "schema-version": 1,
+//	  "exported": "2021-09-01T10:00:00+02:00",
+//	  "repositories": [ { "name": "gitdiscover", ... } ]
+//	}
+//
+// CSV exports have one header row with the keys of ExportedRepository,
+// in the order they are declared, followed by one row per repository.
+type Export struct {
+	SchemaVersion int                  `json:"schema-version" yaml:"schema-version"`
+	Exported      time.Time            `json:"exported" yaml:"exported"`
+	Repositories  []ExportedRepository `json:"repositories" yaml:"repositories"`
+}
+
+// ExportedRepository is the exported state of a single repository.
+// Dates are written in RFC 3339 format.
+type ExportedRepository struct {
+	Name            string    `json:"name" yaml:"name"`
+	Path            string    `json:"path" yaml:"path"`
+	IsGit           bool      `json:"is-git" yaml:"is-git"`
+	ModifiedDate    time.Time `json:"modified-date" yaml:"modified-date"`
+	GitStatus       string    `json:"git-status" yaml:"git-status"`
+	Branch          string    `json:"branch" yaml:"branch"`
+	Upstream        string    `json:"upstream" yaml:"upstream"`
+	Ahead           int       `json:"ahead" yaml:"ahead"`
+	Behind          int       `json:"behind" yaml:"behind"`
+	IsDetached      bool      `json:"is-detached" yaml:"is-detached"`
+	GoStatus        string    `json:"go-status" yaml:"go-status"`
+	Changes         int       `json:"changes" yaml:"changes"`
+	Staged          int       `json:"staged" yaml:"staged"`
+	Unstaged        int       `json:"unstaged" yaml:"unstaged"`
+	Untracked       int       `json:"untracked" yaml:"untracked"`
+	Modified        int       `json:"modified" yaml:"modified"`
+	Deleted         int       `json:"deleted" yaml:"deleted"`
+	Renamed         int       `json:"renamed" yaml:"renamed"`
+	Unmerged        int       `json:"unmerged" yaml:"unmerged"`
+	Stashes         int       `json:"stashes" yaml:"stashes"`
+	LatestTag       string    `json:"latest-tag" yaml:"latest-tag"`
+	CommitsSinceTag int       `json:"commits-since-tag" yaml:"commits-since-tag"`
+	HasRemote       bool      `json:"has-remote" yaml:"has-remote"`
+	IsFavorite      bool      `json:"is-favorite" yaml:"is-favorite"`
+	Group           string    `json:"group" yaml:"group"`
+	IsScanned       bool      `json:"is-scanned" yaml:"is-scanned"`
+}
+
+var exportCSVHeader = []string{
+	"name", "path", "is-git", "modified-date", "git-status", "branch", "upstream", "ahead", "behind",
+	"is-detached", "go-status", "changes", "staged", "unstaged", "untracked", "modified", "deleted", "renamed",
+	"unmerged", "stashes", "latest-tag", "commits-since-tag", "has-remote", "is-favorite", "group", "is-scanned",
+}
+
+// ParseExportFormat returns the export format with the given name (json, csv or yaml).
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch strings.ToLower(name) {
+	case "json":
+		return ExportJSON, nil
+	case "csv":
+		return ExportCSV, nil
+	case "yaml", "yml":
+		return ExportYAML, nil
+	default:
+		return ExportJSON, fmt.Errorf("invalid export format : %s", name)
+	}
+}
+
+// Extension returns the file extension for the export format.
+func (e ExportFormat) Extension() string {
+	switch e {
+	case ExportCSV:
+		return ".csv"
+	case ExportYAML:
+		return ".yaml"
+	default:
+		return ".json"
+	}
+}
+
+// Export writes the repositories to w in the given format.
+func (f Repositories) Export(w io.Writer, format ExportFormat) error {
+	var exported []ExportedRepository
+	for _, repo := range f {
+		exported = append(exported, repo.export())
+	}
+
+	switch format {
+	case ExportJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "\t")
+		return encoder.Encode(newExport(exported))
+	case ExportCSV:
+		return exportCSV(w, exported)
+	case ExportYAML:
+		encoder := yaml.NewEncoder(w)
+		err := encoder.Encode(newExport(exported))
+		if err != nil {
+			return err
+		}
+		return encoder.Close()
+	default:
+		return fmt.Errorf("invalid export format : %d", format)
+	}
+}
+
+func newExport(repos []ExportedRepository) *Export {
+	if repos == nil {
+		repos = []ExportedRepository{}
+	}
+	return &Export{
+		SchemaVersion: ExportSchemaVersion,
+		Exported:      time.Now().Truncate(time.Second),
+		Repositories:  repos,
+	}
+}
+
+func exportCSV(w io.Writer, repos []ExportedRepository) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write(exportCSVHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		err = writer.Write([]string{
+			repo.Name,
+			repo.Path,
+			strconv.FormatBool(repo.IsGit),
+			repo.ModifiedDate.Format(time.RFC3339),
+			repo.GitStatus,
+			repo.Branch,
+			repo.Upstream,
+			strconv.Itoa(repo.Ahead),
+			strconv.Itoa(repo.Behind),
+			strconv.FormatBool(repo.IsDetached),
+			repo.GoStatus,
+			strconv.Itoa(repo.Changes),
+			strconv.Itoa(repo.Staged),
+			strconv.Itoa(repo.Unstaged),
+			strconv.Itoa(repo.Untracked),
+			strconv.Itoa(repo.Modified),
+			strconv.Itoa(repo.Deleted),
+			strconv.Itoa(repo.Renamed),
+			strconv.Itoa(repo.Unmerged),
+			strconv.Itoa(repo.Stashes),
+			repo.LatestTag,
+			strconv.Itoa(repo.CommitsSinceTag),
+			strconv.FormatBool(repo.HasRemote),
+			strconv.FormatBool(repo.IsFavorite),
+			repo.Group,
+			strconv.FormatBool(repo.IsScanned),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func (t *Repository) export() ExportedRepository {
+	return ExportedRepository{
+		Name:            t.name,
+		Path:            t.path,
+		IsGit:           t.isGit,
+		ModifiedDate:    t.modifiedDate,
+		GitStatus:       t.gitStatus,
+		Branch:          t.branch,
+		Upstream:        t.upstream,
+		Ahead:           t.ahead,
+		Behind:          t.behind,
+		IsDetached:      t.isDetached,
+		GoStatus:        strings.TrimSpace(t.goStatus),
+		Changes:         t.Changes(),
+		Staged:          t.changeSummary.Staged,
+		Unstaged:        t.changeSummary.Unstaged,
+		Untracked:       t.changeSummary.Untracked,
+		Modified:        t.changeSummary.Modified,
+		Deleted:         t.changeSummary.Deleted,
+		Renamed:         t.changeSummary.Renamed,
+		Unmerged:        t.changeSummary.Unmerged,
+		Stashes:         t.changeSummary.Stashes,
+		LatestTag:       t.latestTag,
+		CommitsSinceTag: t.commitsSinceTag,
+		HasRemote:       t.HasRemote(),
+		IsFavorite:      t.isFavorite,
+		Group:           t.group,
+		IsScanned:       t.IsScanned(),
+	}
+}
@@ -0,0 +1,76 @@
+package gitdiscover
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func getExportRepositories() Repositories {
+	date := time.Date(2021, 9, 1, 10, 0, 0, 0, time.UTC)
+	return Repositories{
+		&Repository{
+			name: "gitdiscover", path: "/code/gitdiscover", isGit: true, modifiedDate: date,
+			gitStatus: "main↑1|~2", branch: "main", upstream: "origin/main", ahead: 1, goStatus: "   Go 1.17",
+			changeSummary: ChangeSummary{Unstaged: 2, Modified: 2, Stashes: 1}, latestTag: "v1.2.0", commitsSinceTag: 3,
+			remotes:    []*Remote{{Name: "origin", FetchURLs: []string{"git@github.com:hultan/gitdiscover.git"}}},
+			isFavorite: true, group: "work",
+		},
+		&Repository{name: "notes", path: "/code/notes", modifiedDate: date},
+	}
+}
+
+func Test_ParseExportFormat(t *testing.T) {
+	format, err := ParseExportFormat("YAML")
+	assert.Nil(t, err)
+	assert.Equal(t, ExportYAML, format)
+	_, err = ParseExportFormat("xml")
+	assert.NotNil(t, err)
+}
+
+func Test_ExportJSON(t *testing.T) {
+	var buf bytes.Buffer
+	err := getExportRepositories().Export(&buf, ExportJSON)
+	assert.Nil(t, err)
+
+	var export Export
+	err = json.Unmarshal(buf.Bytes(), &export)
+	assert.Nil(t, err)
+	assert.Equal(t, ExportSchemaVersion, export.SchemaVersion)
+	assert.Equal(t, 2, len(export.Repositories))
+	assert.Equal(t, "Go 1.17", export.Repositories[0].GoStatus)
+	assert.True(t, export.Repositories[0].HasRemote)
+	assert.Contains(t, buf.String(), `"modified-date": "2021-09-01T10:00:00Z"`)
+}
+
+func Test_ExportYAML(t *testing.T) {
+	var buf bytes.Buffer
+	err := getExportRepositories().Export(&buf, ExportYAML)
+	assert.Nil(t, err)
+
+	var export Export
+	err = yaml.Unmarshal(buf.Bytes(), &export)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(export.Repositories))
+	assert.Equal(t, "/code/notes", export.Repositories[1].Path)
+}
+
+func Test_ExportCSV(t *testing.T) {
+	var buf bytes.Buffer
+	err := getExportRepositories().Export(&buf, ExportCSV)
+	assert.Nil(t, err)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(records))
+	assert.Equal(t, exportCSVHeader, records[0])
+	assert.Equal(t, []string{
+		"gitdiscover", "/code/gitdiscover", "true", "2021-09-01T10:00:00Z", "main↑1|~2", "main", "origin/main", "1", "0",
+		"false", "Go 1.17", "2", "0", "2", "0", "2", "0", "0", "0", "1", "v1.2.0", "3", "true", "true", "work", "false",
+	}, records[1])
+}
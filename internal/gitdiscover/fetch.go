@@ -0,0 +1,94 @@
+package gitdiscover
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hultan/gitdiscover/internal/gitrunner"
+)
+
+// fetchTimeout is the maximum time that fetching a single repository may take
+const fetchTimeout = 2 * time.Minute
+
+// maxConcurrentFetches is the maximum number of repositories that are fetched
+// at the same time, so that we don't flood the network (or the git servers)
+const maxConcurrentFetches = 4
+
+// FetchResult contains the result of fetching a single repository.
+type FetchResult struct {
+	Path   string
+	Time   time.Time
+	Output string
+	Err    error
+}
+
+// FetchProgress is called every time a repository has been fetched.
+// The calls are made from the worker goroutines, in no particular order.
+type FetchProgress func(result FetchResult)
+
+// FetchRepositories runs git fetch in each of the repositories in paths, at
+// most maxConcurrentFetches at a time. The results are returned in the same
+// order as paths. Repositories that have not been fetched when ctx is
+// cancelled get the context error.
+func FetchRepositories(ctx context.Context, paths []string, progress FetchProgress) []FetchResult {
+	results := make([]FetchResult, len(paths))
+
+	forEachPath(ctx, paths, maxConcurrentFetches, func(i int, path string) {
+		if ctx.Err() != nil {
+			results[i] = FetchResult{Path: path, Time: time.Now(), Err: ctx.Err()}
+			return
+		}
+		results[i] = FetchRepository(ctx, path)
+		if progress != nil {
+			progress(results[i])
+		}
+	})
+
+	return results
+}
+
+// forEachPath calls f for each of the paths, in separate goroutines, with at
+// most limit calls running at the same time. If ctx is cancelled, f is called
+// directly for the remaining paths, so it must check ctx.Err() itself.
+func forEachPath(ctx context.Context, paths []string, limit int, f func(i int, path string)) {
+	running := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range paths {
+		select {
+		case running <- struct{}{}:
+		case <-ctx.Done():
+			f(i, paths[i])
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-running }()
+			f(i, paths[i])
+		}(i)
+	}
+	wg.Wait()
+}
+
+// FetchRepository runs git fetch (all remotes) in the repository at path.
+func FetchRepository(ctx context.Context, path string) FetchResult {
+	runner := newRunner(path, fetchTimeout)
+	result, err := runner.Run(ctx, "fetch", "--all", "--quiet")
+
+	fetchResult := FetchResult{Path: path, Time: time.Now()}
+	fetchResult.Output = strings.TrimSpace(result.Stdout + result.Stderr)
+	if err != nil {
+		var exitErr *gitrunner.ExitError
+		if errors.As(err, &exitErr) && fetchResult.Output != "" {
+			fetchResult.Err = errors.New(fetchResult.Output)
+		} else {
+			fetchResult.Err = err
+		}
+	}
+
+	return fetchResult
+}
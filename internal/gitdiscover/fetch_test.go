@@ -0,0 +1,94 @@
+package gitdiscover
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+// Creates a bare repository, and a clone of it, and then pushes a
+// new commit to the bare repository from a second clone, so that
+// the first clone is one commit behind after a fetch.
+func createTestClone(t *testing.T) (bare, clone string) {
+	source := createTestRepository(t)
+	bare = filepath.Join(t.TempDir(), "remote.git")
+	runTestGit(t, source, "clone", "-q", "--bare", source, bare)
+
+	clone = filepath.Join(t.TempDir(), "clone")
+	runTestGit(t, source, "clone", "-q", bare, clone)
+
+	other := filepath.Join(t.TempDir(), "other")
+	runTestGit(t, source, "clone", "-q", bare, other)
+	writeTestFile(t, other, "new.txt", "new")
+	runTestGit(t, other, "add", "new.txt")
+	runTestGit(t, other, "commit", "-q", "-m", "New commit")
+	runTestGit(t, other, "push", "-q", "origin", "main")
+
+	return bare, clone
+}
+
+func Test_FetchRepositories(t *testing.T) {
+	_, clone := createTestClone(t)
+	notGit := t.TempDir()
+
+	repo := LoadRepository(context.Background(), &config.Repository{Path: clone})
+	assert.Equal(t, 0, repo.Behind())
+
+	var mutex sync.Mutex
+	var fetched []string
+	results := FetchRepositories(context.Background(), []string{clone, notGit}, func(result FetchResult) {
+		mutex.Lock()
+		fetched = append(fetched, result.Path)
+		mutex.Unlock()
+	})
+
+	assert.ElementsMatch(t, []string{clone, notGit}, fetched)
+	assert.Equal(t, 2, len(results))
+	assert.Equal(t, clone, results[0].Path)
+	assert.Nil(t, results[0].Err)
+	assert.False(t, results[0].Time.IsZero())
+	assert.Equal(t, notGit, results[1].Path)
+	assert.NotNil(t, results[1].Err)
+
+	repo = LoadRepository(context.Background(), &config.Repository{Path: clone})
+	assert.Equal(t, 1, repo.Behind())
+}
+
+func Test_FetchRepositories_Cancelled(t *testing.T) {
+	_, clone := createTestClone(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results := FetchRepositories(ctx, []string{clone}, nil)
+	assert.Equal(t, 1, len(results))
+	assert.NotNil(t, results[0].Err)
+}
+
+func TestRepository_NeedsFetch(t *testing.T) {
+	_, clone := createTestClone(t)
+	now := time.Now()
+
+	repo := LoadRepository(context.Background(), &config.Repository{Path: clone})
+	assert.True(t, repo.NeedsFetch(time.Hour, now))
+	repo.SetLastFetch(now.Add(-time.Minute))
+	assert.False(t, repo.NeedsFetch(time.Hour, now))
+	assert.True(t, repo.NeedsFetch(time.Minute, now))
+
+	// The fetch time is saved in the config, and kept when the repository is reloaded
+	d := NewEmptyDiscover(config.NewConfig())
+	d.SetRepositories(Repositories{repo})
+	d.SetFetchTime(clone, now)
+	assert.Equal(t, now, d.Config.GetFetchTime(clone))
+	d.ReplaceRepository(LoadRepository(context.Background(), repo.ToConfig()))
+	assert.Equal(t, now, d.GetRepositoryByPath(clone).LastFetch())
+
+	// Repositories without a remote are never fetched
+	repo = LoadRepository(context.Background(), &config.Repository{Path: createTestRepository(t)})
+	assert.False(t, repo.NeedsFetch(0, now))
+}
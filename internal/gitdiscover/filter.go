@@ -0,0 +1,66 @@
+package gitdiscover
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+// Filter decides which repositories are shown in the repository list.
+type Filter struct {
+	config config.Filter
+	text   string
+	regex  *regexp.Regexp
+}
+
+// NewFilter creates a filter from the filter in the config. It returns
+// an error if the text is not a valid regular expression.
+func NewFilter(filter config.Filter) (*Filter, error) {
+	f := new(Filter)
+	f.config = filter
+	f.text = strings.ToLower(strings.TrimSpace(filter.Text))
+	if filter.Regex && f.text != "" {
+		regex, err := regexp.Compile("(?i)" + strings.TrimSpace(filter.Text))
+		if err != nil {
+			return nil, err
+		}
+		f.regex = regex
+	}
+	return f, nil
+}
+
+// IsEmpty returns true if the filter matches all repositories.
+func (f *Filter) IsEmpty() bool {
+	return f.text == "" && !f.config.OnlyDirty && !f.config.OnlyAhead &&
+		!f.config.OnlyWithoutRemote && !f.config.OnlyGoModules
+}
+
+// Match returns true if the repository should be shown.
+func (f *Filter) Match(repo *Repository) bool {
+	switch {
+	case f.regex != nil:
+		if !f.regex.MatchString(repo.Name()) && !f.regex.MatchString(repo.Path()) {
+			return false
+		}
+	case f.text != "":
+		if !strings.Contains(strings.ToLower(repo.Name()), f.text) &&
+			!strings.Contains(strings.ToLower(repo.Path()), f.text) {
+			return false
+		}
+	}
+
+	if f.config.OnlyDirty && !(repo.IsGit() && (repo.Changes() > 0 || repo.ChangeSummary().Staged > 0)) {
+		return false
+	}
+	if f.config.OnlyAhead && repo.Ahead() == 0 {
+		return false
+	}
+	if f.config.OnlyWithoutRemote && !(repo.IsGit() && !repo.HasRemote()) {
+		return false
+	}
+	if f.config.OnlyGoModules && strings.TrimSpace(repo.GoStatus()) == "" {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,55 @@
+package gitdiscover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+func getFilterRepositories() Repositories {
+	return Repositories{
+		&Repository{name: "gitdiscover", path: "/code/gitdiscover", isGit: true, goStatus: "   Go 1.17",
+			ahead: 1, changeSummary: ChangeSummary{Modified: 2},
+			remotes: []*Remote{{Name: "origin", FetchURLs: []string{"git@github.com:hultan/gitdiscover.git"}}}},
+		&Repository{name: "softteam", path: "/code/softteam", isGit: true, changeSummary: ChangeSummary{Staged: 1}},
+		&Repository{name: "notes", path: "/home/notes"},
+	}
+}
+
+func getMatchingNames(t *testing.T, filter config.Filter) []string {
+	f, err := NewFilter(filter)
+	assert.Nil(t, err)
+	var names []string
+	for _, repo := range getFilterRepositories() {
+		if f.Match(repo) {
+			names = append(names, repo.Name())
+		}
+	}
+	return names
+}
+
+func Test_Filter_Text(t *testing.T) {
+	assert.Equal(t, []string{"gitdiscover", "softteam", "notes"}, getMatchingNames(t, config.Filter{}))
+	assert.Equal(t, []string{"gitdiscover"}, getMatchingNames(t, config.Filter{Text: "DISCO"}))
+	assert.Equal(t, []string{"gitdiscover", "softteam"}, getMatchingNames(t, config.Filter{Text: "/code/"}))
+	assert.Equal(t, []string{"softteam", "notes"}, getMatchingNames(t, config.Filter{Text: "^(soft|notes)", Regex: true}))
+}
+
+func Test_Filter_Toggles(t *testing.T) {
+	assert.Equal(t, []string{"gitdiscover", "softteam"}, getMatchingNames(t, config.Filter{OnlyDirty: true}))
+	assert.Equal(t, []string{"gitdiscover"}, getMatchingNames(t, config.Filter{OnlyAhead: true}))
+	assert.Equal(t, []string{"softteam"}, getMatchingNames(t, config.Filter{OnlyWithoutRemote: true}))
+	assert.Equal(t, []string{"gitdiscover"}, getMatchingNames(t, config.Filter{OnlyGoModules: true}))
+	assert.Nil(t, getMatchingNames(t, config.Filter{Text: "notes", OnlyDirty: true}))
+}
+
+func Test_Filter_InvalidRegex(t *testing.T) {
+	_, err := NewFilter(config.Filter{Text: "git(", Regex: true})
+	assert.NotNil(t, err)
+
+	f, err := NewFilter(config.Filter{Text: "git("})
+	assert.Nil(t, err)
+	assert.False(t, f.IsEmpty())
+}
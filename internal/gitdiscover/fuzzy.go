@@ -0,0 +1,109 @@
+package gitdiscover
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// The score of a matching character, and the bonus when it follows
+// the previous matching character, or starts a word
+const (
+	fuzzyScoreMatch       = 1
+	fuzzyBonusConsecutive = 5
+	fuzzyBonusWordStart   = 3
+	fuzzyMaxLeadingGap    = 5
+)
+
+// FuzzyMatch returns true if all the characters in pattern are in text, in the
+// same order, ignoring case and the spaces in pattern. The score is higher when
+// the matching characters follow each other, or start words in text.
+func FuzzyMatch(pattern, text string) (int, bool) {
+	pattern = strings.ToLower(strings.Join(strings.Fields(pattern), ""))
+	if pattern == "" {
+		return 0, true
+	}
+
+	needle := []rune(pattern)
+	runes := []rune(text)
+	score, n, last := 0, 0, -1
+	for i, r := range runes {
+		if n == len(needle) {
+			break
+		}
+		if unicode.ToLower(r) != needle[n] {
+			continue
+		}
+		score += fuzzyScoreMatch
+		if last >= 0 && last == i-1 {
+			score += fuzzyBonusConsecutive
+		}
+		if isWordStart(runes, i) {
+			score += fuzzyBonusWordStart
+		}
+		if last < 0 {
+			// Matches at the start of the text are better
+			gap := i
+			if gap > fuzzyMaxLeadingGap {
+				gap = fuzzyMaxLeadingGap
+			}
+			score -= gap
+		}
+		last = i
+		n++
+	}
+	if n < len(needle) {
+		return 0, false
+	}
+	return score, true
+}
+
+// Returns true if the rune at i is the first letter of a word, like
+// the "s" in "git status", "git-status" or "gitStatus"
+func isWordStart(runes []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, r := runes[i-1], runes[i]
+	switch {
+	case unicode.IsSpace(prev) || strings.ContainsRune("-_/.:", prev):
+		return true
+	case unicode.IsLower(prev) && unicode.IsUpper(r):
+		return true
+	default:
+		return false
+	}
+}
+
+// FuzzyFilter returns the indexes of the texts that match pattern, the best
+// match first. Texts with the same score are kept in their original order.
+func FuzzyFilter(pattern string, texts []string) []int {
+	var indexes, scores []int
+	for i, text := range texts {
+		if score, ok := FuzzyMatch(pattern, text); ok {
+			indexes = append(indexes, i)
+			scores = append(scores, score)
+		}
+	}
+	sort.Stable(fuzzyResults{indexes: indexes, scores: scores})
+	return indexes
+}
+
+// fuzzyResults sorts matching texts by score, the highest score first
+type fuzzyResults struct {
+	indexes []int
+	scores  []int
+}
+
+func (r fuzzyResults) Len() int {
+	return len(r.indexes)
+}
+
+func (r fuzzyResults) Less(i, j int) bool {
+	return r.scores[i] > r.scores[j]
+}
+
+func (r fuzzyResults) Swap(i, j int) {
+	r.indexes[i], r.indexes[j] = r.indexes[j], r.indexes[i]
+	r.scores[i], r.scores[j] = r.scores[j], r.scores[i]
+}
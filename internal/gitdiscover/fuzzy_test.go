@@ -0,0 +1,46 @@
+package gitdiscover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_FuzzyMatch(t *testing.T) {
+	_, ok := FuzzyMatch("gst", "Git status")
+	assert.True(t, ok)
+	_, ok = FuzzyMatch("GIT ST", "git status")
+	assert.True(t, ok)
+	_, ok = FuzzyMatch("", "anything")
+	assert.True(t, ok)
+
+	// The characters must be in the same order
+	_, ok = FuzzyMatch("tsg", "git status")
+	assert.False(t, ok)
+	_, ok = FuzzyMatch("gitx", "git")
+	assert.False(t, ok)
+}
+
+func Test_FuzzyMatch_Score(t *testing.T) {
+	// Consecutive characters are better than scattered characters
+	consecutive, _ := FuzzyMatch("dis", "discover")
+	scattered, _ := FuzzyMatch("dis", "diff status")
+	assert.Greater(t, consecutive, scattered)
+
+	// Word starts are better than characters in the middle of words
+	wordStart, _ := FuzzyMatch("gd", "Git diff")
+	middle, _ := FuzzyMatch("gd", "bigdata")
+	assert.Greater(t, wordStart, middle)
+
+	// Camel case words start at the upper case letter
+	camelCase, _ := FuzzyMatch("gs", "gitStatus")
+	assert.Greater(t, camelCase, middle)
+}
+
+func Test_FuzzyFilter(t *testing.T) {
+	texts := []string{"Git log", "Refresh", "Git diff", "gitdiscover", "Add repository"}
+	assert.Equal(t, []int{3, 2}, FuzzyFilter("gitd", texts))
+	assert.Equal(t, []int{0, 2}, FuzzyFilter("git ", texts[:3]))
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, FuzzyFilter("", texts))
+	assert.Empty(t, FuzzyFilter("xyz", texts))
+}
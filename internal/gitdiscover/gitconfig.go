@@ -0,0 +1,225 @@
+package gitdiscover
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// GitConfigEntry is a variable in a git config file, like url in [remote "origin"].
+type GitConfigEntry struct {
+	// Section and Key are lower case, since they are case insensitive
+	Section string
+	// Subsection is case sensitive, like "origin" in [remote "origin"]
+	Subsection string
+	Key        string
+	Value      string
+}
+
+// GitConfig is a parsed git config file, like .git/config.
+type GitConfig struct {
+	// Entries are in the order they are in the file, since
+	// some variables (like remote.<name>.url) can have many values
+	Entries []GitConfigEntry
+}
+
+// ReadGitConfig reads and parses the git config file at configPath.
+func ReadGitConfig(configPath string) (*GitConfig, error) {
+	file, err := os.Open(configPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return ParseGitConfig(file)
+}
+
+// ParseGitConfig parses a git config file. Include directives are not followed.
+func ParseGitConfig(r io.Reader) (*GitConfig, error) {
+	config := &GitConfig{}
+	scanner := bufio.NewScanner(r)
+	var section, subsection string
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Values can continue on the next line, if the line ends with a backslash
+		for strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`) && scanner.Scan() {
+			lineNumber++
+			line = line[:len(line)-1] + scanner.Text()
+		}
+
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			var rest string
+			var err error
+			section, subsection, rest, err = parseGitConfigSection(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d : %w", lineNumber, err)
+			}
+			// A variable can follow the section header on the same line
+			line = strings.TrimSpace(rest)
+			if line == "" || line[0] == '#' || line[0] == ';' {
+				continue
+			}
+		}
+
+		if section == "" {
+			return nil, fmt.Errorf("line %d : variable outside of a section", lineNumber)
+		}
+		key, value, err := parseGitConfigVariable(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d : %w", lineNumber, err)
+		}
+		config.Entries = append(config.Entries, GitConfigEntry{
+			Section: section, Subsection: subsection, Key: key, Value: value,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Parses a section header, like [core], [remote "origin"] or the
+// deprecated [remote.origin], and returns the text after it
+func parseGitConfigSection(line string) (section, subsection, rest string, err error) {
+	end := strings.IndexByte(line, ']')
+	quote := strings.IndexByte(line, '"')
+	if quote >= 0 && quote < end {
+		// The subsection is quoted, and can contain ] and escaped quotes
+		section = strings.TrimSpace(line[1:quote])
+		var sb strings.Builder
+		i := quote + 1
+		for ; i < len(line) && line[i] != '"'; i++ {
+			if line[i] == '\\' && i+1 < len(line) {
+				i++
+			}
+			sb.WriteByte(line[i])
+		}
+		end = strings.IndexByte(line[i:], ']')
+		if i == len(line) || end < 0 {
+			return "", "", "", fmt.Errorf("invalid section header : %s", line)
+		}
+		return strings.ToLower(section), sb.String(), line[i+end+1:], nil
+	}
+
+	if end < 0 {
+		return "", "", "", fmt.Errorf("invalid section header : %s", line)
+	}
+	section = strings.TrimSpace(line[1:end])
+	if i := strings.IndexByte(section, '.'); i >= 0 {
+		// Subsections in the deprecated syntax are lower case
+		section, subsection = section[:i], strings.ToLower(section[i+1:])
+	}
+	if section == "" {
+		return "", "", "", fmt.Errorf("invalid section header : %s", line)
+	}
+	return strings.ToLower(section), subsection, line[end+1:], nil
+}
+
+// Parses a variable, like "url = https://github.com/hultan/gitdiscover.git"
+func parseGitConfigVariable(line string) (key, value string, err error) {
+	i := strings.IndexByte(line, '=')
+	if i < 0 {
+		// A variable without a value is a boolean true
+		return strings.ToLower(strings.TrimSpace(stripGitConfigComment(line))), "true", nil
+	}
+	key = strings.ToLower(strings.TrimSpace(line[:i]))
+	if key == "" {
+		return "", "", fmt.Errorf("invalid variable : %s", line)
+	}
+	value, err = parseGitConfigValue(line[i+1:])
+	return key, value, err
+}
+
+// Parses a value, with quotes, escape sequences and comments
+func parseGitConfigValue(text string) (string, error) {
+	var sb strings.Builder
+	inQuotes := false
+	// Whitespace is only kept between other characters, or in quotes
+	pendingSpace := ""
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '"':
+			sb.WriteString(pendingSpace)
+			pendingSpace = ""
+			inQuotes = !inQuotes
+		case c == '\\':
+			if i+1 == len(text) {
+				return "", fmt.Errorf("invalid escape sequence : %s", text)
+			}
+			i++
+			escaped, ok := map[byte]string{'n': "\n", 't': "\t", 'b': "\b", '"': `"`, '\\': `\`}[text[i]]
+			if !ok {
+				return "", fmt.Errorf("invalid escape sequence : %s", text)
+			}
+			sb.WriteString(pendingSpace)
+			pendingSpace = ""
+			sb.WriteString(escaped)
+		case !inQuotes && (c == '#' || c == ';'):
+			return sb.String(), nil
+		case !inQuotes && (c == ' ' || c == '\t'):
+			if sb.Len() > 0 {
+				pendingSpace += string(c)
+			}
+		default:
+			sb.WriteString(pendingSpace)
+			pendingSpace = ""
+			sb.WriteByte(c)
+		}
+	}
+	if inQuotes {
+		return "", fmt.Errorf("missing end quote : %s", text)
+	}
+	return sb.String(), nil
+}
+
+func stripGitConfigComment(text string) string {
+	if i := strings.IndexAny(text, "#;"); i >= 0 {
+		return text[:i]
+	}
+	return text
+}
+
+// GetAll returns all values of a variable, like GetAll("remote", "origin", "url").
+func (c *GitConfig) GetAll(section, subsection, key string) []string {
+	var values []string
+	for _, entry := range c.Entries {
+		if entry.Section == strings.ToLower(section) && entry.Subsection == subsection &&
+			entry.Key == strings.ToLower(key) {
+			values = append(values, entry.Value)
+		}
+	}
+	return values
+}
+
+// Get returns the last value of a variable, or an empty string if it is not set.
+func (c *GitConfig) Get(section, subsection, key string) string {
+	values := c.GetAll(section, subsection, key)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+// Subsections returns the subsections of a section, like the remote names
+// for "remote", in the order they first appear in the file.
+func (c *GitConfig) Subsections(section string) []string {
+	var subsections []string
+	found := make(map[string]bool)
+	for _, entry := range c.Entries {
+		if entry.Section == strings.ToLower(section) && entry.Subsection != "" && !found[entry.Subsection] {
+			found[entry.Subsection] = true
+			subsections = append(subsections, entry.Subsection)
+		}
+	}
+	return subsections
+}
@@ -0,0 +1,53 @@
+package gitdiscover
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testGitConfig = `[core]
+	repositoryformatversion = 0
+	bare = false ; a comment
+	IgnoreCase
+[remote "origin"]
+	url = git@github.com:hultan/gitdiscover.git
+	fetch = +refs/heads/*:refs/remotes/origin/*
+[remote "My Fork"] url = "https://example.com/my fork.git"   # a comment
+	pushurl = one
+	PushURL = two
+[branch.Main]
+	remote = origin
+	description = "first line\nsecond \"line\"" \
+continued
+`
+
+func Test_ParseGitConfig(t *testing.T) {
+	config, err := ParseGitConfig(strings.NewReader(testGitConfig))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "false", config.Get("core", "", "bare"))
+	assert.Equal(t, "true", config.Get("CORE", "", "ignorecase"))
+	assert.Equal(t, "git@github.com:hultan/gitdiscover.git", config.Get("remote", "origin", "URL"))
+	assert.Equal(t, "https://example.com/my fork.git", config.Get("remote", "My Fork", "url"))
+	assert.Equal(t, []string{"one", "two"}, config.GetAll("remote", "My Fork", "pushurl"))
+	assert.Equal(t, "", config.Get("remote", "my fork", "url"))
+	assert.Equal(t, []string{"origin", "My Fork"}, config.Subsections("remote"))
+
+	// The deprecated [section.subsection] syntax is lower case
+	assert.Equal(t, "origin", config.Get("branch", "main", "remote"))
+	assert.Equal(t, "first line\nsecond \"line\" continued", config.Get("branch", "main", "description"))
+}
+
+func Test_ParseGitConfig_Errors(t *testing.T) {
+	for _, text := range []string{
+		"url = outside",
+		"[remote \"origin\"\n",
+		"[remote \"origin\"]\nurl = \"missing end quote",
+		"[core]\nname = bad \\q escape",
+	} {
+		_, err := ParseGitConfig(strings.NewReader(text))
+		assert.NotNil(t, err, text)
+	}
+}
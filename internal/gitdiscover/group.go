@@ -0,0 +1,110 @@
+package gitdiscover
+
+import (
+	"sort"
+	"strings"
+)
+
+// The keys of the built in sections in the repository list
+const (
+	SectionFavorites = "favorites"
+	SectionGit       = "git"
+	SectionNonGit    = "non-git"
+
+	// groupSectionPrefix is the start of the keys of user defined groups, like "group:work"
+	groupSectionPrefix = "group:"
+)
+
+// RepositorySection is a section in the repository list, either one
+// of the built in sections or a user defined group.
+type RepositorySection struct {
+	// Key identifies the section, like "favorites" or "group:work"
+	Key   string
+	Title string
+	// Group is the name of the user defined group, empty for the built in sections
+	Group        string
+	Repositories Repositories
+}
+
+// GroupRepositories splits the repositories into sections: favorites first
+// (if favoritesFirst is true, otherwise the favorites are in the other sections),
+// then the user defined groups (sorted by name), then the ungrouped git
+// repositories and last the ungrouped non-git folders. The repositories keep
+// their order within each section, and empty sections are left out.
+func GroupRepositories(repos Repositories, favoritesFirst bool) []*RepositorySection {
+	favorites := &RepositorySection{Key: SectionFavorites, Title: "FAVORITES"}
+	git := &RepositorySection{Key: SectionGit, Title: "GIT REPOSITORIES"}
+	nonGit := &RepositorySection{Key: SectionNonGit, Title: "NON-GIT FOLDERS"}
+	groups := make(map[string]*RepositorySection)
+
+	for _, repo := range repos {
+		var section *RepositorySection
+		switch {
+		case favoritesFirst && repo.IsFavorite():
+			section = favorites
+		case repo.Group() != "":
+			section = groups[repo.Group()]
+			if section == nil {
+				section = &RepositorySection{Key: GroupSectionKey(repo.Group()),
+					Title: strings.ToUpper(repo.Group()), Group: repo.Group()}
+				groups[repo.Group()] = section
+			}
+		case repo.IsGit():
+			section = git
+		default:
+			section = nonGit
+		}
+		section.Repositories = append(section.Repositories, repo)
+	}
+
+	var sections []*RepositorySection
+	if len(favorites.Repositories) > 0 {
+		sections = append(sections, favorites)
+	}
+	for _, group := range repos.Groups() {
+		sections = append(sections, groups[group])
+	}
+	for _, section := range []*RepositorySection{git, nonGit} {
+		if len(section.Repositories) > 0 {
+			sections = append(sections, section)
+		}
+	}
+	return sections
+}
+
+// GroupSectionKey returns the section key of a user defined group.
+func GroupSectionKey(group string) string {
+	return groupSectionPrefix + group
+}
+
+// Groups returns the names of the user defined groups, sorted by name.
+func (r Repositories) Groups() []string {
+	var groups []string
+	found := make(map[string]bool)
+	for _, repo := range r {
+		if repo.Group() != "" && !found[repo.Group()] {
+			found[repo.Group()] = true
+			groups = append(groups, repo.Group())
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		return strings.ToLower(groups[i]) < strings.ToLower(groups[j])
+	})
+	return groups
+}
+
+// MoveToSection moves a repository to the section with the given key, by
+// changing whether it is a favorite and its group. Moving a repository to
+// the git or non-git section removes it from its group.
+func (t *Repository) MoveToSection(key string) {
+	switch {
+	case key == SectionFavorites:
+		t.SetIsFavorite(true)
+	case strings.HasPrefix(key, groupSectionPrefix):
+		t.SetIsFavorite(false)
+		t.SetGroup(strings.TrimPrefix(key, groupSectionPrefix))
+	default:
+		t.SetIsFavorite(false)
+		t.SetGroup("")
+	}
+}
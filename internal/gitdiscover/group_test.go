@@ -0,0 +1,59 @@
+package gitdiscover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GroupRepositories(t *testing.T) {
+	repos := Repositories{
+		&Repository{name: "a", isGit: true},
+		&Repository{name: "b", isGit: true, group: "work"},
+		&Repository{name: "c", isFavorite: true, group: "work"},
+		&Repository{name: "d"},
+		&Repository{name: "e", group: "Libraries"},
+		&Repository{name: "f", isGit: true, group: "work"},
+	}
+
+	sections := GroupRepositories(repos, true)
+	var keys []string
+	for _, section := range sections {
+		keys = append(keys, section.Key)
+	}
+	assert.Equal(t, []string{SectionFavorites, "group:Libraries", "group:work", SectionGit, SectionNonGit}, keys)
+	assert.Equal(t, "WORK", sections[2].Title)
+	assert.Equal(t, "work", sections[2].Group)
+	assert.Equal(t, Repositories{repos[1], repos[5]}, sections[2].Repositories)
+	assert.Equal(t, []string{"Libraries", "work"}, repos.Groups())
+
+	// Empty sections are left out
+	sections = GroupRepositories(Repositories{repos[0]}, true)
+	assert.Equal(t, 1, len(sections))
+	assert.Equal(t, SectionGit, sections[0].Key)
+
+	// Favorites are in their groups, when they are not sorted first
+	sections = GroupRepositories(repos, false)
+	assert.Equal(t, "group:Libraries", sections[0].Key)
+	assert.Equal(t, Repositories{repos[1], repos[2], repos[5]}, sections[1].Repositories)
+}
+
+func Test_MoveToSection(t *testing.T) {
+	repo := &Repository{name: "a", isGit: true, isFavorite: true, scanRoot: "/code"}
+
+	repo.MoveToSection(GroupSectionKey(" experiments "))
+	assert.False(t, repo.IsFavorite())
+	assert.Equal(t, "experiments", repo.Group())
+	// Scanned repositories in a group are saved, to remember the group
+	assert.True(t, repo.shouldSave())
+	assert.Equal(t, "experiments", repo.ToConfig().Group)
+
+	repo.MoveToSection(SectionFavorites)
+	assert.True(t, repo.IsFavorite())
+	assert.Equal(t, "experiments", repo.Group())
+
+	repo.MoveToSection(SectionGit)
+	assert.False(t, repo.IsFavorite())
+	assert.Equal(t, "", repo.Group())
+	assert.False(t, repo.shouldSave())
+}
@@ -0,0 +1,130 @@
+package gitdiscover
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// historyTimeout is the maximum time that loading a page of history, or a commit, may take
+const historyTimeout = 30 * time.Second
+
+// maxCommitDiffLength is the maximum length (in bytes) of the diff of a commit,
+// longer diffs are truncated, since they make the details pane very slow
+const maxCommitDiffLength = 512 * 1024
+
+// The fields of a commit are separated by the unit separator (\x1f), and the
+// commits by the record separator (\x1e), since they can not be in the text
+const historyFormat = "--format=%H%x1f%h%x1f%an%x1f%ae%x1f%aI%x1f%D%x1f%s%x1e"
+
+// Commit is a commit in the history of a repository.
+type Commit struct {
+	Hash        string
+	ShortHash   string
+	Author      string
+	AuthorEmail string
+	Date        time.Time
+	Subject     string
+	// Refs are the branches and tags that point to the commit, like "HEAD -> main"
+	Refs []string
+}
+
+// HistoryQuery selects a page of commits, and optionally filters them.
+type HistoryQuery struct {
+	Skip  int
+	Limit int
+	// Author only includes commits with a matching author (name or email)
+	Author string
+	// Message only includes commits with a matching message (case insensitive)
+	Message string
+	// Path only includes commits that change the file or folder
+	Path string
+}
+
+// GetHistory returns a page of the history of the current branch in the
+// repository at repoPath, newest commits first.
+func GetHistory(ctx context.Context, repoPath string, query HistoryQuery) ([]*Commit, error) {
+	args := []string{"log", historyFormat, fmt.Sprintf("--skip=%d", query.Skip)}
+	if query.Limit > 0 {
+		args = append(args, fmt.Sprintf("--max-count=%d", query.Limit))
+	}
+	if query.Author != "" {
+		args = append(args, "--author="+query.Author)
+	}
+	if query.Message != "" {
+		args = append(args, "--regexp-ignore-case", "--fixed-strings", "--grep="+query.Message)
+	}
+	args = append(args, "--")
+	if query.Path != "" {
+		args = append(args, query.Path)
+	}
+
+	result, err := newRunner(repoPath, historyTimeout).Run(ctx, args...)
+	if err != nil {
+		return nil, getGitError(err)
+	}
+
+	return parseHistory(result.Stdout)
+}
+
+// Parse the output of git log, using historyFormat
+func parseHistory(text string) ([]*Commit, error) {
+	var commits []*Commit
+	for _, record := range strings.Split(text, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if record == "" {
+			continue
+		}
+		fields := strings.Split(record, "\x1f")
+		if len(fields) != 7 {
+			return nil, fmt.Errorf("invalid git log record : %q", record)
+		}
+		date, err := time.Parse(time.RFC3339, fields[4])
+		if err != nil {
+			return nil, err
+		}
+		commit := &Commit{
+			Hash:        fields[0],
+			ShortHash:   fields[1],
+			Author:      fields[2],
+			AuthorEmail: fields[3],
+			Date:        date,
+			Subject:     fields[6],
+		}
+		if fields[5] != "" {
+			commit.Refs = strings.Split(fields[5], ", ")
+		}
+		commits = append(commits, commit)
+	}
+	return commits, nil
+}
+
+// GetCommitDetails returns the full message, and the diff, of a commit.
+// Diffs that are longer than maxCommitDiffLength are truncated.
+func GetCommitDetails(ctx context.Context, repoPath, hash string) (message, diff string, err error) {
+	runner := newRunner(repoPath, historyTimeout)
+	message, err = runner.Output(ctx, "show", "--no-patch", "--format=%B", hash, "--")
+	if err != nil {
+		return "", "", getGitError(err)
+	}
+	result, err := runner.Run(ctx, "show", "--format=", "--patch", "--no-color", hash, "--")
+	if err != nil {
+		return "", "", getGitError(err)
+	}
+	return message, truncateDiff(strings.TrimLeft(result.Stdout, "\n"), maxCommitDiffLength), nil
+}
+
+// truncateDiff cuts the diff after the last whole line that fits in maxLength,
+// and adds a line that says that the diff is truncated
+func truncateDiff(diff string, maxLength int) string {
+	if len(diff) <= maxLength {
+		return diff
+	}
+	truncated := diff[:maxLength]
+	if i := strings.LastIndex(truncated, "\n"); i >= 0 {
+		truncated = truncated[:i+1]
+	}
+	return truncated + fmt.Sprintf("\n... the diff is truncated, it is %d KB, use git show to see all of it\n",
+		len(diff)/1024)
+}
@@ -0,0 +1,98 @@
+package gitdiscover
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestHistory(t *testing.T) string {
+	dir := createTestRepository(t)
+	writeTestFile(t, dir, "docs.txt", "docs")
+	runTestGit(t, dir, "add", "docs.txt")
+	runTestGit(t, dir, "-c", "user.name=Other", "-c", "user.email=other@example.com",
+		"commit", "-q", "-m", "Add docs\n\nA longer description.")
+	writeTestFile(t, dir, "committed.txt", "changed")
+	runTestGit(t, dir, "commit", "-q", "-a", "-m", "Fix BUG in committed.txt")
+	runTestGit(t, dir, "tag", "v1.0.0")
+	return dir
+}
+
+func getSubjects(commits []*Commit) []string {
+	var subjects []string
+	for _, commit := range commits {
+		subjects = append(subjects, commit.Subject)
+	}
+	return subjects
+}
+
+func Test_GetHistory(t *testing.T) {
+	dir := createTestHistory(t)
+	ctx := context.Background()
+
+	commits, err := GetHistory(ctx, dir, HistoryQuery{})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Fix BUG in committed.txt", "Add docs", "Initial commit"}, getSubjects(commits))
+	assert.Equal(t, []string{"HEAD -> main", "tag: v1.0.0"}, commits[0].Refs)
+	assert.Nil(t, commits[1].Refs)
+	assert.Equal(t, "Other", commits[1].Author)
+	assert.Equal(t, "other@example.com", commits[1].AuthorEmail)
+	assert.Equal(t, 40, len(commits[0].Hash))
+	assert.True(t, len(commits[0].ShortHash) < 40)
+	assert.WithinDuration(t, time.Now(), commits[0].Date, time.Minute)
+
+	// Pages
+	commits, err = GetHistory(ctx, dir, HistoryQuery{Skip: 1, Limit: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Add docs"}, getSubjects(commits))
+
+	// Search
+	commits, err = GetHistory(ctx, dir, HistoryQuery{Author: "other"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Add docs"}, getSubjects(commits))
+	commits, err = GetHistory(ctx, dir, HistoryQuery{Message: "bug"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Fix BUG in committed.txt"}, getSubjects(commits))
+	commits, err = GetHistory(ctx, dir, HistoryQuery{Path: "committed.txt"})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"Fix BUG in committed.txt", "Initial commit"}, getSubjects(commits))
+	commits, err = GetHistory(ctx, dir, HistoryQuery{Message: "no such commit"})
+	assert.Nil(t, err)
+	assert.Empty(t, commits)
+}
+
+func Test_GetHistory_NotGit(t *testing.T) {
+	_, err := GetHistory(context.Background(), t.TempDir(), HistoryQuery{})
+	assert.NotNil(t, err)
+}
+
+func Test_GetCommitDetails(t *testing.T) {
+	dir := createTestHistory(t)
+	commits, err := GetHistory(context.Background(), dir, HistoryQuery{})
+	assert.Nil(t, err)
+
+	message, diff, err := GetCommitDetails(context.Background(), dir, commits[1].Hash)
+	assert.Nil(t, err)
+	assert.Equal(t, "Add docs\n\nA longer description.", message)
+	assert.Contains(t, diff, "diff --git a/docs.txt b/docs.txt")
+	assert.Contains(t, diff, "+docs")
+}
+
+func Test_truncateDiff(t *testing.T) {
+	diff := "+first line\n+second line\n"
+	assert.Equal(t, diff, truncateDiff(diff, len(diff)))
+	assert.Equal(t, "+first line\n\n... the diff is truncated, it is 0 KB, use git show to see all of it\n",
+		truncateDiff(diff, 15))
+}
+
+func Test_parseHistory(t *testing.T) {
+	commits, err := parseHistory("abc\x1fa\x1fPer\x1fper@example.com\x1f2021-09-01T10:00:00+02:00\x1f\x1fSubject\x1e\n")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(commits))
+	assert.Equal(t, "Subject", commits[0].Subject)
+
+	_, err = parseHistory("abc\x1fa\x1e")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,157 @@
+package gitdiscover
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// RepositoryKind is the kind of git repository that a folder contains.
+type RepositoryKind int
+
+const (
+	// RepositoryKindNone is used for folders that are not git repositories
+	RepositoryKindNone RepositoryKind = iota
+	// RepositoryKindNormal is a repository with a .git folder
+	RepositoryKindNormal
+	// RepositoryKindWorktree is a linked worktree, created by git worktree add
+	RepositoryKindWorktree
+	// RepositoryKindSubmodule is a submodule, with its .git folder in the parent repository
+	RepositoryKindSubmodule
+	// RepositoryKindBare is a bare repository, without a working tree
+	RepositoryKindBare
+)
+
+// String returns the name of the repository kind, like "worktree".
+func (k RepositoryKind) String() string {
+	switch k {
+	case RepositoryKindNormal:
+		return "repository"
+	case RepositoryKindWorktree:
+		return "worktree"
+	case RepositoryKindSubmodule:
+		return "submodule"
+	case RepositoryKindBare:
+		return "bare repository"
+	default:
+		return "folder"
+	}
+}
+
+// gitLayout describes where the git files of a repository are
+type gitLayout struct {
+	kind RepositoryKind
+	// gitDir contains HEAD and index, like .git or .git/worktrees/<name>
+	gitDir string
+	// commonDir contains the files that are shared between worktrees, like config and refs
+	commonDir string
+	// parentPath is the main worktree of a linked worktree, or the parent repository of a submodule
+	parentPath string
+}
+
+// Find the git files of the repository at repoPath. The .git entry can be
+// a folder, or a file containing "gitdir: <path>" for worktrees and submodules.
+// A folder without a .git entry can be a bare repository.
+func getGitLayout(repoPath string) gitLayout {
+	dotGit := filepath.Join(repoPath, ".git")
+	info, err := os.Stat(dotGit)
+	switch {
+	case err == nil && info.IsDir():
+		return gitLayout{kind: RepositoryKindNormal, gitDir: dotGit, commonDir: dotGit}
+	case err == nil:
+		return getLinkedGitLayout(repoPath, dotGit)
+	case isBareRepository(repoPath):
+		return gitLayout{kind: RepositoryKindBare, gitDir: repoPath, commonDir: repoPath}
+	default:
+		return gitLayout{}
+	}
+}
+
+// Get the layout of a worktree or submodule, from its .git file
+func getLinkedGitLayout(repoPath, dotGit string) gitLayout {
+	buf, err := ioutil.ReadFile(dotGit)
+	if err != nil {
+		return gitLayout{}
+	}
+	text := strings.TrimSpace(string(buf))
+	if !strings.HasPrefix(text, "gitdir:") {
+		return gitLayout{}
+	}
+	gitDir := resolvePath(repoPath, strings.TrimSpace(strings.TrimPrefix(text, "gitdir:")))
+
+	// Linked worktrees have a commondir file, pointing to the main .git folder
+	buf, err = ioutil.ReadFile(filepath.Join(gitDir, "commondir"))
+	if err == nil {
+		commonDir := resolvePath(gitDir, strings.TrimSpace(string(buf)))
+		parentPath := commonDir
+		if filepath.Base(commonDir) == ".git" {
+			parentPath = filepath.Dir(commonDir)
+		}
+		return gitLayout{kind: RepositoryKindWorktree, gitDir: gitDir, commonDir: commonDir, parentPath: parentPath}
+	}
+
+	// A .git file pointing somewhere else than .git/modules, like git init --separate-git-dir does
+	if !strings.Contains(filepath.ToSlash(gitDir), "/modules/") {
+		return gitLayout{kind: RepositoryKindNormal, gitDir: gitDir, commonDir: gitDir}
+	}
+
+	// Submodules are in .git/modules in the parent repository, which
+	// is the closest parent folder that contains a .git entry
+	layout := gitLayout{kind: RepositoryKindSubmodule, gitDir: gitDir, commonDir: gitDir}
+	for dir := filepath.Dir(repoPath); dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			layout.parentPath = dir
+			break
+		}
+	}
+	return layout
+}
+
+// A bare repository has HEAD, objects and refs, but no .git folder
+func isBareRepository(path string) bool {
+	for _, name := range []string{"HEAD", "objects", "refs"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+			return false
+		}
+	}
+	config, err := ReadGitConfig(filepath.Join(path, "config"))
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(config.Get("core", "", "bare")) {
+	case "true", "yes", "on", "1":
+		return true
+	default:
+		return false
+	}
+}
+
+func resolvePath(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// Get the paths of the linked worktrees of a repository, from the
+// gitdir files in <commonDir>/worktrees/<name>, which point to the
+// .git file of each worktree
+func getWorktrees(commonDir string) []string {
+	entries, err := ioutil.ReadDir(filepath.Join(commonDir, "worktrees"))
+	if err != nil {
+		return nil
+	}
+	var worktrees []string
+	for _, entry := range entries {
+		buf, err := ioutil.ReadFile(filepath.Join(commonDir, "worktrees", entry.Name(), "gitdir"))
+		if err != nil {
+			continue
+		}
+		gitFile := resolvePath(filepath.Join(commonDir, "worktrees", entry.Name()), strings.TrimSpace(string(buf)))
+		worktrees = append(worktrees, filepath.Dir(gitFile))
+	}
+	sort.Strings(worktrees)
+	return worktrees
+}
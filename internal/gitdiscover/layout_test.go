@@ -0,0 +1,62 @@
+package gitdiscover
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+func Test_GitLayout(t *testing.T) {
+	main := createTestRepository(t)
+	runTestGit(t, main, "remote", "add", "origin", "https://github.com/hultan/gitdiscover.git")
+	runTestGit(t, main, "stash", "-q", "--include-untracked")
+	ctx := context.Background()
+
+	// Linked worktree
+	worktree := filepath.Join(t.TempDir(), "feature")
+	runTestGit(t, main, "worktree", "add", "-q", "-b", "feature", worktree)
+	writeTestFile(t, worktree, "new.txt", "new")
+
+	repo := LoadRepository(ctx, &config.Repository{Path: worktree})
+	assert.True(t, repo.IsGit())
+	assert.Equal(t, RepositoryKindWorktree, repo.Kind())
+	assert.Equal(t, main, repo.ParentPath())
+	assert.Equal(t, []string{worktree}, repo.Worktrees())
+	assert.Equal(t, "feature", repo.Branch())
+	assert.Equal(t, 1, repo.ChangeSummary().Untracked)
+	// The config and the stashes are shared with the main worktree
+	assert.True(t, repo.HasRemote())
+	assert.Equal(t, 1, repo.Stashes())
+
+	repo = LoadRepository(ctx, &config.Repository{Path: main})
+	assert.Equal(t, RepositoryKindNormal, repo.Kind())
+	assert.Equal(t, "", repo.ParentPath())
+	assert.Equal(t, []string{worktree}, repo.Worktrees())
+
+	// Submodule
+	runTestGit(t, main, "-c", "protocol.file.allow=always", "submodule", "add", "-q", worktree, "sub")
+	submodule := filepath.Join(main, "sub")
+	repo = LoadRepository(ctx, &config.Repository{Path: submodule})
+	assert.Equal(t, RepositoryKindSubmodule, repo.Kind())
+	assert.Equal(t, main, repo.ParentPath())
+	assert.Equal(t, "feature", repo.Branch())
+	assert.Equal(t, 0, repo.Changes())
+
+	// Bare repository
+	bare := filepath.Join(t.TempDir(), "bare.git")
+	runTestGit(t, main, "clone", "-q", "--bare", main, bare)
+	repo = LoadRepository(ctx, &config.Repository{Path: bare})
+	assert.True(t, repo.IsGit())
+	assert.Equal(t, RepositoryKindBare, repo.Kind())
+	assert.Equal(t, "main", repo.Branch())
+	assert.Equal(t, "main", repo.GitStatus())
+
+	// Not a repository
+	repo = LoadRepository(ctx, &config.Repository{Path: t.TempDir()})
+	assert.False(t, repo.IsGit())
+	assert.Equal(t, RepositoryKindNone, repo.Kind())
+}
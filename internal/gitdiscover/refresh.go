@@ -0,0 +1,82 @@
+package gitdiscover
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+// refreshTimeout is the maximum time that refreshing a single repository may take
+const refreshTimeout = 10 * time.Second
+
+// RefreshProgress is called every time a repository has been refreshed.
+// index is the index of the repository in the config, and the calls
+// are made from the worker goroutines, in no particular order.
+type RefreshProgress func(index int, repo *Repository)
+
+// LoadRepositories creates and refreshes a Repository for each of the
+// config repositories, using a bounded pool of workers. The returned
+// repositories are in the same order as configRepos, no matter in which
+// order the workers finish. If ctx is cancelled, LoadRepositories stops
+// as soon as possible and returns the context error.
+func LoadRepositories(ctx context.Context, configRepos []*config.Repository,
+	progress RefreshProgress) (Repositories, error) {
+
+	repositories := make(Repositories, len(configRepos))
+
+	workers := runtime.NumCPU()
+	if workers > len(configRepos) {
+		workers = len(configRepos)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				repo := LoadRepository(ctx, configRepos[i])
+				repositories[i] = repo
+				if progress != nil && ctx.Err() == nil {
+					progress(i, repo)
+				}
+			}
+		}()
+	}
+
+	// Hand out the jobs, and stop handing out when cancelled
+feed:
+	for i := range configRepos {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	return repositories, nil
+}
+
+// LoadRepository creates and refreshes a single repository, giving up
+// on the slow parts (like git status) after refreshTimeout.
+func LoadRepository(ctx context.Context, configRepo *config.Repository) *Repository {
+	ctx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+
+	folder := newFolder(ctx, configRepo.Path)
+	folder.setImagePath(configRepo.ImagePath)
+	folder.SetIsFavorite(configRepo.IsFavorite)
+	folder.SetGroup(configRepo.Group)
+	folder.scanRoot = configRepo.ScanRoot
+	return folder
+}
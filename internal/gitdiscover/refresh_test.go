@@ -0,0 +1,124 @@
+package gitdiscover
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+// createTestRepository creates a new git repository, with one
+// committed file and one untracked file.
+func createTestRepository(t *testing.T) string {
+	dir := t.TempDir()
+	runTestGit(t, dir, "init", "-q", "-b", "main")
+	writeTestFile(t, dir, "committed.txt", "committed")
+	runTestGit(t, dir, "add", "committed.txt")
+	runTestGit(t, dir, "commit", "-q", "-m", "Initial commit")
+	writeTestFile(t, dir, "untracked.txt", "untracked")
+	return dir
+}
+
+func runTestGit(t *testing.T, dir string, args ...string) string {
+	args = append([]string{"-c", "user.name=Test", "-c", "user.email=test@example.com"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v failed : %s", args, out)
+	}
+	return string(out)
+}
+
+func writeTestFile(t *testing.T, dir, name, text string) {
+	err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func Test_LoadRepositories(t *testing.T) {
+	var configRepos []*config.Repository
+	for i := 0; i < 5; i++ {
+		configRepos = append(configRepos, &config.Repository{Path: t.TempDir()})
+	}
+	gitRepo := createTestRepository(t)
+	configRepos = append(configRepos, &config.Repository{Path: gitRepo, IsFavorite: true})
+
+	var mutex sync.Mutex
+	var indexes []int
+	repos, err := LoadRepositories(context.Background(), configRepos, func(index int, repo *Repository) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		indexes = append(indexes, index)
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, len(configRepos), len(repos))
+	assert.Equal(t, len(configRepos), len(indexes))
+
+	// The order should always be the config order
+	for i, repo := range repos {
+		assert.Equal(t, configRepos[i].Path, repo.Path())
+	}
+
+	repo := repos[len(repos)-1]
+	assert.True(t, repo.IsGit())
+	assert.True(t, repo.IsFavorite())
+	assert.Equal(t, "main|+1", repo.GitStatus())
+	assert.Equal(t, 1, repo.Changes())
+}
+
+func Test_LoadRepositories_Cancelled(t *testing.T) {
+	configRepos := []*config.Repository{{Path: t.TempDir()}, {Path: t.TempDir()}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	repos, err := LoadRepositories(ctx, configRepos, nil)
+	assert.Equal(t, context.Canceled, err)
+	assert.Nil(t, repos)
+}
+
+func Test_parseGitStatus(t *testing.T) {
+	status := "# branch.oid 1234\x00# branch.head main\x00# branch.upstream origin/main\x00" +
+		"# branch.ab +2 -1\x00" +
+		"1 .M N... 100644 100644 100644 1234 1234 modified.go\x00" +
+		"1 M. N... 100644 100644 100644 1234 1234 staged.go\x00" +
+		"1 .D N... 100644 100644 000000 1234 1234 deleted.go\x00" +
+		"2 R. N... 100644 100644 100644 1234 1234 R100 new.go\x00old.go\x00" +
+		"u UU N... 100644 100644 100644 100644 1234 1234 1234 conflict.go\x00" +
+		"? untracked.go\x00"
+
+	info, err := parseGitStatus(status)
+	assert.Nil(t, err)
+	assert.Equal(t, "main", info.branch)
+	assert.Equal(t, "origin/main", info.upstream)
+	assert.False(t, info.isDetached)
+	assert.Equal(t, 2, info.ahead)
+	assert.Equal(t, 1, info.behind)
+	assert.Equal(t, ChangeSummary{
+		Staged:    2,
+		Unstaged:  2,
+		Untracked: 1,
+		Modified:  1,
+		Deleted:   1,
+		Renamed:   1,
+		Unmerged:  1,
+	}, info.changes)
+	assert.Equal(t, 4, info.changes.Total())
+	assert.Equal(t, "main↑2↓1|+1~1-1x1•2", info.prompt())
+}
+
+func Test_parseGitStatus_Detached(t *testing.T) {
+	info, err := parseGitStatus("# branch.oid 1234\x00# branch.head (detached)\x00")
+	assert.Nil(t, err)
+	assert.True(t, info.isDetached)
+	assert.Equal(t, "", info.branch)
+	assert.Equal(t, "", info.upstream)
+	assert.Equal(t, ":HEAD", info.prompt())
+}
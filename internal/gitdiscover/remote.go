@@ -0,0 +1,234 @@
+package gitdiscover
+
+import (
+	"net/url"
+	"path"
+	"strings"
+)
+
+// Provider is the hosting provider of a remote repository, like GitHub.
+type Provider int
+
+const (
+	// ProviderOther is used for self hosted servers, and for local paths
+	ProviderOther Provider = iota
+	ProviderGitHub
+	ProviderGitLab
+	ProviderBitbucket
+	ProviderAzureDevOps
+	ProviderCodeberg
+	ProviderSourceHut
+)
+
+// String returns the name of the provider, like "GitHub".
+func (p Provider) String() string {
+	switch p {
+	case ProviderGitHub:
+		return "GitHub"
+	case ProviderGitLab:
+		return "GitLab"
+	case ProviderBitbucket:
+		return "Bitbucket"
+	case ProviderAzureDevOps:
+		return "Azure DevOps"
+	case ProviderCodeberg:
+		return "Codeberg"
+	case ProviderSourceHut:
+		return "SourceHut"
+	default:
+		return "Other"
+	}
+}
+
+// Remote is a remote repository, from the [remote "name"] section in .git/config.
+type Remote struct {
+	Name      string
+	FetchURLs []string
+	// PushURLs are the pushurl values, or the fetch URLs if there are none
+	PushURLs []string
+}
+
+// URL returns the first fetch URL of the remote, or an empty string if it has none.
+func (r *Remote) URL() string {
+	if len(r.FetchURLs) == 0 {
+		return ""
+	}
+	return r.FetchURLs[0]
+}
+
+// Provider returns the hosting provider of the remote, from its fetch URL.
+func (r *Remote) Provider() Provider {
+	host, _, ok := parseRemoteURL(r.URL())
+	if !ok {
+		return ProviderOther
+	}
+	return getProvider(host)
+}
+
+// WebURL returns the web page of the remote repository, like
+// https://github.com/hultan/gitdiscover, or an empty string if
+// the remote is a local path.
+func (r *Remote) WebURL() string {
+	return getWebURL(r.URL())
+}
+
+// GetRemotes returns the remotes in the git config file at configPath.
+func GetRemotes(configPath string) ([]*Remote, error) {
+	config, err := ReadGitConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	return getRemotes(config), nil
+}
+
+// Get the remotes in a git config, with the url.<base>.insteadOf
+// and url.<base>.pushInsteadOf rewrites applied, like git does
+func getRemotes(config *GitConfig) []*Remote {
+	var remotes []*Remote
+	for _, name := range config.Subsections("remote") {
+		remote := &Remote{Name: name}
+		for _, u := range config.GetAll("remote", name, "url") {
+			remote.FetchURLs = append(remote.FetchURLs, rewriteURL(config, u, "insteadof"))
+		}
+		for _, u := range config.GetAll("remote", name, "pushurl") {
+			remote.PushURLs = append(remote.PushURLs, rewriteURL(config, u, "insteadof"))
+		}
+		if len(remote.PushURLs) == 0 {
+			for _, u := range config.GetAll("remote", name, "url") {
+				pushURL := rewriteURL(config, u, "pushinsteadof")
+				if pushURL == u {
+					pushURL = rewriteURL(config, u, "insteadof")
+				}
+				remote.PushURLs = append(remote.PushURLs, pushURL)
+			}
+		}
+		// Sections with only fetch refspecs, or other settings, are not remotes
+		if len(remote.FetchURLs) == 0 && len(remote.PushURLs) == 0 {
+			continue
+		}
+		remotes = append(remotes, remote)
+	}
+	return remotes
+}
+
+// Rewrite a URL using the longest matching url.<base>.<key> prefix
+func rewriteURL(config *GitConfig, u, key string) string {
+	var base, prefix string
+	for _, b := range config.Subsections("url") {
+		for _, p := range config.GetAll("url", b, key) {
+			if strings.HasPrefix(u, p) && len(p) > len(prefix) {
+				base, prefix = b, p
+			}
+		}
+	}
+	if prefix == "" {
+		return u
+	}
+	return base + strings.TrimPrefix(u, prefix)
+}
+
+// DefaultRemote returns the remote of the upstream branch (like origin
+// for origin/main), or origin, or the first remote. It returns nil if
+// there are no remotes.
+func DefaultRemote(remotes []*Remote, upstream string) *Remote {
+	var best *Remote
+	for _, remote := range remotes {
+		// Remote names can contain slashes, so use the longest match
+		if strings.HasPrefix(upstream, remote.Name+"/") && (best == nil || len(remote.Name) > len(best.Name)) {
+			best = remote
+		}
+	}
+	if best != nil {
+		return best
+	}
+	for _, remote := range remotes {
+		if remote.Name == "origin" {
+			return remote
+		}
+	}
+	if len(remotes) > 0 {
+		return remotes[0]
+	}
+	return nil
+}
+
+// Parse a remote URL, like https://github.com/hultan/gitdiscover.git,
+// ssh://git@github.com:22/hultan/gitdiscover.git or the scp-like
+// git@github.com:hultan/gitdiscover.git, and return the host (without
+// user and port) and the path. Local paths return false.
+func parseRemoteURL(remoteURL string) (host, urlPath string, ok bool) {
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil || u.Scheme == "file" || u.Hostname() == "" {
+			return "", "", false
+		}
+		return strings.ToLower(u.Hostname()), strings.Trim(u.Path, "/"), true
+	}
+
+	// The scp-like syntax is only used if there is no slash before the colon
+	colon := strings.IndexByte(remoteURL, ':')
+	if colon <= 0 || strings.Contains(remoteURL[:colon], "/") {
+		return "", "", false
+	}
+	host = remoteURL[:colon]
+	if at := strings.LastIndexByte(host, '@'); at >= 0 {
+		host = host[at+1:]
+	}
+	host = strings.Trim(host, "[]")
+	if host == "" {
+		return "", "", false
+	}
+	return strings.ToLower(host), strings.Trim(remoteURL[colon+1:], "/"), true
+}
+
+func getProvider(host string) Provider {
+	switch {
+	case host == "github.com" || strings.HasSuffix(host, ".github.com"):
+		return ProviderGitHub
+	case host == "gitlab.com" || strings.HasPrefix(host, "gitlab."):
+		return ProviderGitLab
+	case host == "bitbucket.org" || strings.HasPrefix(host, "bitbucket."):
+		return ProviderBitbucket
+	case host == "dev.azure.com" || host == "ssh.dev.azure.com" || strings.HasSuffix(host, ".visualstudio.com"):
+		return ProviderAzureDevOps
+	case host == "codeberg.org":
+		return ProviderCodeberg
+	case host == "git.sr.ht":
+		return ProviderSourceHut
+	default:
+		return ProviderOther
+	}
+}
+
+// Get the web page of a remote URL, or an empty string for local paths
+func getWebURL(remoteURL string) string {
+	host, urlPath, ok := parseRemoteURL(remoteURL)
+	if !ok || urlPath == "" {
+		return ""
+	}
+	urlPath = strings.TrimSuffix(urlPath, ".git")
+
+	switch getProvider(host) {
+	case ProviderGitHub:
+		// SSH over HTTPS uses ssh.github.com
+		host = "github.com"
+	case ProviderAzureDevOps:
+		// SSH URLs look like git@ssh.dev.azure.com:v3/organization/project/repository
+		if host == "ssh.dev.azure.com" {
+			parts := strings.Split(urlPath, "/")
+			if len(parts) != 4 || parts[0] != "v3" {
+				return ""
+			}
+			return "https://dev.azure.com/" + path.Join(parts[1], parts[2], "_git", parts[3])
+		}
+	}
+
+	// Use the port in http(s) URLs, since they are web servers
+	if strings.HasPrefix(remoteURL, "http://") || strings.HasPrefix(remoteURL, "https://") {
+		u, err := url.Parse(remoteURL)
+		if err == nil {
+			return u.Scheme + "://" + u.Host + "/" + urlPath
+		}
+	}
+	return "https://" + host + "/" + urlPath
+}
@@ -0,0 +1,64 @@
+package gitdiscover
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Remote_WebURL(t *testing.T) {
+	tests := []struct {
+		url      string
+		provider Provider
+		webURL   string
+	}{
+		{"https://github.com/hultan/gitdiscover.git", ProviderGitHub, "https://github.com/hultan/gitdiscover"},
+		{"git@github.com:hultan/gitdiscover.git", ProviderGitHub, "https://github.com/hultan/gitdiscover"},
+		{"ssh://git@ssh.github.com:443/hultan/gitdiscover.git", ProviderGitHub, "https://github.com/hultan/gitdiscover"},
+		{"https://gitlab.com/group/sub/project.git/", ProviderGitLab, "https://gitlab.com/group/sub/project"},
+		{"git@bitbucket.org:team/repo.git", ProviderBitbucket, "https://bitbucket.org/team/repo"},
+		{"git@ssh.dev.azure.com:v3/org/project/repo", ProviderAzureDevOps, "https://dev.azure.com/org/project/_git/repo"},
+		{"https://org@dev.azure.com/org/project/_git/repo", ProviderAzureDevOps, "https://dev.azure.com/org/project/_git/repo"},
+		{"https://codeberg.org/user/repo.git", ProviderCodeberg, "https://codeberg.org/user/repo"},
+		{"git@git.sr.ht:~user/repo", ProviderSourceHut, "https://git.sr.ht/~user/repo"},
+		{"http://git.example.com:8080/repo.git", ProviderOther, "http://git.example.com:8080/repo"},
+		{"/srv/git/repo.git", ProviderOther, ""},
+		{"../repo", ProviderOther, ""},
+		{"file:///srv/git/repo.git", ProviderOther, ""},
+	}
+	for _, test := range tests {
+		remote := &Remote{Name: "origin", FetchURLs: []string{test.url}}
+		assert.Equal(t, test.provider, remote.Provider(), test.url)
+		assert.Equal(t, test.webURL, remote.WebURL(), test.url)
+	}
+}
+
+func Test_GetRemotes(t *testing.T) {
+	config, err := ParseGitConfig(strings.NewReader(`[url "git@github.com:"]
+	pushInsteadOf = https://github.com/
+[url "https://mirror.example.com/"]
+	insteadOf = https://example.com/
+[remote "origin"]
+	url = https://github.com/hultan/gitdiscover.git
+[remote "backup"]
+	url = https://example.com/backup.git
+	pushurl = /srv/backup.git
+[remote "empty"]
+	fetch = +refs/heads/*:refs/remotes/empty/*
+`))
+	assert.Nil(t, err)
+
+	remotes := getRemotes(config)
+	assert.Equal(t, 2, len(remotes))
+	assert.Equal(t, "origin", remotes[0].Name)
+	assert.Equal(t, []string{"https://github.com/hultan/gitdiscover.git"}, remotes[0].FetchURLs)
+	assert.Equal(t, []string{"git@github.com:hultan/gitdiscover.git"}, remotes[0].PushURLs)
+	assert.Equal(t, []string{"https://mirror.example.com/backup.git"}, remotes[1].FetchURLs)
+	assert.Equal(t, []string{"/srv/backup.git"}, remotes[1].PushURLs)
+
+	assert.Equal(t, "backup", DefaultRemote(remotes, "backup/main").Name)
+	assert.Equal(t, "origin", DefaultRemote(remotes, "").Name)
+	assert.Equal(t, "backup", DefaultRemote(remotes[1:], "").Name)
+	assert.Nil(t, DefaultRemote(nil, "origin/main"))
+}
@@ -1,17 +1,17 @@
 package gitdiscover
 
 import (
+	"context"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
-	"regexp"
 	"strings"
 	"time"
 
-	gitStatus "github.com/hultan/gitstatus"
-	gitStatusPrompt "github.com/hultan/gitstatusprompt"
 	goMod "github.com/hultan/gomod"
+
+	"github.com/hultan/gitdiscover/internal/config"
 )
 
 // Repositories is a slice of git folders.
@@ -20,21 +20,37 @@ type Repositories []*Repository
 // Repository represents a git repositry
 // (or occasionally a standard non-git folder).
 type Repository struct {
-	name         string
-	path         string
-	isGit        bool
-	modifiedDate time.Time
-	imagePath    string
-	gitStatus    string
-	goStatus     string
-	changes      int
-	hasRemote    bool
-	isFavorite   bool
-}
-
-func newFolder(folder string) *Repository {
+	name          string
+	path          string
+	isGit         bool
+	modifiedDate  time.Time
+	imagePath     string
+	gitStatus     string
+	branch        string
+	upstream      string
+	ahead         int
+	behind        int
+	isDetached    bool
+	goStatus      string
+	changeSummary ChangeSummary
+	remotes       []*Remote
+	lastFetch     time.Time
+	isFavorite    bool
+	group         string
+	scanRoot      string
+
+	// latestTag is the highest semantic version tag reachable from HEAD
+	latestTag       string
+	commitsSinceTag int
+
+	// layout is where the git files are, for worktrees, submodules and bare repositories
+	layout    gitLayout
+	worktrees []string
+}
+
+func newFolder(ctx context.Context, folder string) *Repository {
 	f := Repository{path: strings.Trim(folder, " ")}
-	f.refresh()
+	f.refresh(ctx)
 	return &f
 }
 
@@ -44,16 +60,47 @@ func (f Repositories) Len() int { return len(f) }
 // Swap makes sure that Repositories implements the Interface interface
 func (f Repositories) Swap(i, j int) { f[i], f[j] = f[j], f[i] }
 
-func (t *Repository) refresh() {
+func (t *Repository) refresh(ctx context.Context) {
 	t.name = path.Base(t.path)
-	t.isGit = t.isGitFolder(path.Join(t.path, ".git"))
+	t.layout = getGitLayout(t.path)
+	t.isGit = t.layout.kind != RepositoryKindNone
 	t.modifiedDate = t.getModifiedDate(t.path)
 	if t.isGit {
-		t.hasRemote = t.getHasRemote(t.path)
-		t.gitStatus = t.getGitStatus(t.path)
+		t.remotes = t.getRemotes(t.layout.commonDir)
+		t.worktrees = getWorktrees(t.layout.commonDir)
 		t.goStatus = t.getGoStatus(t.path)
-		t.changes = t.getNoOfChanges(t.path)
+		t.refreshGitStatus(ctx)
+	}
+}
+
+// Get the git status and the number of changes
+func (t *Repository) refreshGitStatus(ctx context.Context) {
+	getInfo := getGitStatusInfo
+	if t.layout.kind == RepositoryKindBare {
+		getInfo = getBareStatusInfo
 	}
+	info, err := getInfo(ctx, t.path)
+	if err != nil {
+		info = &gitStatusInfo{}
+		t.gitStatus = err.Error()
+	} else {
+		t.gitStatus = info.prompt()
+	}
+	t.changeSummary = info.changes
+	t.changeSummary.Stashes = t.getStashCount(t.layout.commonDir)
+	t.branch = info.branch
+	t.upstream = info.upstream
+	t.ahead = info.ahead
+	t.behind = info.behind
+	t.isDetached = info.isDetached
+
+	// Repositories without tags (or commits) have no latest tag
+	tag, commits, err := GetLatestVersionTag(ctx, t.path)
+	if err != nil {
+		tag, commits = "", 0
+	}
+	t.latestTag = tag
+	t.commitsSinceTag = commits
 }
 
 // Name returns the name of the repository.
@@ -69,7 +116,10 @@ func (t *Repository) Path() string {
 // SetPath lets the user change the path to the repository.
 func (t *Repository) SetPath(newPath string) {
 	t.path = newPath
-	t.refresh()
+
+	ctx, cancel := context.WithTimeout(context.Background(), refreshTimeout)
+	defer cancel()
+	t.refresh(ctx)
 }
 
 // ImagePath returns the path to the Repositories image.
@@ -87,24 +137,73 @@ func (t *Repository) GitStatus() string {
 	return t.gitStatus
 }
 
+// Branch returns the name of the current branch, it is
+// empty for non-git folders and when HEAD is detached.
+func (t *Repository) Branch() string {
+	return t.branch
+}
+
+// Upstream returns the name of the upstream branch (like origin/main),
+// it is empty if the current branch has no upstream.
+func (t *Repository) Upstream() string {
+	return t.upstream
+}
+
+// Ahead returns the number of commits that the current branch is ahead of its upstream.
+func (t *Repository) Ahead() int {
+	return t.ahead
+}
+
+// Behind returns the number of commits that the current branch is behind its upstream.
+func (t *Repository) Behind() int {
+	return t.behind
+}
+
+// IsDetached returns true if HEAD is detached (not pointing to a branch).
+func (t *Repository) IsDetached() bool {
+	return t.isDetached
+}
+
 // GoStatus returns the go status
 func (t *Repository) GoStatus() string {
 	return t.goStatus
 }
 
 // HasRemote returns true if the repository has a Git remote repository.
-func (t *Repository) HasRemote() string {
-	if !t.IsGit() {
-		return " no "
-	}
-	if t.hasRemote {
-		return "yes"
-	}
+func (t *Repository) HasRemote() bool {
+	return t.isGit && len(t.remotes) > 0
+}
+
+// Remotes returns the remote repositories, in the order they are in .git/config.
+func (t *Repository) Remotes() []*Remote {
+	return t.remotes
+}
+
+// DefaultRemote returns the remote of the upstream branch, or origin,
+// or the first remote. It returns nil if there are no remotes.
+func (t *Repository) DefaultRemote() *Remote {
+	return DefaultRemote(t.remotes, t.upstream)
+}
 
-	return " no "
+// Kind returns the kind of repository, like a worktree or a submodule.
+func (t *Repository) Kind() RepositoryKind {
+	return t.layout.kind
 }
 
-// IsGit returns true if the folder points to a Git repository (has a .git folder).
+// ParentPath returns the main worktree of a linked worktree, or the parent
+// repository of a submodule. It is empty for other repositories.
+func (t *Repository) ParentPath() string {
+	return t.layout.parentPath
+}
+
+// Worktrees returns the paths of the linked worktrees that share this
+// repository, including this one if it is a linked worktree.
+func (t *Repository) Worktrees() []string {
+	return t.worktrees
+}
+
+// IsGit returns true if the folder points to a Git repository (has a .git folder or
+// file, or is a bare repository).
 func (t *Repository) IsGit() bool {
 	return t.isGit
 }
@@ -116,7 +215,28 @@ func (t *Repository) ModifiedDate() time.Time {
 
 // Changes returns the number of changes to the folder.
 func (t *Repository) Changes() int {
-	return t.changes
+	return t.changeSummary.Total()
+}
+
+// ChangeSummary returns the number of changes to the folder, per category.
+func (t *Repository) ChangeSummary() ChangeSummary {
+	return t.changeSummary
+}
+
+// LatestTag returns the highest semantic version tag (like v1.2.3) that
+// is reachable from HEAD, or an empty string if there is none.
+func (t *Repository) LatestTag() string {
+	return t.latestTag
+}
+
+// CommitsSinceTag returns the number of commits since LatestTag.
+func (t *Repository) CommitsSinceTag() int {
+	return t.commitsSinceTag
+}
+
+// Stashes returns the number of stashes in the repository.
+func (t *Repository) Stashes() int {
+	return t.changeSummary.Stashes
 }
 
 // IsFavorite returns the isFavorite flag
@@ -129,9 +249,59 @@ func (t *Repository) SetIsFavorite(value bool) {
 	t.isFavorite = value
 }
 
-func (t *Repository) isGitFolder(gitFolder string) bool {
-	_, err := os.Stat(gitFolder)
-	return !os.IsNotExist(err)
+// Group returns the user defined group of the repository, or an empty string.
+func (t *Repository) Group() string {
+	return t.group
+}
+
+// SetGroup sets the user defined group of the repository, an empty string removes it from its group.
+func (t *Repository) SetGroup(group string) {
+	t.group = strings.TrimSpace(group)
+}
+
+// IsScanned returns true if the repository was found by scanning a scan root,
+// rather than being added by the user.
+func (t *Repository) IsScanned() bool {
+	return t.scanRoot != ""
+}
+
+// ScanRoot returns the path of the scan root that found the repository.
+func (t *Repository) ScanRoot() string {
+	return t.scanRoot
+}
+
+// ToConfig returns the config for the repository.
+func (t *Repository) ToConfig() *config.Repository {
+	return &config.Repository{
+		Path:       t.path,
+		ImagePath:  t.imagePath,
+		IsFavorite: t.isFavorite,
+		Group:      t.group,
+		ScanRoot:   t.scanRoot,
+	}
+}
+
+// LastFetch returns the last time the repository was fetched by GitDiscover.
+func (t *Repository) LastFetch() time.Time {
+	return t.lastFetch
+}
+
+// SetLastFetch sets the last time the repository was fetched by GitDiscover.
+func (t *Repository) SetLastFetch(lastFetch time.Time) {
+	t.lastFetch = lastFetch
+}
+
+// NeedsFetch returns true if the repository has a remote, and has
+// not been fetched during the last interval.
+func (t *Repository) NeedsFetch(interval time.Duration, now time.Time) bool {
+	return t.HasRemote() && now.Sub(t.lastFetch) >= interval
+}
+
+// shouldSave returns true if the repository should be saved in the config.
+// Scanned repositories are found again on every refresh, so they are only
+// saved if the user has made them favorites, or added them to a group.
+func (t *Repository) shouldSave() bool {
+	return !t.IsScanned() || t.isFavorite || t.group != ""
 }
 
 // Get the modified date of a file
@@ -143,13 +313,6 @@ func (t *Repository) getModifiedDate(path string) time.Time {
 	return info.ModTime()
 }
 
-// Get the git status
-func (t *Repository) getGitStatus(path string) string {
-	p := gitStatusPrompt.GitStatusPrompt{}
-	status := p.GetPrompt(path)
-	return status
-}
-
 // Get the go status
 func (t *Repository) getGoStatus(path string) string {
 	m := goMod.GoMod{}
@@ -165,26 +328,21 @@ func (t *Repository) getGoStatus(path string) string {
 	}
 }
 
-func (t *Repository) getNoOfChanges(path string) int {
-	gs := gitStatus.GitStatus{}
-	status, err := gs.GetStatus(path)
+// Get the number of stashes, from the stash reflog (one line per stash),
+// which is shared by all worktrees
+func (t *Repository) getStashCount(commonDir string) int {
+	buf, err := ioutil.ReadFile(path.Join(commonDir, "logs", "refs", "stash"))
 	if err != nil {
 		return 0
 	}
-	return status.Untracked() + status.Modified() + status.Deleted() + status.Unmerged()
+	return strings.Count(string(buf), "\n")
 }
 
-func (t *Repository) getHasRemote(repoPath string) bool {
-	configPath := path.Join(repoPath, ".git", "config")
-	buf, err := ioutil.ReadFile(configPath)
+// Get the remotes from the config file, which is shared by all worktrees
+func (t *Repository) getRemotes(commonDir string) []*Remote {
+	remotes, err := GetRemotes(path.Join(commonDir, "config"))
 	if err != nil {
-		return false
-	}
-	expr := `\[remote`
-	r := regexp.MustCompile(expr)
-	result := r.FindString(string(buf))
-	if result == "" {
-		return false
+		return nil
 	}
-	return true
+	return remotes
 }
@@ -1,61 +1,328 @@
 package gitdiscover
 
-// ByName sorts Repositories by their name.
-type ByName struct{ Repositories }
+import (
+	"fmt"
+	"sort"
+	"strings"
 
-// Less is a helper function that sorts by their names.
-func (b ByName) Less(i, j int) bool {
-	if b.Repositories[i].IsFavorite() && !b.Repositories[j].IsFavorite() {
-		return true
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+// SortColumn is a column that repositories can be sorted by, like "name" or
+// "ahead". The names of the change categories (like "staged") are also columns.
+type SortColumn string
+
+const (
+	SortByName         SortColumn = "name"
+	SortByPath         SortColumn = "path"
+	SortByModifiedDate SortColumn = "date"
+	SortByAhead        SortColumn = "ahead"
+	SortByBehind       SortColumn = "behind"
+	SortByLatestTag    SortColumn = "tag"
+	SortByGoStatus     SortColumn = "go"
+	SortByRemote       SortColumn = "remote"
+	SortByGroup        SortColumn = "group"
+	// SortByGit sorts git repositories before other folders
+	SortByGit SortColumn = "git"
+)
+
+// SortColumns contains all sort columns, in display order.
+var SortColumns = getSortColumns()
+
+func getSortColumns() []SortColumn {
+	columns := []SortColumn{SortByName, SortByPath, SortByModifiedDate}
+	for _, category := range ChangeCategories {
+		columns = append(columns, ChangeCategorySortColumn(category))
 	}
-	if b.Repositories[j].IsFavorite() && !b.Repositories[i].IsFavorite() {
-		return false
+	return append(columns, SortByAhead, SortByBehind, SortByLatestTag, SortByGoStatus, SortByRemote,
+		SortByGroup, SortByGit)
+}
+
+// ChangeCategorySortColumn returns the column that sorts by the number of changes in a category.
+func ChangeCategorySortColumn(category ChangeCategory) SortColumn {
+	return SortColumn(category.String())
+}
+
+// ParseSortColumn returns the sort column with the given name.
+func ParseSortColumn(name string) (SortColumn, error) {
+	for _, column := range SortColumns {
+		if strings.EqualFold(name, string(column)) {
+			return column, nil
+		}
 	}
-	if b.Repositories[i].IsGit() && !b.Repositories[j].IsGit() {
+	return SortByName, fmt.Errorf("invalid sort column : %s", name)
+}
+
+// Title returns the name of the column, for menus, like "Modified date".
+func (c SortColumn) Title() string {
+	switch c {
+	case SortByModifiedDate:
+		return "Modified date"
+	case SortByLatestTag:
+		return "Latest tag"
+	case SortByGoStatus:
+		return "Go version"
+	case SortByGit:
+		return "Git repositories first"
+	default:
+		return strings.ToUpper(string(c[:1])) + string(c[1:])
+	}
+}
+
+// DefaultDescending returns true for the columns that are usually sorted
+// in descending order, so that the newest date or the most changes are first.
+func (c SortColumn) DefaultDescending() bool {
+	if _, isCategory := c.changeCategory(); isCategory {
 		return true
 	}
-	if b.Repositories[j].IsGit() && !b.Repositories[i].IsGit() {
+	switch c {
+	case SortByModifiedDate, SortByAhead, SortByBehind, SortByLatestTag:
+		return true
+	default:
 		return false
 	}
-	return b.Repositories[i].name < b.Repositories[j].name
 }
 
-// ByModifiedDate sorts Repositories by their modification date.
-type ByModifiedDate struct{ Repositories }
+func (c SortColumn) changeCategory() (ChangeCategory, bool) {
+	for _, category := range ChangeCategories {
+		if ChangeCategorySortColumn(category) == c {
+			return category, true
+		}
+	}
+	return ChangesTotal, false
+}
 
-// Less is a helper function that sorts by their names.
-func (b ByModifiedDate) Less(i, j int) bool {
-	if b.Repositories[i].IsFavorite() && !b.Repositories[j].IsFavorite() {
-		return true
+// compare returns -1, 0 or 1 if a is before, equal to, or after b, in ascending order
+func (c SortColumn) compare(a, b *Repository) int {
+	if category, isCategory := c.changeCategory(); isCategory {
+		return sign(a.changeSummary.Count(category) - b.changeSummary.Count(category))
 	}
-	if b.Repositories[j].IsFavorite() && !b.Repositories[i].IsFavorite() {
-		return false
+	switch c {
+	case SortByName:
+		return strings.Compare(a.name, b.name)
+	case SortByPath:
+		return strings.Compare(a.path, b.path)
+	case SortByModifiedDate:
+		switch {
+		case a.modifiedDate.Before(b.modifiedDate):
+			return -1
+		case a.modifiedDate.After(b.modifiedDate):
+			return 1
+		default:
+			return 0
+		}
+	case SortByAhead:
+		return sign(a.ahead - b.ahead)
+	case SortByBehind:
+		return sign(a.behind - b.behind)
+	case SortByLatestTag:
+		return compareLatestTags(a.latestTag, b.latestTag)
+	case SortByGoStatus:
+		return strings.Compare(strings.TrimSpace(a.goStatus), strings.TrimSpace(b.goStatus))
+	case SortByRemote:
+		return strings.Compare(getRemoteProvider(a), getRemoteProvider(b))
+	case SortByGroup:
+		return strings.Compare(strings.ToLower(a.group), strings.ToLower(b.group))
+	case SortByGit:
+		return compareBool(a.isGit, b.isGit)
+	default:
+		return 0
 	}
-	if b.Repositories[i].IsGit() && !b.Repositories[j].IsGit() {
-		return true
+}
+
+// Repositories without a version tag are before the ones with a tag
+func compareLatestTags(a, b string) int {
+	aVersion, aOk := ParseVersion(a)
+	bVersion, bOk := ParseVersion(b)
+	switch {
+	case aOk && bOk:
+		return aVersion.Compare(bVersion)
+	default:
+		return compareBool(bOk, aOk)
 	}
-	if b.Repositories[j].IsGit() && !b.Repositories[i].IsGit() {
-		return false
+}
+
+func getRemoteProvider(repo *Repository) string {
+	if remote := repo.DefaultRemote(); remote != nil {
+		return remote.Provider().String()
+	}
+	return ""
+}
+
+// compareBool sorts true before false
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return -1
+	default:
+		return 1
 	}
-	return b.Repositories[i].ModifiedDate().After(b.Repositories[j].ModifiedDate())
 }
 
-// ByChanges sorts Repositories by the amount of changed files in the repository.
-type ByChanges struct{ Repositories }
+// SortKey is a column in a sort order, and its direction.
+type SortKey struct {
+	Column     SortColumn
+	Descending bool
+}
 
-// Less is a helper function that sorts by their names.
-func (b ByChanges) Less(i, j int) bool {
-	if b.Repositories[i].IsFavorite() && !b.Repositories[j].IsFavorite() {
-		return true
+// NewSortKey returns a sort key that sorts by the column in its default direction.
+func NewSortKey(column SortColumn) SortKey {
+	return SortKey{Column: column, Descending: column.DefaultDescending()}
+}
+
+// ParseSortKey parses a sort key, like "date", "date:asc" or "name:desc".
+// A column without a direction is sorted in its default direction.
+func ParseSortKey(text string) (SortKey, error) {
+	name, direction := strings.TrimSpace(text), ""
+	if i := strings.IndexByte(name, ':'); i >= 0 {
+		name, direction = name[:i], strings.ToLower(name[i+1:])
 	}
-	if b.Repositories[j].IsFavorite() && !b.Repositories[i].IsFavorite() {
-		return false
+	column, err := ParseSortColumn(name)
+	if err != nil {
+		return SortKey{}, err
 	}
-	if b.Repositories[i].IsGit() && !b.Repositories[j].IsGit() {
-		return true
+	key := NewSortKey(column)
+	switch direction {
+	case "":
+	case "asc":
+		key.Descending = false
+	case "desc":
+		key.Descending = true
+	default:
+		return SortKey{}, fmt.Errorf("invalid sort direction : %s", text)
 	}
-	if b.Repositories[j].IsGit() && !b.Repositories[i].IsGit() {
-		return false
+	return key, nil
+}
+
+// String returns the sort key in the format that ParseSortKey parses, like "date:desc".
+func (k SortKey) String() string {
+	if k.Descending {
+		return string(k.Column) + ":desc"
+	}
+	return string(k.Column) + ":asc"
+}
+
+func (k SortKey) compare(a, b *Repository) int {
+	if k.Descending {
+		return k.Column.compare(b, a)
+	}
+	return k.Column.compare(a, b)
+}
+
+// SortOrder is a chain of sort keys, the second key is used when the
+// repositories are equal for the first key, and so on.
+type SortOrder struct {
+	// FavoritesFirst sorts the favorites before the other repositories
+	FavoritesFirst bool
+	Keys           []SortKey
+}
+
+// DefaultSortOrder returns the default sort order: favorites first,
+// then git repositories, and then by name.
+func DefaultSortOrder() *SortOrder {
+	return &SortOrder{FavoritesFirst: true, Keys: []SortKey{NewSortKey(SortByGit), NewSortKey(SortByName)}}
+}
+
+// NewSortOrder creates a sort order from the sort order in the config. A
+// config without sort keys returns the default sort order.
+func NewSortOrder(sort config.Sort) (*SortOrder, error) {
+	if len(sort.Keys) == 0 {
+		return DefaultSortOrder(), nil
+	}
+	order := &SortOrder{FavoritesFirst: sort.FavoritesFirst}
+	for _, key := range sort.Keys {
+		column, err := ParseSortColumn(key.Column)
+		if err != nil {
+			return nil, err
+		}
+		order.Keys = append(order.Keys, SortKey{Column: column, Descending: key.Descending})
+	}
+	return order, nil
+}
+
+// ParseSortKeys parses a comma separated list of sort keys, like "ahead,date:asc".
+func ParseSortKeys(text string) ([]SortKey, error) {
+	var keys []SortKey
+	for _, field := range strings.Split(text, ",") {
+		key, err := ParseSortKey(field)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// ToConfig returns the sort order, for saving it in the config.
+func (o *SortOrder) ToConfig() config.Sort {
+	sort := config.Sort{FavoritesFirst: o.FavoritesFirst}
+	for _, key := range o.Keys {
+		sort.Keys = append(sort.Keys, config.SortKey{Column: string(key.Column), Descending: key.Descending})
 	}
-	return b.Repositories[i].changes > b.Repositories[j].changes
+	return sort
+}
+
+// String returns the sort order, like "favorites, ahead:desc, name:asc".
+func (o *SortOrder) String() string {
+	var keys []string
+	if o.FavoritesFirst {
+		keys = append(keys, "favorites")
+	}
+	for _, key := range o.Keys {
+		keys = append(keys, key.String())
+	}
+	return strings.Join(keys, ", ")
+}
+
+// Primary returns the first sort key, or false if there are no keys.
+func (o *SortOrder) Primary() (SortKey, bool) {
+	if len(o.Keys) == 0 {
+		return SortKey{}, false
+	}
+	return o.Keys[0], true
+}
+
+// SetPrimary makes the column the first sort key, in its default direction,
+// and keeps the other keys after it. If the column already is the first key,
+// its direction is reversed.
+func (o *SortOrder) SetPrimary(column SortColumn) {
+	if primary, ok := o.Primary(); ok && primary.Column == column {
+		o.Keys[0].Descending = !primary.Descending
+		return
+	}
+	keys := []SortKey{NewSortKey(column)}
+	for _, key := range o.Keys {
+		if key.Column != column {
+			keys = append(keys, key)
+		}
+	}
+	o.Keys = keys
+}
+
+// Compare returns -1, 0 or 1 if a is sorted before, equal to, or after b.
+// Repositories that are equal for all keys are sorted by name, and then path.
+func (o *SortOrder) Compare(a, b *Repository) int {
+	if o.FavoritesFirst {
+		if c := compareBool(a.isFavorite, b.isFavorite); c != 0 {
+			return c
+		}
+	}
+	for _, key := range o.Keys {
+		if c := key.compare(a, b); c != 0 {
+			return c
+		}
+	}
+	if c := SortByName.compare(a, b); c != 0 {
+		return c
+	}
+	return SortByPath.compare(a, b)
+}
+
+// Sort sorts the repositories in the sort order.
+func (r Repositories) Sort(order *SortOrder) {
+	sort.SliceStable(r, func(i, j int) bool {
+		return order.Compare(r[i], r[j]) < 0
+	})
 }
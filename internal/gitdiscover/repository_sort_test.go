@@ -0,0 +1,122 @@
+package gitdiscover
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+func getSortRepositories() Repositories {
+	return Repositories{
+		&Repository{name: "c", isGit: true, ahead: 1, behind: 5},
+		&Repository{name: "d"},
+		&Repository{name: "a", isGit: true, ahead: 3},
+		&Repository{name: "b", isGit: true, behind: 2, isFavorite: true},
+	}
+}
+
+func getNames(repos Repositories) []string {
+	var names []string
+	for _, repo := range repos {
+		names = append(names, repo.Name())
+	}
+	return names
+}
+
+func sortByKeys(repos Repositories, favoritesFirst bool, keys ...SortKey) {
+	repos.Sort(&SortOrder{FavoritesFirst: favoritesFirst, Keys: keys})
+}
+
+func Test_SortDefault(t *testing.T) {
+	repos := getSortRepositories()
+	repos.Sort(DefaultSortOrder())
+	assert.Equal(t, []string{"b", "a", "c", "d"}, getNames(repos))
+}
+
+func Test_SortByAhead(t *testing.T) {
+	repos := getSortRepositories()
+	sortByKeys(repos, true, NewSortKey(SortByGit), NewSortKey(SortByAhead))
+	assert.Equal(t, []string{"b", "a", "c", "d"}, getNames(repos))
+}
+
+func Test_SortByBehind(t *testing.T) {
+	repos := getSortRepositories()
+	sortByKeys(repos, true, NewSortKey(SortByGit), NewSortKey(SortByBehind))
+	assert.Equal(t, []string{"b", "c", "a", "d"}, getNames(repos))
+}
+
+func Test_SortByChangeCategory(t *testing.T) {
+	repos := getSortRepositories()
+	repos[0].changeSummary.Stashes = 1
+	repos[2].changeSummary.Stashes = 2
+	sortByKeys(repos, true, NewSortKey(ChangeCategorySortColumn(ChangesStashes)))
+	assert.Equal(t, []string{"b", "a", "c", "d"}, getNames(repos))
+	sortByKeys(repos, true, NewSortKey(ChangeCategorySortColumn(ChangesStaged)))
+	assert.Equal(t, "b", repos[0].Name())
+}
+
+func Test_SortChain(t *testing.T) {
+	repos := getSortRepositories()
+
+	// Without favorites first, and ascending
+	sortByKeys(repos, false, SortKey{Column: SortByBehind})
+	assert.Equal(t, []string{"a", "d", "b", "c"}, getNames(repos))
+
+	// The second key is used when the first key is equal
+	sortByKeys(repos, false, NewSortKey(SortByGit), SortKey{Column: SortByName, Descending: true})
+	assert.Equal(t, []string{"c", "b", "a", "d"}, getNames(repos))
+}
+
+func Test_SortByLatestTag(t *testing.T) {
+	repos := Repositories{
+		&Repository{name: "a", latestTag: "v1.10.0"},
+		&Repository{name: "b"},
+		&Repository{name: "c", latestTag: "v1.9.0"},
+	}
+	sortByKeys(repos, false, NewSortKey(SortByLatestTag))
+	assert.Equal(t, []string{"a", "c", "b"}, getNames(repos))
+}
+
+func Test_ParseSortKeys(t *testing.T) {
+	keys, err := ParseSortKeys("ahead, date:asc,Name:desc,staged")
+	assert.Nil(t, err)
+	assert.Equal(t, []SortKey{
+		{Column: SortByAhead, Descending: true}, {Column: SortByModifiedDate},
+		{Column: SortByName, Descending: true}, {Column: "staged", Descending: true},
+	}, keys)
+
+	_, err = ParseSortKeys("size")
+	assert.NotNil(t, err)
+	_, err = ParseSortKeys("name:up")
+	assert.NotNil(t, err)
+}
+
+func Test_SortOrder_SetPrimary(t *testing.T) {
+	order := DefaultSortOrder()
+	order.SetPrimary(SortByName)
+	assert.Equal(t, "favorites, name:asc, git:asc", order.String())
+
+	// Setting the primary key again reverses it
+	order.SetPrimary(SortByName)
+	assert.Equal(t, "favorites, name:desc, git:asc", order.String())
+
+	order.SetPrimary(SortByModifiedDate)
+	assert.Equal(t, "favorites, date:desc, name:desc, git:asc", order.String())
+}
+
+func Test_SortOrder_Config(t *testing.T) {
+	order, err := NewSortOrder(config.Sort{})
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultSortOrder(), order)
+
+	sort := config.Sort{Keys: []config.SortKey{{Column: "ahead", Descending: true}, {Column: "name"}}}
+	order, err = NewSortOrder(sort)
+	assert.Nil(t, err)
+	assert.False(t, order.FavoritesFirst)
+	assert.Equal(t, sort, order.ToConfig())
+
+	_, err = NewSortOrder(config.Sort{Keys: []config.SortKey{{Column: "size"}}})
+	assert.NotNil(t, err)
+}
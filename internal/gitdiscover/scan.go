@@ -0,0 +1,104 @@
+package gitdiscover
+
+import (
+	"context"
+	"io/fs"
+	"path/filepath"
+	"strings"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+// defaultScanDepth is used for scan roots that has no max depth
+const defaultScanDepth = 3
+
+// AddScannedRepositories scans the scan roots for git repositories, and returns
+// the config repositories followed by the scanned repositories that are not
+// already in the config. Scanned repositories have their ScanRoot field set.
+func AddScannedRepositories(ctx context.Context, configRepos []*config.Repository,
+	scanRoots []*config.ScanRoot) ([]*config.Repository, error) {
+
+	result := append([]*config.Repository(nil), configRepos...)
+	known := make(map[string]bool)
+	for _, repo := range configRepos {
+		known[filepath.Clean(strings.Trim(repo.Path, " "))] = true
+	}
+
+	for _, root := range scanRoots {
+		paths, err := scanRoot(ctx, root)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			if known[path] {
+				continue
+			}
+			known[path] = true
+			result = append(result, &config.Repository{Path: path, ScanRoot: root.Path})
+		}
+	}
+
+	return result, nil
+}
+
+// scanRoot returns the paths of the git repositories below the scan root
+func scanRoot(ctx context.Context, root *config.ScanRoot) ([]string, error) {
+	rootPath := filepath.Clean(root.Path)
+	maxDepth := root.MaxDepth
+	if maxDepth <= 0 {
+		maxDepth = defaultScanDepth
+	}
+
+	var paths []string
+	err := filepath.WalkDir(rootPath, func(path string, entry fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			// Skip folders that we can't read (or a missing scan root)
+			return nil
+		}
+		if !entry.IsDir() || path == rootPath {
+			return nil
+		}
+
+		relativePath, _ := filepath.Rel(rootPath, path)
+		depth := strings.Count(relativePath, string(filepath.Separator)) + 1
+
+		// Hidden folders (including .git) and excluded folders
+		if strings.HasPrefix(entry.Name(), ".") || matchesAny(root.Exclude, relativePath) {
+			return filepath.SkipDir
+		}
+
+		kind := getGitLayout(path).kind
+		isRepository := kind != RepositoryKindNone
+		if isRepository && (len(root.Include) == 0 || matchesAny(root.Include, relativePath)) {
+			paths = append(paths, path)
+		}
+		// There are no repositories inside bare repositories, only git files
+		if kind == RepositoryKindBare || (isRepository && root.SkipNested) || depth >= maxDepth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return paths, nil
+}
+
+// matchesAny returns true if any of the patterns matches the
+// relative path, or the name of the folder.
+func matchesAny(patterns []string, relativePath string) bool {
+	name := filepath.Base(relativePath)
+	for _, pattern := range patterns {
+		if ok, _ := filepath.Match(pattern, relativePath); ok {
+			return true
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
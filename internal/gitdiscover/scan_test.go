@@ -0,0 +1,96 @@
+package gitdiscover
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+// createScanTree creates a folder tree with a few fake repositories
+func createScanTree(t *testing.T) string {
+	root := t.TempDir()
+	for _, dir := range []string{
+		"a/.git",
+		"a/nested/.git",
+		"b/c/.git",
+		"b/c/d/e/.git",
+		"archive/old/.git",
+		".hidden/f/.git",
+		"plain/folder",
+	} {
+		err := os.MkdirAll(filepath.Join(root, dir), 0755)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func Test_scanRoot(t *testing.T) {
+	root := createScanTree(t)
+
+	paths, err := scanRoot(context.Background(), &config.ScanRoot{Path: root})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(root, "a"),
+		filepath.Join(root, "a/nested"),
+		filepath.Join(root, "b/c"),
+		filepath.Join(root, "archive/old"),
+	}, paths)
+}
+
+func Test_scanRoot_Options(t *testing.T) {
+	root := createScanTree(t)
+
+	paths, err := scanRoot(context.Background(), &config.ScanRoot{
+		Path:       root,
+		MaxDepth:   4,
+		Exclude:    []string{"archive"},
+		SkipNested: true,
+	})
+	assert.Nil(t, err)
+	assert.ElementsMatch(t, []string{
+		filepath.Join(root, "a"),
+		filepath.Join(root, "b/c"),
+	}, paths)
+
+	paths, err = scanRoot(context.Background(), &config.ScanRoot{
+		Path:    root,
+		Include: []string{"b/*"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "b/c")}, paths)
+
+	paths, err = scanRoot(context.Background(), &config.ScanRoot{Path: filepath.Join(root, "missing")})
+	assert.Nil(t, err)
+	assert.Empty(t, paths)
+}
+
+func Test_AddScannedRepositories(t *testing.T) {
+	root := createScanTree(t)
+	configRepos := []*config.Repository{{Path: filepath.Join(root, "a"), IsFavorite: true}}
+
+	repos, err := AddScannedRepositories(context.Background(), configRepos, []*config.ScanRoot{
+		{Path: root, SkipNested: true},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(repos))
+	assert.Equal(t, configRepos[0], repos[0])
+	for _, repo := range repos[1:] {
+		assert.Equal(t, root, repo.ScanRoot)
+	}
+}
+
+func Test_scanRoot_Bare(t *testing.T) {
+	root := t.TempDir()
+	runTestGit(t, root, "init", "-q", "--bare", "server/repo.git")
+
+	paths, err := scanRoot(context.Background(), &config.ScanRoot{Path: root, MaxDepth: 5})
+	assert.Nil(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "server/repo.git")}, paths)
+}
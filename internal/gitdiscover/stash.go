@@ -0,0 +1,128 @@
+package gitdiscover
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// stashTimeout is the maximum time that listing or changing stashes may take
+const stashTimeout = 30 * time.Second
+
+// The fields of a stash are separated by the unit separator, like in historyFormat
+const stashFormat = "--format=%gd%x1f%H%x1f%cI%x1f%gs"
+
+// Stash is a stash entry in a repository.
+type Stash struct {
+	// Index is the position of the stash, 0 is the latest stash
+	Index int
+	Hash  string
+	Date  time.Time
+	// Message is the stash message, like "WIP on main: 1a2b3c4 Fix the refresh"
+	Message string
+}
+
+// Ref returns the name of the stash, like "stash@{0}".
+func (s *Stash) Ref() string {
+	return getStashRef(s.Index)
+}
+
+func getStashRef(index int) string {
+	return fmt.Sprintf("stash@{%d}", index)
+}
+
+// GetStashes returns the stashes in the repository at repoPath, latest first.
+func GetStashes(ctx context.Context, repoPath string) ([]*Stash, error) {
+	result, err := newRunner(repoPath, stashTimeout).Run(ctx, "stash", "list", stashFormat)
+	if err != nil {
+		return nil, getGitError(err)
+	}
+	return parseStashes(result.Stdout)
+}
+
+// Parse the output of git stash list, using stashFormat
+func parseStashes(text string) ([]*Stash, error) {
+	var stashes []*Stash
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("invalid git stash list line : %q", line)
+		}
+		stash := &Stash{Hash: fields[1], Message: fields[3]}
+		_, err := fmt.Sscanf(fields[0], "stash@{%d}", &stash.Index)
+		if err != nil {
+			return nil, fmt.Errorf("invalid stash name : %q", fields[0])
+		}
+		stash.Date, err = time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, err
+		}
+		stashes = append(stashes, stash)
+	}
+	return stashes, nil
+}
+
+// GetStashDiff returns the diff of the stash with the given index.
+func GetStashDiff(ctx context.Context, repoPath string, index int) (string, error) {
+	result, err := newRunner(repoPath, stashTimeout).Run(ctx, "stash", "show", "--patch", "--no-color", getStashRef(index))
+	if err != nil {
+		return "", getGitError(err)
+	}
+	return result.Stdout, nil
+}
+
+// ApplyStash applies the stash, and keeps it.
+func ApplyStash(ctx context.Context, repoPath string, stash *Stash) error {
+	return runStashCommand(ctx, repoPath, stash, "apply")
+}
+
+// PopStash applies the stash, and removes it if it could be applied without conflicts.
+func PopStash(ctx context.Context, repoPath string, stash *Stash) error {
+	return runStashCommand(ctx, repoPath, stash, "pop")
+}
+
+// DropStash removes the stash.
+func DropStash(ctx context.Context, repoPath string, stash *Stash) error {
+	return runStashCommand(ctx, repoPath, stash, "drop")
+}
+
+// runStashCommand runs git stash with the command on the stash. The stash
+// commands take the position of the stash, and the positions change when
+// stashes are added or removed (by another program), so we check that the
+// stash is still at its position first.
+func runStashCommand(ctx context.Context, repoPath string, stash *Stash, command string) error {
+	runner := newRunner(repoPath, stashTimeout)
+	hash, err := runner.Output(ctx, "rev-parse", "--verify", "--quiet", stash.Ref())
+	if err != nil || hash != stash.Hash {
+		return fmt.Errorf("%s has changed since the stashes were listed, refresh the list and try again", stash.Ref())
+	}
+	_, err = runner.Run(ctx, "stash", command, "--quiet", stash.Ref())
+	return getGitError(err)
+}
+
+// CreateStash stashes the changes in the repository, with an optional message,
+// and also stashes untracked files if includeUntracked is true.
+func CreateStash(ctx context.Context, repoPath, message string, includeUntracked bool) error {
+	args := []string{"stash", "push"}
+	if includeUntracked {
+		args = append(args, "--include-untracked")
+	}
+	if message != "" {
+		args = append(args, "--message", message)
+	}
+
+	result, err := newRunner(repoPath, stashTimeout).Run(ctx, args...)
+	if err != nil {
+		return getGitError(err)
+	}
+	// Older versions of git exit with 0, even if there is nothing to stash
+	if strings.Contains(result.Stdout, "No local changes to save") {
+		return errors.New("no local changes to save")
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package gitdiscover
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+func Test_Stashes(t *testing.T) {
+	setTestIdentity(t)
+	dir := createTestRepository(t)
+	ctx := context.Background()
+
+	stashes, err := GetStashes(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(stashes))
+
+	writeTestFile(t, dir, "committed.txt", "first")
+	err = CreateStash(ctx, dir, "", false)
+	assert.Nil(t, err)
+	writeTestFile(t, dir, "committed.txt", "second")
+	err = CreateStash(ctx, dir, "Second stash", true)
+	assert.Nil(t, err)
+
+	// Nothing left to stash
+	err = CreateStash(ctx, dir, "", true)
+	assert.NotNil(t, err)
+
+	stashes, err = GetStashes(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(stashes))
+	assert.Equal(t, 0, stashes[0].Index)
+	assert.Equal(t, "stash@{0}", stashes[0].Ref())
+	assert.Equal(t, "On main: Second stash", stashes[0].Message)
+	assert.Equal(t, "WIP on main: ", stashes[1].Message[:13])
+	assert.False(t, stashes[1].Date.IsZero())
+
+	repo := LoadRepository(ctx, &config.Repository{Path: dir})
+	assert.Equal(t, 2, repo.Stashes())
+
+	diff, err := GetStashDiff(ctx, dir, 1)
+	assert.Nil(t, err)
+	assert.Contains(t, diff, "+first")
+
+	err = ApplyStash(ctx, dir, stashes[1])
+	assert.Nil(t, err)
+	assert.Contains(t, runTestGit(t, dir, "diff"), "+first")
+	runTestGit(t, dir, "checkout", "--", "committed.txt")
+
+	err = PopStash(ctx, dir, stashes[0])
+	assert.Nil(t, err)
+	assert.Contains(t, runTestGit(t, dir, "diff"), "+second")
+
+	// The first stash is now stash@{0}, so the listed stash@{1} is stale
+	err = DropStash(ctx, dir, stashes[1])
+	assert.NotNil(t, err)
+	stashes, err = GetStashes(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stashes))
+
+	err = DropStash(ctx, dir, stashes[0])
+	assert.Nil(t, err)
+	err = DropStash(ctx, dir, stashes[0])
+	assert.NotNil(t, err)
+	stashes, err = GetStashes(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, len(stashes))
+}
+
+func Test_parseStashes(t *testing.T) {
+	stashes, err := parseStashes("stash@{3}\x1fabc\x1f2022-01-02T03:04:05+01:00\x1fOn main: test\n")
+	assert.Nil(t, err)
+	assert.Equal(t, 1, len(stashes))
+	assert.Equal(t, 3, stashes[0].Index)
+	assert.Equal(t, "abc", stashes[0].Hash)
+
+	_, err = parseStashes("invalid\x1fabc\x1f2022-01-02T03:04:05+01:00\x1fOn main: test\n")
+	assert.NotNil(t, err)
+}
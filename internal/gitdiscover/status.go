@@ -0,0 +1,207 @@
+package gitdiscover
+
+import (
+	"context"
+	"strconv"
+	"strings"
+)
+
+// gitStatusInfo contains the parsed output of git status.
+//
+// We used to get this from github.com/hultan/gitstatus, but that package
+// changes the working directory of the whole process (os.Chdir) while
+// running git, so it can not be used when refreshing repositories in
+// parallel. Here git runs in the repository folder (using gitrunner) instead.
+type gitStatusInfo struct {
+	branch     string
+	upstream   string
+	ahead      int
+	behind     int
+	isDetached bool
+
+	// files are the changed, and untracked, files
+	files   []*ChangedFile
+	changes ChangeSummary
+}
+
+// Get the git status of the repository at path
+func getGitStatusInfo(ctx context.Context, path string) (*gitStatusInfo, error) {
+	runner := newRunner(path, refreshTimeout)
+	// Don't let git status update the index, since that would
+	// trigger the repository watcher every time we refresh.
+	runner.Env = []string{"GIT_OPTIONAL_LOCKS=0"}
+	result, err := runner.Run(ctx, "status", "--porcelain=v2", "-z", "--branch", "--untracked-files=all")
+	if err != nil {
+		return nil, getGitError(err)
+	}
+
+	return parseGitStatus(result.Stdout)
+}
+
+// Get the branch of the bare repository at path, since git status
+// needs a working tree. Bare repositories have no changes or upstream.
+func getBareStatusInfo(ctx context.Context, path string) (*gitStatusInfo, error) {
+	result, err := newRunner(path, refreshTimeout).Run(ctx, "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		// symbolic-ref fails when HEAD is detached
+		return &gitStatusInfo{isDetached: true}, nil
+	}
+	return &gitStatusInfo{branch: strings.TrimSpace(result.Stdout)}, nil
+}
+
+// Parse the output of "git status --porcelain=v2 -z", with or without --branch
+func parseGitStatus(status string) (*gitStatusInfo, error) {
+	info := &gitStatusInfo{}
+	items := strings.Split(status, "\x00")
+	for i := 0; i < len(items); i++ {
+		item := items[i]
+		switch {
+		case strings.HasPrefix(item, "# "):
+			err := info.parseBranch(item)
+			if err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(item, "1 "):
+			// 1 XY sub mH mI mW hH hI path
+			fields := strings.SplitN(item, " ", 9)
+			if len(fields) == 9 && len(fields[1]) == 2 {
+				info.files = append(info.files, &ChangedFile{Path: fields[8],
+					IndexStatus: fields[1][0], WorktreeStatus: fields[1][1]})
+			}
+		case strings.HasPrefix(item, "2 "):
+			// 2 XY sub mH mI mW hH hI score path, followed by the original path
+			// as a separate item, which must not be parsed as an entry
+			fields := strings.SplitN(item, " ", 10)
+			if i+1 < len(items) {
+				i++
+			}
+			if len(fields) == 10 && len(fields[1]) == 2 {
+				info.files = append(info.files, &ChangedFile{Path: fields[9], OrigPath: items[i],
+					IndexStatus: fields[1][0], WorktreeStatus: fields[1][1]})
+			}
+		case strings.HasPrefix(item, "u "):
+			// u XY sub m1 m2 m3 mW h1 h2 h3 path
+			fields := strings.SplitN(item, " ", 11)
+			if len(fields) == 11 && len(fields[1]) == 2 {
+				info.files = append(info.files, &ChangedFile{Path: fields[10],
+					IndexStatus: fields[1][0], WorktreeStatus: fields[1][1], Unmerged: true})
+			}
+		case strings.HasPrefix(item, "? "):
+			info.files = append(info.files, &ChangedFile{Path: item[2:],
+				IndexStatus: '.', WorktreeStatus: '?', Untracked: true})
+		}
+	}
+	info.changes = getChangeSummary(info.files)
+
+	return info, nil
+}
+
+func (g *gitStatusInfo) parseBranch(line string) error {
+	items := strings.Split(line, " ")
+	if len(items) < 3 {
+		return nil
+	}
+
+	switch items[1] {
+	case "branch.head":
+		if items[2] == "(detached)" {
+			g.isDetached = true
+		} else {
+			g.branch = items[2]
+		}
+	case "branch.upstream":
+		g.upstream = items[2]
+	case "branch.ab":
+		if len(items) < 4 {
+			return nil
+		}
+		ahead, err := strconv.Atoi(strings.TrimPrefix(items[2], "+"))
+		if err != nil {
+			return err
+		}
+		behind, err := strconv.Atoi(strings.TrimPrefix(items[3], "-"))
+		if err != nil {
+			return err
+		}
+		g.ahead = ahead
+		g.behind = behind
+	}
+
+	return nil
+}
+
+// getChangeSummary counts the changes in the changed files. The stashes are not counted.
+func getChangeSummary(files []*ChangedFile) ChangeSummary {
+	var changes ChangeSummary
+	for _, file := range files {
+		switch {
+		case file.Untracked:
+			changes.Untracked++
+			continue
+		case file.Unmerged:
+			changes.Unmerged++
+			continue
+		}
+
+		// Index (staged) status
+		if file.IndexStatus != '.' {
+			changes.Staged++
+		}
+		if file.OrigPath != "" {
+			changes.Renamed++
+		}
+
+		// Working tree (unstaged) status
+		if file.WorktreeStatus != '.' {
+			changes.Unstaged++
+		}
+		switch file.WorktreeStatus {
+		case 'M':
+			changes.Modified++
+		case 'D':
+			changes.Deleted++
+		}
+	}
+	return changes
+}
+
+// prompt returns a short status text, like "main↑1|+2~1". It has the same format as
+// the prompt of github.com/hultan/gitstatusprompt, that we used before, but that
+// prompt never showed the commits behind, since it parsed "-1" as a negative number.
+func (g *gitStatusInfo) prompt() string {
+	var result string
+
+	if g.branch != "" {
+		result = g.branch
+	} else {
+		// Detached head
+		result = ":HEAD"
+	}
+	if g.ahead > 0 {
+		result += "↑" + strconv.Itoa(g.ahead)
+	}
+	if g.behind > 0 {
+		result += "↓" + strconv.Itoa(g.behind)
+	}
+	c := g.changes
+	if c.Untracked+c.Modified+c.Deleted+c.Unmerged+c.Staged > 0 {
+		result += "|"
+	}
+	if c.Untracked > 0 {
+		result += "+" + strconv.Itoa(c.Untracked)
+	}
+	if c.Modified > 0 {
+		result += "~" + strconv.Itoa(c.Modified)
+	}
+	if c.Deleted > 0 {
+		result += "-" + strconv.Itoa(c.Deleted)
+	}
+	if c.Unmerged > 0 {
+		result += "x" + strconv.Itoa(c.Unmerged)
+	}
+	if c.Staged > 0 {
+		result += "•" + strconv.Itoa(c.Staged)
+	}
+
+	return result
+}
@@ -0,0 +1,256 @@
+package gitdiscover
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tagTimeout is the maximum time that listing or changing tags may take
+const tagTimeout = 30 * time.Second
+
+// The fields of a tag are separated by the unit separator, like in historyFormat.
+// %(*objectname) is the tagged commit of annotated tags, and empty for lightweight tags.
+const tagFormat = "--format=%(refname:short)%1f%(objecttype)%1f%(objectname)%1f%(*objectname)%1f" +
+	"%(creatordate:iso-strict)%1f%(contents:subject)"
+
+var versionRegex = regexp.MustCompile(`^(v?)(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+	`(?:-([0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*))?(?:\+[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*)?$`)
+
+// Version is a semantic version, like v1.2.3 or 1.2.3-beta.1.
+type Version struct {
+	// Prefix is "v" if the version starts with a v, like Go module versions do
+	Prefix     string
+	Major      int
+	Minor      int
+	Patch      int
+	Prerelease string
+}
+
+// ParseVersion parses a semantic version, like "v1.2.3", and returns
+// false if the text is not a semantic version.
+func ParseVersion(text string) (Version, bool) {
+	match := versionRegex.FindStringSubmatch(text)
+	if match == nil {
+		return Version{}, false
+	}
+	var numbers [3]int
+	for i := range numbers {
+		n, err := strconv.Atoi(match[i+2])
+		if err != nil {
+			return Version{}, false
+		}
+		numbers[i] = n
+	}
+	return Version{Prefix: match[1], Major: numbers[0], Minor: numbers[1], Patch: numbers[2],
+		Prerelease: match[5]}, true
+}
+
+// String returns the version, like "v1.2.3". Build metadata is not included.
+func (v Version) String() string {
+	text := fmt.Sprintf("%s%d.%d.%d", v.Prefix, v.Major, v.Minor, v.Patch)
+	if v.Prerelease != "" {
+		text += "-" + v.Prerelease
+	}
+	return text
+}
+
+// Compare returns -1, 0 or 1 if v is lower than, equal to, or higher than other,
+// using the semantic versioning precedence rules.
+func (v Version) Compare(other Version) int {
+	for _, diff := range []int{v.Major - other.Major, v.Minor - other.Minor, v.Patch - other.Patch} {
+		if diff != 0 {
+			return sign(diff)
+		}
+	}
+	return comparePrerelease(v.Prerelease, other.Prerelease)
+}
+
+// A version without a pre-release is higher than one with a pre-release, and
+// pre-releases are compared identifier by identifier, numbers before text.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	aIds, bIds := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(aIds) && i < len(bIds); i++ {
+		aNumber, aErr := strconv.Atoi(aIds[i])
+		bNumber, bErr := strconv.Atoi(bIds[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if aNumber != bNumber {
+				return sign(aNumber - bNumber)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(aIds[i], bIds[i]); c != 0 {
+				return c
+			}
+		}
+	}
+	return sign(len(aIds) - len(bIds))
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// NextPatch returns the next patch version, like v1.2.4 for v1.2.3.
+// The next version of a pre-release, like v1.2.3-rc.1, is the release v1.2.3.
+func (v Version) NextPatch() Version {
+	if v.Prerelease == "" {
+		v.Patch++
+	}
+	v.Prerelease = ""
+	return v
+}
+
+// NextMinor returns the next minor version, like v1.3.0 for v1.2.3.
+func (v Version) NextMinor() Version {
+	return Version{Prefix: v.Prefix, Major: v.Major, Minor: v.Minor + 1}
+}
+
+// NextMajor returns the next major version, like v2.0.0 for v1.2.3.
+func (v Version) NextMajor() Version {
+	return Version{Prefix: v.Prefix, Major: v.Major + 1}
+}
+
+// GetLatestVersion returns the highest semantic version in tags, and false if there is none.
+func GetLatestVersion(tags []string) (string, Version, bool) {
+	var latestTag string
+	var latest Version
+	found := false
+	for _, tag := range tags {
+		version, ok := ParseVersion(tag)
+		if ok && (!found || version.Compare(latest) > 0) {
+			latestTag, latest, found = tag, version, true
+		}
+	}
+	return latestTag, latest, found
+}
+
+// SuggestNextVersions returns the next patch, minor and major version after
+// the latest semantic version in tags, or v0.0.1, v0.1.0 and v1.0.0 if there is none.
+func SuggestNextVersions(tags []string) (patch, minor, major string) {
+	_, latest, ok := GetLatestVersion(tags)
+	if !ok {
+		latest = Version{Prefix: "v"}
+	}
+	return latest.NextPatch().String(), latest.NextMinor().String(), latest.NextMajor().String()
+}
+
+// Tag is a tag in a repository.
+type Tag struct {
+	Name      string
+	Annotated bool
+	// Commit is the commit that the tag points to
+	Commit string
+	// Date is the date of the tag for annotated tags, and of the commit for lightweight tags
+	Date time.Time
+	// Message is the subject of the tag message for annotated tags, and of the commit for lightweight tags
+	Message string
+}
+
+// GetTags returns the tags in the repository at repoPath, newest first.
+func GetTags(ctx context.Context, repoPath string) ([]*Tag, error) {
+	result, err := newRunner(repoPath, tagTimeout).Run(ctx, "for-each-ref", "--sort=-creatordate", tagFormat, "refs/tags")
+	if err != nil {
+		return nil, getGitError(err)
+	}
+	return parseTags(result.Stdout)
+}
+
+// Parse the output of git for-each-ref, using tagFormat
+func parseTags(text string) ([]*Tag, error) {
+	var tags []*Tag
+	for _, line := range strings.Split(text, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, "\x1f")
+		if len(fields) != 6 {
+			return nil, fmt.Errorf("invalid git for-each-ref line : %q", line)
+		}
+		tag := &Tag{Name: fields[0], Annotated: fields[1] == "tag", Commit: fields[2], Message: fields[5]}
+		if tag.Annotated {
+			tag.Commit = fields[3]
+		}
+		if fields[4] != "" {
+			date, err := time.Parse(time.RFC3339, fields[4])
+			if err != nil {
+				return nil, err
+			}
+			tag.Date = date
+		}
+		tags = append(tags, tag)
+	}
+	return tags, nil
+}
+
+// GetLatestVersionTag returns the highest semantic version tag that
+// is reachable from HEAD, and the number of commits since that tag.
+// The tag is empty if there is no such tag.
+func GetLatestVersionTag(ctx context.Context, repoPath string) (string, int, error) {
+	runner := newRunner(repoPath, tagTimeout)
+	result, err := runner.Run(ctx, "tag", "--list", "--merged", "HEAD")
+	if err != nil {
+		return "", 0, getGitError(err)
+	}
+	tag, _, ok := GetLatestVersion(strings.Fields(result.Stdout))
+	if !ok {
+		return "", 0, nil
+	}
+
+	count, err := runner.Output(ctx, "rev-list", "--count", "refs/tags/"+tag+"..HEAD")
+	if err != nil {
+		return "", 0, getGitError(err)
+	}
+	commits, err := strconv.Atoi(count)
+	if err != nil {
+		return "", 0, err
+	}
+	return tag, commits, nil
+}
+
+// CreateTag creates an annotated tag on HEAD.
+func CreateTag(ctx context.Context, repoPath, name, message string) error {
+	runner := newRunner(repoPath, tagTimeout)
+	if name == "" || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("invalid tag name : %q", name)
+	}
+	_, err := runner.Run(ctx, "check-ref-format", "refs/tags/"+name)
+	if err != nil {
+		return fmt.Errorf("invalid tag name : %q", name)
+	}
+	if strings.TrimSpace(message) == "" {
+		message = name
+	}
+
+	runner.Stdin = strings.NewReader(message)
+	_, err = runner.Run(ctx, "tag", "--annotate", "--file=-", name)
+	return getGitError(err)
+}
+
+// DeleteTag deletes a local tag.
+func DeleteTag(ctx context.Context, repoPath, name string) error {
+	_, err := newRunner(repoPath, tagTimeout).Run(ctx, "tag", "--delete", "--", name)
+	return getGitError(err)
+}
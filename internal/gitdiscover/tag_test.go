@@ -0,0 +1,105 @@
+package gitdiscover
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/hultan/gitdiscover/internal/config"
+)
+
+func Test_ParseVersion(t *testing.T) {
+	version, ok := ParseVersion("v1.2.3")
+	assert.True(t, ok)
+	assert.Equal(t, Version{Prefix: "v", Major: 1, Minor: 2, Patch: 3}, version)
+
+	version, ok = ParseVersion("10.0.1-rc.1+build.5")
+	assert.True(t, ok)
+	assert.Equal(t, Version{Major: 10, Patch: 1, Prerelease: "rc.1"}, version)
+	assert.Equal(t, "10.0.1-rc.1", version.String())
+
+	for _, text := range []string{"1.2", "v01.2.3", "release-1.2.3", "v1.2.3-", ""} {
+		_, ok = ParseVersion(text)
+		assert.False(t, ok, text)
+	}
+}
+
+func Test_Version_Compare(t *testing.T) {
+	// In increasing order, from the semantic versioning specification
+	versions := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2",
+		"1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0"}
+	for i := 0; i < len(versions)-1; i++ {
+		lower, _ := ParseVersion(versions[i])
+		higher, _ := ParseVersion(versions[i+1])
+		assert.Equal(t, -1, lower.Compare(higher), versions[i])
+		assert.Equal(t, 1, higher.Compare(lower), versions[i])
+		assert.Equal(t, 0, lower.Compare(lower), versions[i])
+	}
+}
+
+func Test_SuggestNextVersions(t *testing.T) {
+	patch, minor, major := SuggestNextVersions([]string{"v1.2.3", "v1.10.0", "latest", "v1.9.9"})
+	assert.Equal(t, "v1.10.1", patch)
+	assert.Equal(t, "v1.11.0", minor)
+	assert.Equal(t, "v2.0.0", major)
+
+	patch, minor, major = SuggestNextVersions([]string{"v2.0.0-rc.1"})
+	assert.Equal(t, "v2.0.0", patch)
+	assert.Equal(t, "v2.1.0", minor)
+	assert.Equal(t, "v3.0.0", major)
+
+	patch, minor, major = SuggestNextVersions(nil)
+	assert.Equal(t, "v0.0.1", patch)
+	assert.Equal(t, "v0.1.0", minor)
+	assert.Equal(t, "v1.0.0", major)
+}
+
+func Test_Tags(t *testing.T) {
+	setTestIdentity(t)
+	dir := createTestRepository(t)
+	ctx := context.Background()
+
+	tag, commits, err := GetLatestVersionTag(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "", tag)
+	assert.Equal(t, 0, commits)
+
+	err = CreateTag(ctx, dir, "v1.0.0", "First release")
+	assert.Nil(t, err)
+	runTestGit(t, dir, "tag", "not-a-version")
+	err = CreateTag(ctx, dir, "bad..tag", "")
+	assert.NotNil(t, err)
+	err = CreateTag(ctx, dir, "v1.0.0", "")
+	assert.NotNil(t, err)
+
+	writeTestFile(t, dir, "committed.txt", "changed")
+	runTestGit(t, dir, "commit", "-q", "-a", "-m", "Second commit")
+	runTestGit(t, dir, "commit", "-q", "--allow-empty", "-m", "Third commit")
+
+	repo := LoadRepository(ctx, &config.Repository{Path: dir})
+	assert.Equal(t, "v1.0.0", repo.LatestTag())
+	assert.Equal(t, 2, repo.CommitsSinceTag())
+
+	tags, err := GetTags(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, len(tags))
+	for _, tag := range tags {
+		switch tag.Name {
+		case "v1.0.0":
+			assert.True(t, tag.Annotated)
+			assert.Equal(t, "First release", tag.Message)
+		case "not-a-version":
+			assert.False(t, tag.Annotated)
+			assert.Equal(t, "Initial commit", tag.Message)
+		}
+		assert.Equal(t, tags[0].Commit, tag.Commit)
+		assert.False(t, tag.Date.IsZero())
+	}
+
+	err = DeleteTag(ctx, dir, "v1.0.0")
+	assert.Nil(t, err)
+	tag, _, err = GetLatestVersionTag(ctx, dir)
+	assert.Nil(t, err)
+	assert.Equal(t, "", tag)
+}
@@ -0,0 +1,337 @@
+package gitdiscover
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+// Events that we want to know about, for the working tree and the .git folder
+const watchMask = syscall.IN_CREATE | syscall.IN_DELETE | syscall.IN_CLOSE_WRITE |
+	syscall.IN_MOVED_FROM | syscall.IN_MOVED_TO | syscall.IN_DELETE_SELF
+
+// maxDebounceWaits is the maximum number of debounce intervals that an update
+// can be postponed, so that a constantly changing repository still gets updated.
+const maxDebounceWaits = 10
+
+// Watcher watches the working tree and the .git folder (HEAD, index and refs)
+// of repositories using inotify, and calls onChange with the repository path
+// when something has changed. Changes are debounced, so a git checkout of
+// thousands of files results in a single call.
+type Watcher struct {
+	file     *os.File
+	debounce time.Duration
+	onChange func(repoPath string)
+
+	mutex    sync.Mutex
+	watches  map[int32][]watch  // watch descriptor -> watched folder, per repository
+	repos    map[string][]int32 // repository path -> watch descriptors
+	gitDirs  map[string]string  // repository path -> git folder (with HEAD and index)
+	timers   map[string]*time.Timer
+	firstHit map[string]time.Time // repository path -> time of the first pending change
+}
+
+type watch struct {
+	repoPath string
+	dir      string
+	isGitDir bool
+}
+
+// NewWatcher creates a new watcher. onChange is called from a goroutine
+// belonging to the watcher, at most once per debounce interval and repository.
+func NewWatcher(debounce time.Duration, onChange func(repoPath string)) (*Watcher, error) {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &Watcher{
+		// Since the descriptor is non-blocking, os.File uses the runtime
+		// poller, which means that Close will interrupt a pending Read.
+		file:     os.NewFile(uintptr(fd), "inotify"),
+		debounce: debounce,
+		onChange: onChange,
+		watches:  make(map[int32][]watch),
+		repos:    make(map[string][]int32),
+		gitDirs:  make(map[string]string),
+		timers:   make(map[string]*time.Timer),
+		firstHit: make(map[string]time.Time),
+	}
+	go w.readEvents()
+
+	return w, nil
+}
+
+// Watch starts watching a repository. Watching an already watched repository does nothing.
+func (w *Watcher) Watch(repoPath string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if _, ok := w.repos[repoPath]; ok {
+		return nil
+	}
+	w.repos[repoPath] = nil
+
+	// Working tree (bare repositories have none)
+	layout := getGitLayout(repoPath)
+	if layout.kind != RepositoryKindBare {
+		err := w.addTree(repoPath, repoPath, false)
+		if err != nil {
+			return err
+		}
+	}
+	if layout.kind == RepositoryKindNone {
+		return nil
+	}
+
+	// The git folder (for HEAD and index), and the refs that are shared
+	// by all worktrees (in the main .git folder for linked worktrees)
+	w.gitDirs[repoPath] = layout.gitDir
+	err := w.addWatch(repoPath, layout.gitDir, true)
+	if err != nil {
+		return err
+	}
+	return w.addTree(repoPath, filepath.Join(layout.commonDir, "refs"), true)
+}
+
+// Unwatch stops watching a repository.
+func (w *Watcher) Unwatch(repoPath string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for _, wd := range w.repos[repoPath] {
+		// Folders can be shared with other repositories, like the refs of linked worktrees
+		var others []watch
+		for _, watched := range w.watches[wd] {
+			if watched.repoPath != repoPath {
+				others = append(others, watched)
+			}
+		}
+		if len(others) > 0 {
+			w.watches[wd] = others
+			continue
+		}
+		_, _ = syscall.InotifyRmWatch(int(w.file.Fd()), uint32(wd))
+		delete(w.watches, wd)
+	}
+	delete(w.repos, repoPath)
+	delete(w.gitDirs, repoPath)
+
+	if timer, ok := w.timers[repoPath]; ok {
+		timer.Stop()
+		delete(w.timers, repoPath)
+		delete(w.firstHit, repoPath)
+	}
+}
+
+// SetRepositories watches the given repositories, and stops
+// watching all other repositories.
+func (w *Watcher) SetRepositories(repoPaths []string) error {
+	keep := make(map[string]bool)
+	for _, repoPath := range repoPaths {
+		keep[repoPath] = true
+	}
+
+	w.mutex.Lock()
+	var remove []string
+	for repoPath := range w.repos {
+		if !keep[repoPath] {
+			remove = append(remove, repoPath)
+		}
+	}
+	w.mutex.Unlock()
+
+	for _, repoPath := range remove {
+		w.Unwatch(repoPath)
+	}
+
+	var result error
+	for _, repoPath := range repoPaths {
+		err := w.Watch(repoPath)
+		if err != nil && result == nil {
+			result = err
+		}
+	}
+	return result
+}
+
+// Close stops watching all repositories.
+func (w *Watcher) Close() error {
+	w.mutex.Lock()
+	for repoPath, timer := range w.timers {
+		timer.Stop()
+		delete(w.timers, repoPath)
+		delete(w.firstHit, repoPath)
+	}
+	w.mutex.Unlock()
+
+	return w.file.Close()
+}
+
+// Add watches for a folder and all its sub folders, except .git folders
+func (w *Watcher) addTree(repoPath, root string, isGitDir bool) error {
+	return filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Skip folders that we can't read
+			return nil
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		if !isGitDir && entry.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		return w.addWatch(repoPath, path, isGitDir)
+	})
+}
+
+func (w *Watcher) addWatch(repoPath, dir string, isGitDir bool) error {
+	wd, err := syscall.InotifyAddWatch(int(w.file.Fd()), dir, watchMask)
+	if err != nil {
+		if errors.Is(err, syscall.ENOSPC) {
+			return errors.New("too many folders to watch, increase fs.inotify.max_user_watches")
+		}
+		return err
+	}
+
+	// Watching the same folder again returns the same watch descriptor
+	for _, watched := range w.watches[int32(wd)] {
+		if watched.repoPath == repoPath {
+			return nil
+		}
+	}
+	w.watches[int32(wd)] = append(w.watches[int32(wd)], watch{repoPath: repoPath, dir: dir, isGitDir: isGitDir})
+	w.repos[repoPath] = append(w.repos[repoPath], int32(wd))
+	return nil
+}
+
+func (w *Watcher) readEvents() {
+	buffer := make([]byte, 64*1024)
+	for {
+		n, err := w.file.Read(buffer)
+		if err != nil {
+			// The watcher has been closed
+			return
+		}
+
+		offset := 0
+		for offset+syscall.SizeofInotifyEvent <= n {
+			event := (*syscall.InotifyEvent)(unsafe.Pointer(&buffer[offset]))
+			nameStart := offset + syscall.SizeofInotifyEvent
+			name := strings.TrimRight(string(buffer[nameStart:nameStart+int(event.Len)]), "\x00")
+			w.handleEvent(event.Wd, event.Mask, name)
+			offset = nameStart + int(event.Len)
+		}
+	}
+}
+
+func (w *Watcher) handleEvent(wd int32, mask uint32, name string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if mask&syscall.IN_Q_OVERFLOW != 0 {
+		// Events have been lost, so every repository might have changed
+		for repoPath := range w.repos {
+			w.schedule(repoPath)
+		}
+		return
+	}
+
+	watches, ok := w.watches[wd]
+	if !ok {
+		return
+	}
+
+	if mask&syscall.IN_IGNORED != 0 {
+		// The folder has been removed. The watch descriptor can be reused by
+		// the kernel for another folder, so it must not be removed again later.
+		delete(w.watches, wd)
+		for _, watched := range watches {
+			w.removeRepositoryWatch(watched.repoPath, wd)
+		}
+		return
+	}
+
+	for _, watched := range watches {
+		w.handleRepositoryEvent(watched, mask, name)
+	}
+}
+
+// Remove a watch descriptor from a repository, must be called with the mutex locked
+func (w *Watcher) removeRepositoryWatch(repoPath string, wd int32) {
+	wds := w.repos[repoPath]
+	for i := range wds {
+		if wds[i] == wd {
+			w.repos[repoPath] = append(wds[:i], wds[i+1:]...)
+			return
+		}
+	}
+}
+
+// Handle an event in a folder of a repository, must be called with the mutex locked
+func (w *Watcher) handleRepositoryEvent(watched watch, mask uint32, name string) {
+	if watched.isGitDir && !w.isInterestingGitFile(watched, name) {
+		return
+	}
+
+	// Watch new folders in the working tree (and in refs)
+	if mask&syscall.IN_ISDIR != 0 && mask&(syscall.IN_CREATE|syscall.IN_MOVED_TO) != 0 {
+		newDir := filepath.Join(watched.dir, name)
+		isTopGitDir := watched.isGitDir && watched.dir == w.gitDirs[watched.repoPath]
+		if name != ".git" && !isTopGitDir {
+			_ = w.addTree(watched.repoPath, newDir, watched.isGitDir)
+		}
+	}
+
+	w.schedule(watched.repoPath)
+}
+
+// We are only interested in HEAD, index and refs in the git folder,
+// the rest (like objects and logs) is only noise. Must be called with the mutex locked.
+func (w *Watcher) isInterestingGitFile(watched watch, name string) bool {
+	if watched.dir != w.gitDirs[watched.repoPath] {
+		// Folders inside .git/refs
+		return !strings.HasSuffix(name, ".lock")
+	}
+	switch name {
+	case "HEAD", "index", "packed-refs", "FETCH_HEAD", "ORIG_HEAD", "MERGE_HEAD":
+		return true
+	default:
+		return false
+	}
+}
+
+// Debounce changes, must be called with the mutex locked
+func (w *Watcher) schedule(repoPath string) {
+	if first, pending := w.firstHit[repoPath]; pending {
+		// Postpone the update, unless it has been postponed for too long
+		if time.Since(first) >= w.debounce*maxDebounceWaits {
+			return
+		}
+		w.timers[repoPath].Stop()
+	} else {
+		w.firstHit[repoPath] = time.Now()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(w.debounce, func() {
+		w.mutex.Lock()
+		if w.timers[repoPath] != timer {
+			// Postponed again, or no longer watched
+			w.mutex.Unlock()
+			return
+		}
+		delete(w.timers, repoPath)
+		delete(w.firstHit, repoPath)
+		w.mutex.Unlock()
+
+		w.onChange(repoPath)
+	})
+	w.timers[repoPath] = timer
+}
@@ -0,0 +1,100 @@
+package gitdiscover
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Watcher(t *testing.T) {
+	dir := createTestRepository(t)
+	changes := make(chan string, 10)
+	w, err := NewWatcher(100*time.Millisecond, func(repoPath string) {
+		changes <- repoPath
+	})
+	assert.Nil(t, err)
+	defer func() { _ = w.Close() }()
+
+	err = w.Watch(dir)
+	assert.Nil(t, err)
+
+	// Lots of changes, including new sub folders, should only result in one call
+	err = os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	assert.Nil(t, err)
+	for i := 0; i < 50; i++ {
+		writeTestFile(t, dir, fmt.Sprintf("sub/file%d.txt", i), "text")
+	}
+
+	select {
+	case repoPath := <-changes:
+		assert.Equal(t, dir, repoPath)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+	select {
+	case <-changes:
+		t.Fatal("changes were not debounced")
+	case <-time.After(300 * time.Millisecond):
+	}
+
+	// Changes in the .git folder
+	runTestGit(t, dir, "checkout", "-q", "-b", "feature")
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported for checkout")
+	}
+
+	// Unwatched repositories should not report changes
+	w.Unwatch(dir)
+	writeTestFile(t, dir, "another.txt", "text")
+	select {
+	case <-changes:
+		t.Fatal("change reported for unwatched repository")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+func Test_Watcher_RemovedFolderAndOverflow(t *testing.T) {
+	dir := createTestRepository(t)
+	err := os.MkdirAll(filepath.Join(dir, "sub"), 0755)
+	assert.Nil(t, err)
+	changes := make(chan string, 10)
+	w, err := NewWatcher(100*time.Millisecond, func(repoPath string) {
+		changes <- repoPath
+	})
+	assert.Nil(t, err)
+	defer func() { _ = w.Close() }()
+
+	err = w.Watch(dir)
+	assert.Nil(t, err)
+	w.mutex.Lock()
+	count := len(w.repos[dir])
+	w.mutex.Unlock()
+
+	// The watch descriptor of a removed folder is removed from the repository
+	err = os.RemoveAll(filepath.Join(dir, "sub"))
+	assert.Nil(t, err)
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported")
+	}
+	w.mutex.Lock()
+	assert.Equal(t, count-1, len(w.repos[dir]))
+	w.mutex.Unlock()
+
+	// All repositories are refreshed when events have been lost
+	w.handleEvent(-1, syscall.IN_Q_OVERFLOW, "")
+	select {
+	case repoPath := <-changes:
+		assert.Equal(t, dir, repoPath)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change reported after an overflow")
+	}
+}
@@ -0,0 +1,29 @@
+//go:build !linux
+// +build !linux
+
+package gitdiscover
+
+import (
+	"errors"
+	"time"
+)
+
+// Watcher watches repositories for changes, it is only supported on Linux.
+type Watcher struct{}
+
+// NewWatcher returns an error, since watching is only supported on Linux.
+func NewWatcher(_ time.Duration, _ func(repoPath string)) (*Watcher, error) {
+	return nil, errors.New("watching repositories is only supported on linux")
+}
+
+// Watch does nothing on this platform.
+func (w *Watcher) Watch(_ string) error { return nil }
+
+// Unwatch does nothing on this platform.
+func (w *Watcher) Unwatch(_ string) {}
+
+// SetRepositories does nothing on this platform.
+func (w *Watcher) SetRepositories(_ []string) error { return nil }
+
+// Close does nothing on this platform.
+func (w *Watcher) Close() error { return nil }
@@ -0,0 +1,225 @@
+package gitoutput
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// The 16 basic ANSI colors, normal and bright (xterm defaults)
+var ansiColors = []string{
+	"#000000", "#CD0000", "#00CD00", "#CDCD00", "#0000EE", "#CD00CD", "#00CDCD", "#E5E5E5",
+	"#7F7F7F", "#FF0000", "#00FF00", "#FFFF00", "#5C5CFF", "#FF00FF", "#00FFFF", "#FFFFFF",
+}
+
+// The text attributes set by SGR (Select Graphic Rendition) escape sequences
+type sgrState struct {
+	foreground    string
+	background    string
+	bold          bool
+	dim           bool
+	italic        bool
+	underline     bool
+	reverse       bool
+	strikethrough bool
+}
+
+// Returns the Pango span attributes for the state
+func (s sgrState) attributes() string {
+	var attributes []string
+	foreground, background := s.foreground, s.background
+	if s.reverse {
+		foreground, background = background, foreground
+	}
+	if foreground != "" {
+		attributes = append(attributes, `foreground="`+foreground+`"`)
+	}
+	if background != "" {
+		attributes = append(attributes, `background="`+background+`"`)
+	}
+	switch {
+	case s.bold:
+		attributes = append(attributes, `weight="bold"`)
+	case s.dim:
+		attributes = append(attributes, `weight="light"`)
+	}
+	if s.italic {
+		attributes = append(attributes, `style="italic"`)
+	}
+	if s.underline {
+		attributes = append(attributes, `underline="single"`)
+	}
+	if s.strikethrough {
+		attributes = append(attributes, `strikethrough="true"`)
+	}
+	return strings.Join(attributes, " ")
+}
+
+// ANSIConverter converts text with ANSI escape sequences, like the output
+// of git -c color.ui=always, to Pango markup. SGR sequences (colors and text
+// attributes) are converted, and all other escape sequences are removed. The
+// attributes are kept between calls to Convert, since a colored part of the
+// text may span more than one line.
+type ANSIConverter struct {
+	state sgrState
+}
+
+// NewANSIConverter creates a new ANSIConverter.
+func NewANSIConverter() *ANSIConverter {
+	return new(ANSIConverter)
+}
+
+// ANSIToPango converts text with ANSI escape sequences to Pango markup.
+func ANSIToPango(text string) string {
+	return NewANSIConverter().Convert(text)
+}
+
+// HasANSI returns true if the text contains ANSI escape sequences.
+func HasANSI(text string) bool {
+	return strings.Contains(text, "\x1b")
+}
+
+// Convert converts text with ANSI escape sequences to Pango markup.
+// The returned markup is always balanced (all spans are closed).
+func (c *ANSIConverter) Convert(text string) string {
+	var sb strings.Builder
+	var plain strings.Builder
+	flush := func() {
+		if plain.Len() > 0 {
+			sb.WriteString(span(c.state.attributes(), plain.String()))
+			plain.Reset()
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		if text[i] != '\x1b' {
+			plain.WriteByte(text[i])
+			continue
+		}
+
+		// ESC followed by anything but [ is a two character sequence,
+		// except character set designations, like ESC ( B
+		if i+1 >= len(text) || text[i+1] != '[' {
+			i++
+			if i < len(text) && strings.IndexByte("()*+", text[i]) >= 0 {
+				i++
+			}
+			continue
+		}
+
+		// CSI sequence : ESC [ parameters final, where final is in @ to ~
+		end := i + 2
+		for end < len(text) && (text[end] < '@' || text[end] > '~') {
+			end++
+		}
+		if end == len(text) {
+			// Incomplete sequence
+			break
+		}
+		if text[end] == 'm' {
+			flush()
+			c.applySGR(text[i+2 : end])
+		}
+		i = end
+	}
+	flush()
+
+	return sb.String()
+}
+
+// applySGR applies the parameters of an SGR sequence, like "1;31"
+func (c *ANSIConverter) applySGR(parameters string) {
+	codes := strings.Split(parameters, ";")
+	for i := 0; i < len(codes); i++ {
+		code, err := strconv.Atoi(codes[i])
+		if err != nil {
+			// An empty parameter means 0 (reset)
+			code = 0
+		}
+
+		s := &c.state
+		switch {
+		case code == 0:
+			*s = sgrState{}
+		case code == 1:
+			s.bold = true
+		case code == 2:
+			s.dim = true
+		case code == 3:
+			s.italic = true
+		case code == 4:
+			s.underline = true
+		case code == 7:
+			s.reverse = true
+		case code == 9:
+			s.strikethrough = true
+		case code == 22:
+			s.bold, s.dim = false, false
+		case code == 23:
+			s.italic = false
+		case code == 24:
+			s.underline = false
+		case code == 27:
+			s.reverse = false
+		case code == 29:
+			s.strikethrough = false
+		case code >= 30 && code <= 37:
+			s.foreground = ansiColors[code-30]
+		case code == 38:
+			s.foreground, i = parseExtendedColor(codes, i)
+		case code == 39:
+			s.foreground = ""
+		case code >= 40 && code <= 47:
+			s.background = ansiColors[code-40]
+		case code == 48:
+			s.background, i = parseExtendedColor(codes, i)
+		case code == 49:
+			s.background = ""
+		case code >= 90 && code <= 97:
+			s.foreground = ansiColors[code-90+8]
+		case code >= 100 && code <= 107:
+			s.background = ansiColors[code-100+8]
+		}
+	}
+}
+
+// parseExtendedColor parses a 256 color (38;5;n) or a 24-bit color (38;2;r;g;b)
+// starting at codes[i], and returns the color and the index of its last code.
+func parseExtendedColor(codes []string, i int) (string, int) {
+	if i+2 < len(codes) && codes[i+1] == "5" {
+		n, err := strconv.Atoi(codes[i+2])
+		if err != nil || n < 0 || n > 255 {
+			return "", i + 2
+		}
+		return get256Color(n), i + 2
+	}
+	if i+4 < len(codes) && codes[i+1] == "2" {
+		var rgb [3]int
+		for j := range rgb {
+			value, err := strconv.Atoi(codes[i+2+j])
+			if err != nil || value < 0 || value > 255 {
+				return "", i + 4
+			}
+			rgb[j] = value
+		}
+		return fmt.Sprintf("#%02X%02X%02X", rgb[0], rgb[1], rgb[2]), i + 4
+	}
+	return "", len(codes)
+}
+
+// get256Color returns color n in the xterm 256 color palette
+func get256Color(n int) string {
+	switch {
+	case n < 16:
+		return ansiColors[n]
+	case n < 232:
+		// 6x6x6 color cube
+		levels := []int{0, 95, 135, 175, 215, 255}
+		n -= 16
+		return fmt.Sprintf("#%02X%02X%02X", levels[n/36], levels[n/6%6], levels[n%6])
+	default:
+		// Gray scale
+		gray := 8 + (n-232)*10
+		return fmt.Sprintf("#%02X%02X%02X", gray, gray, gray)
+	}
+}
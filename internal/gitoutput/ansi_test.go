@@ -0,0 +1,50 @@
+package gitoutput
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestANSIToPango(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"plain", "a < b", "a &lt; b"},
+		{"color", "\x1b[31mred\x1b[m plain", `<span foreground="#CD0000">red</span> plain`},
+		{"bold and color", "\x1b[1;32mok\x1b[0m", `<span foreground="#00CD00" weight="bold">ok</span>`},
+		{"bright and background", "\x1b[93;44mx", `<span foreground="#FFFF00" background="#0000EE">x</span>`},
+		{"256 colors", "\x1b[38;5;196mx\x1b[38;5;244my", `<span foreground="#FF0000">x</span><span foreground="#808080">y</span>`},
+		{"24-bit colors", "\x1b[48;2;1;2;255mx", `<span background="#0102FF">x</span>`},
+		{"reset attributes", "\x1b[1;3;4mx\x1b[22;23;24my", `<span weight="bold" style="italic" underline="single">x</span>y`},
+		{"default colors", "\x1b[31;42mx\x1b[39;49my", `<span foreground="#CD0000" background="#00CD00">x</span>y`},
+		{"reverse", "\x1b[31;7mx", `<span background="#CD0000">x</span>`},
+		{"other sequences are removed", "\x1b[Kx\x1b(By", "xy"},
+		{"incomplete sequence", "x\x1b[31", "x"},
+		// git log --decorate --color=always
+		{"git log", "\x1b[33mcommit 3b18e51\x1b[m\x1b[33m (\x1b[m\x1b[1;36mHEAD -> \x1b[m\x1b[1;32mmain\x1b[m\x1b[33m)\x1b[m",
+			`<span foreground="#CDCD00">commit 3b18e51</span><span foreground="#CDCD00"> (</span>` +
+				`<span foreground="#00CDCD" weight="bold">HEAD -&gt; </span>` +
+				`<span foreground="#00CD00" weight="bold">main</span><span foreground="#CDCD00">)</span>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ANSIToPango(tt.text))
+		})
+	}
+}
+
+func TestANSIConverter_Convert(t *testing.T) {
+	// The colors are kept between lines
+	c := NewANSIConverter()
+	assert.Equal(t, `<span foreground="#CD0000">first</span>`, c.Convert("\x1b[31mfirst"))
+	assert.Equal(t, `<span foreground="#CD0000">second</span>`, c.Convert("second\x1b[m"))
+	assert.Equal(t, "third", c.Convert("third"))
+}
+
+func TestHasANSI(t *testing.T) {
+	assert.True(t, HasANSI("\x1b[31mred"))
+	assert.False(t, HasANSI("plain"))
+}
@@ -0,0 +1,296 @@
+package gitoutput
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DiffLineKind is the kind of line in a unified diff.
+type DiffLineKind int
+
+const (
+	// DiffOther is a line outside of the diffs, like the commit header in git log -p
+	DiffOther DiffLineKind = iota
+	// DiffFileHeader is a line like "diff --git", "index", "---" or "+++"
+	DiffFileHeader
+	// DiffHunkHeader is a line like "@@ -1,2 +1,3 @@"
+	DiffHunkHeader
+	DiffContext
+	DiffAdded
+	DiffRemoved
+	// DiffNoNewline is the "\ No newline at end of file" line
+	DiffNoNewline
+)
+
+// maxWordDiffTokens is the maximum number of tokens in a line
+// that we try to find the changed words in
+const maxWordDiffTokens = 500
+
+var hunkHeaderRegex = regexp.MustCompile(`^@@ -\d+(?:,(\d+))? \+\d+(?:,(\d+))? @@`)
+
+var fileHeaderPrefixes = []string{
+	"diff --git ", "diff --cc ", "diff --combined ", "index ", "--- ", "+++ ",
+	"new file mode ", "deleted file mode ", "old mode ", "new mode ",
+	"similarity index ", "dissimilarity index ", "rename from ", "rename to ",
+	"copy from ", "copy to ", "Binary files ",
+}
+
+// Segment is a part of an added or removed line.
+type Segment struct {
+	Text string
+	// Changed is true if the text is not in the corresponding
+	// removed (or added) line
+	Changed bool
+}
+
+// DiffLine is a parsed line in a unified diff.
+type DiffLine struct {
+	Kind DiffLineKind
+	Text string
+	// Segments splits the content of an added or removed line (without the
+	// leading + or -) into changed and unchanged parts. It is nil if the line
+	// has no corresponding line, or if the lines have nothing in common.
+	Segments []Segment
+}
+
+// DiffParser parses a unified diff line by line, so that it can be used
+// while the diff is being streamed. Added and removed lines are held back
+// until the end of each block of changes, to find the changed words.
+type DiffParser struct {
+	// Number of old and new lines left in the current hunk
+	oldLeft, newLeft int
+
+	removed []DiffLine
+	added   []DiffLine
+}
+
+// NewDiffParser creates a new DiffParser.
+func NewDiffParser() *DiffParser {
+	return new(DiffParser)
+}
+
+// ParseDiff parses a complete unified diff.
+func ParseDiff(text string) []DiffLine {
+	p := NewDiffParser()
+	var result []DiffLine
+	for _, line := range splitLines(text) {
+		result = append(result, p.ParseLine(line)...)
+	}
+	return append(result, p.Flush()...)
+}
+
+// ParseLine parses the next line of the diff, and returns the lines that
+// are done, which may be none, or more than one, at the end of a block of
+// added and removed lines.
+func (p *DiffParser) ParseLine(line string) []DiffLine {
+	if p.oldLeft > 0 || p.newLeft > 0 {
+		switch {
+		case strings.HasPrefix(line, "-") && p.oldLeft > 0:
+			p.oldLeft--
+			p.removed = append(p.removed, DiffLine{Kind: DiffRemoved, Text: line})
+			return nil
+		case strings.HasPrefix(line, "+") && p.newLeft > 0:
+			p.newLeft--
+			p.added = append(p.added, DiffLine{Kind: DiffAdded, Text: line})
+			return nil
+		case strings.HasPrefix(line, " ") || line == "":
+			p.oldLeft--
+			p.newLeft--
+			return append(p.Flush(), DiffLine{Kind: DiffContext, Text: line})
+		}
+	}
+
+	result := p.Flush()
+	if strings.HasPrefix(line, `\`) {
+		return append(result, DiffLine{Kind: DiffNoNewline, Text: line})
+	}
+
+	// The hunk is over, or the counts in the hunk header were wrong
+	p.oldLeft, p.newLeft = 0, 0
+	if match := hunkHeaderRegex.FindStringSubmatch(line); match != nil {
+		p.oldLeft = parseHunkCount(match[1])
+		p.newLeft = parseHunkCount(match[2])
+		return append(result, DiffLine{Kind: DiffHunkHeader, Text: line})
+	}
+	for _, prefix := range fileHeaderPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			return append(result, DiffLine{Kind: DiffFileHeader, Text: line})
+		}
+	}
+	return append(result, DiffLine{Kind: DiffOther, Text: line})
+}
+
+// Flush returns the added and removed lines that are held back.
+func (p *DiffParser) Flush() []DiffLine {
+	if len(p.removed) == 0 && len(p.added) == 0 {
+		return nil
+	}
+
+	// Pair the removed and added lines, in order, to find the changed words
+	for i := 0; i < len(p.removed) && i < len(p.added); i++ {
+		p.removed[i].Segments, p.added[i].Segments = diffWords(p.removed[i].Text[1:], p.added[i].Text[1:])
+	}
+
+	result := append(p.removed, p.added...)
+	p.removed = nil
+	p.added = nil
+	return result
+}
+
+// The line count in a hunk header is 1 if it is left out
+func parseHunkCount(count string) int {
+	if count == "" {
+		return 1
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// diffWords splits two lines into changed and unchanged segments, using the
+// longest common subsequence of their tokens. It returns nil segments if
+// the lines have no words in common, or are too long.
+func diffWords(oldLine, newLine string) ([]Segment, []Segment) {
+	oldTokens := tokenize(oldLine)
+	newTokens := tokenize(newLine)
+	if len(oldTokens) > maxWordDiffTokens || len(newTokens) > maxWordDiffTokens {
+		return nil, nil
+	}
+
+	// lcs[i][j] is the length of the LCS of oldTokens[i:] and newTokens[j:]
+	lcs := make([][]int, len(oldTokens)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(newTokens)+1)
+	}
+	for i := len(oldTokens) - 1; i >= 0; i-- {
+		for j := len(newTokens) - 1; j >= 0; j-- {
+			switch {
+			case oldTokens[i] == newTokens[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	oldChanged := make([]bool, len(oldTokens))
+	newChanged := make([]bool, len(newTokens))
+	commonWords := false
+	i, j := 0, 0
+	for i < len(oldTokens) || j < len(newTokens) {
+		switch {
+		case i < len(oldTokens) && j < len(newTokens) && oldTokens[i] == newTokens[j]:
+			if strings.TrimSpace(oldTokens[i]) != "" {
+				commonWords = true
+			}
+			i++
+			j++
+		case j == len(newTokens) || (i < len(oldTokens) && lcs[i+1][j] >= lcs[i][j+1]):
+			oldChanged[i] = true
+			i++
+		default:
+			newChanged[j] = true
+			j++
+		}
+	}
+	if !commonWords {
+		return nil, nil
+	}
+
+	return toSegments(oldTokens, oldChanged), toSegments(newTokens, newChanged)
+}
+
+// Merge tokens into segments, with one segment per run of (un)changed tokens
+func toSegments(tokens []string, changed []bool) []Segment {
+	var segments []Segment
+	for i, token := range tokens {
+		if len(segments) > 0 && segments[len(segments)-1].Changed == changed[i] {
+			segments[len(segments)-1].Text += token
+			continue
+		}
+		segments = append(segments, Segment{Text: token, Changed: changed[i]})
+	}
+	return segments
+}
+
+// tokenize splits a line into words (letters, digits and underscores),
+// runs of whitespace, and single other characters
+func tokenize(line string) []string {
+	var tokens []string
+	runes := []rune(line)
+	for start := 0; start < len(runes); {
+		end := start + 1
+		switch {
+		case isWordRune(runes[start]):
+			for end < len(runes) && isWordRune(runes[end]) {
+				end++
+			}
+		case isSpaceRune(runes[start]):
+			for end < len(runes) && isSpaceRune(runes[end]) {
+				end++
+			}
+		}
+		tokens = append(tokens, string(runes[start:end]))
+		start = end
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r > 127
+}
+
+func isSpaceRune(r rune) bool {
+	return r == ' ' || r == '\t'
+}
+
+// FormatDiffLine returns a parsed diff line, with a line break, as Pango markup.
+func FormatDiffLine(line DiffLine) string {
+	var markup string
+	switch line.Kind {
+	case DiffFileHeader:
+		markup = span(`weight="bold"`, line.Text)
+	case DiffHunkHeader:
+		markup = span(`color="#00AAAA"`, line.Text)
+	case DiffAdded:
+		markup = formatChangedLine(line, "green", "#C8F0C8")
+	case DiffRemoved:
+		markup = formatChangedLine(line, "red", "#F0C8C8")
+	case DiffNoNewline:
+		markup = span(`color="gray" style="italic"`, line.Text)
+	default:
+		markup = EscapeMarkup(line.Text)
+	}
+	return markup + "\n"
+}
+
+// Added and removed lines get the color, and changed words also get the background
+func formatChangedLine(line DiffLine, color, background string) string {
+	if line.Segments == nil {
+		return span(`color="`+color+`"`, line.Text)
+	}
+
+	markup := `<span color="` + color + `">` + EscapeMarkup(line.Text[:1])
+	for _, segment := range line.Segments {
+		if segment.Changed {
+			markup += span(`background="`+background+`"`, segment.Text)
+		} else {
+			markup += EscapeMarkup(segment.Text)
+		}
+	}
+	return markup + "</span>"
+}
+
+// DiffToPango returns a unified diff as Pango markup.
+func DiffToPango(text string) string {
+	var sb strings.Builder
+	for _, line := range ParseDiff(text) {
+		sb.WriteString(FormatDiffLine(line))
+	}
+	return sb.String()
+}
@@ -0,0 +1,126 @@
+package gitoutput
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleDiff = `diff --git a/main.go b/main.go
+index 3b18e51..a3c1f2d 100644
+--- a/main.go
++++ b/main.go
+@@ -1,6 +1,6 @@
+ package main
+ 
+--- removed comment line
++++ added comment line
+ func main() {
+-	fmt.Println("Hello world")
++	fmt.Println("Hello gitdiscover")
+ }
+\ No newline at end of file
+diff --git a/new.txt b/new.txt
+new file mode 100644
+index 0000000..ce01362
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1 @@
++hello
+`
+
+func getKinds(lines []DiffLine) []DiffLineKind {
+	var kinds []DiffLineKind
+	for _, line := range lines {
+		kinds = append(kinds, line.Kind)
+	}
+	return kinds
+}
+
+func TestParseDiff(t *testing.T) {
+	lines := ParseDiff(sampleDiff)
+	assert.Equal(t, []DiffLineKind{
+		DiffFileHeader, DiffFileHeader, DiffFileHeader, DiffFileHeader,
+		DiffHunkHeader,
+		DiffContext, DiffContext,
+		// "--- removed..." and "+++ added..." are content, not file headers
+		DiffRemoved, DiffAdded,
+		DiffContext,
+		DiffRemoved, DiffAdded,
+		DiffContext,
+		DiffNoNewline,
+		DiffFileHeader, DiffFileHeader, DiffFileHeader, DiffFileHeader, DiffFileHeader,
+		DiffHunkHeader,
+		DiffAdded,
+	}, getKinds(lines))
+	assert.Equal(t, "--- removed comment line", lines[7].Text)
+}
+
+func TestParseDiff_Other(t *testing.T) {
+	// git log -p has commit headers between the diffs
+	text := "commit 3b18e51\nAuthor: Test\n\n    Message\n\ndiff --git a/a b/a\n@@ -1 +1 @@\n-a\n+b\n"
+	lines := ParseDiff(text)
+	assert.Equal(t, []DiffLineKind{
+		DiffOther, DiffOther, DiffOther, DiffOther, DiffOther,
+		DiffFileHeader, DiffHunkHeader, DiffRemoved, DiffAdded,
+	}, getKinds(lines))
+}
+
+func TestParseDiff_WordDiff(t *testing.T) {
+	lines := ParseDiff(sampleDiff)
+
+	removed := lines[10]
+	assert.Equal(t, []Segment{
+		{Text: "\tfmt.Println(\"Hello ", Changed: false},
+		{Text: "world", Changed: true},
+		{Text: "\")", Changed: false},
+	}, removed.Segments)
+
+	added := lines[11]
+	assert.Equal(t, []Segment{
+		{Text: "\tfmt.Println(\"Hello ", Changed: false},
+		{Text: "gitdiscover", Changed: true},
+		{Text: "\")", Changed: false},
+	}, added.Segments)
+
+	// Added lines without a removed line have no segments
+	assert.Nil(t, lines[20].Segments)
+}
+
+func TestParseDiff_WordDiff_NothingInCommon(t *testing.T) {
+	lines := ParseDiff("@@ -1 +1 @@\n-foo bar\n+baz qux\n")
+	assert.Nil(t, lines[1].Segments)
+	assert.Nil(t, lines[2].Segments)
+}
+
+func TestDiffParser_ParseLine(t *testing.T) {
+	// Removed and added lines are held back until the block ends
+	p := NewDiffParser()
+	assert.Equal(t, 1, len(p.ParseLine("@@ -1,3 +1,2 @@")))
+	assert.Empty(t, p.ParseLine("-old"))
+	assert.Empty(t, p.ParseLine("+new"))
+	assert.Equal(t, 3, len(p.ParseLine(" context")))
+	assert.Empty(t, p.ParseLine("-last"))
+	assert.Equal(t, 1, len(p.Flush()))
+	assert.Empty(t, p.Flush())
+}
+
+func TestFormatDiffLine(t *testing.T) {
+	lines := ParseDiff(sampleDiff)
+	assert.Equal(t, "<span weight=\"bold\">--- a/main.go</span>\n", FormatDiffLine(lines[2]))
+	assert.Equal(t, "<span color=\"#00AAAA\">@@ -1,6 +1,6 @@</span>\n", FormatDiffLine(lines[4]))
+	assert.Equal(t, "<span color=\"red\">-<span background=\"#F0C8C8\">--</span> "+
+		"<span background=\"#F0C8C8\">removed</span> comment line</span>\n", FormatDiffLine(lines[7]))
+	assert.Equal(t, "<span color=\"green\">+\tfmt.Println(\"Hello "+
+		"<span background=\"#C8F0C8\">gitdiscover</span>\")</span>\n", FormatDiffLine(lines[11]))
+	assert.Equal(t, "<span color=\"green\">+hello</span>\n", FormatDiffLine(lines[20]))
+}
+
+func TestDiffToPango(t *testing.T) {
+	markup := DiffToPango("@@ -1 +1 @@\n-a < b\n+a &lt; b && c\n")
+	assert.NotContains(t, markup, "a < b")
+	assert.Contains(t, markup, "&amp;lt;")
+	assert.Equal(t, 3, strings.Count(markup, "\n"))
+	assert.Equal(t, strings.Count(markup, "<span"), strings.Count(markup, "</span>"))
+}
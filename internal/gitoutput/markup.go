@@ -0,0 +1,21 @@
+// Package gitoutput parses and formats git output (diffs, status and
+// ANSI colored text) as Pango markup, for the GitDiscover output window.
+package gitoutput
+
+import "strings"
+
+var markupReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeMarkup escapes the characters that have a special meaning in Pango markup.
+func EscapeMarkup(text string) string {
+	return markupReplacer.Replace(text)
+}
+
+// Returns the escaped text in a span with the given attributes,
+// like `color="red"`, or just the escaped text if there are none.
+func span(attributes, text string) string {
+	if attributes == "" {
+		return EscapeMarkup(text)
+	}
+	return "<span " + attributes + ">" + EscapeMarkup(text) + "</span>"
+}
@@ -0,0 +1,54 @@
+package gitoutput
+
+import "strings"
+
+// StatusFormatter formats the output of git status (the long format) line
+// by line. Staged files are green, and unstaged, untracked and unmerged
+// files are red, like git does it in a terminal.
+type StatusFormatter struct {
+	color string
+}
+
+// NewStatusFormatter creates a new StatusFormatter.
+func NewStatusFormatter() *StatusFormatter {
+	return new(StatusFormatter)
+}
+
+// FormatLine returns a single line of git status output, with
+// a line break, as Pango markup.
+func (f *StatusFormatter) FormatLine(line string) string {
+	switch {
+	case strings.TrimSpace(line) == "":
+		// An empty line ends the section
+		f.color = ""
+	case strings.HasPrefix(line, "Changes to be committed:"):
+		f.color = "green"
+	case strings.HasPrefix(line, "Changes not staged for commit:"),
+		strings.HasPrefix(line, "Untracked files:"),
+		strings.HasPrefix(line, "Unmerged paths:"):
+		f.color = "red"
+	case f.color != "" && strings.HasPrefix(line, "\t"):
+		// Files are indented with a tab, hints with spaces
+		return span(`color="`+f.color+`"`, line) + "\n"
+	}
+	return EscapeMarkup(line) + "\n"
+}
+
+// StatusToPango returns the output of git status as Pango markup.
+func StatusToPango(text string) string {
+	f := NewStatusFormatter()
+	var sb strings.Builder
+	for _, line := range splitLines(text) {
+		sb.WriteString(f.FormatLine(line))
+	}
+	return sb.String()
+}
+
+// Split text into lines, without a last empty line for a trailing line break
+func splitLines(text string) []string {
+	text = strings.TrimSuffix(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
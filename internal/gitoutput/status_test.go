@@ -0,0 +1,38 @@
+package gitoutput
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleStatus = `On branch main
+Changes to be committed:
+  (use "git restore --staged <file>..." to unstage)
+	new file:   added.txt
+
+Changes not staged for commit:
+  (use "git add <file>..." to update what will be committed)
+	modified:   main.go
+
+Untracked files:
+  (use "git add <file>..." to include in what will be committed)
+	untracked.txt
+
+`
+
+func TestStatusToPango(t *testing.T) {
+	lines := strings.Split(StatusToPango(sampleStatus), "\n")
+	assert.Equal(t, "On branch main", lines[0])
+	assert.Equal(t, `  (use "git restore --staged &lt;file&gt;..." to unstage)`, lines[2])
+	assert.Equal(t, "<span color=\"green\">\tnew file:   added.txt</span>", lines[3])
+	assert.Equal(t, "<span color=\"red\">\tmodified:   main.go</span>", lines[7])
+	assert.Equal(t, "<span color=\"red\">\tuntracked.txt</span>", lines[11])
+	assert.Equal(t, 14, len(lines))
+}
+
+func TestStatusToPango_Clean(t *testing.T) {
+	text := "On branch main\nnothing to commit, working tree clean\n"
+	assert.Equal(t, text, StatusToPango(text))
+}
@@ -0,0 +1,137 @@
+// Package gitrunner runs git commands, without a shell, in a given folder.
+package gitrunner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Runner runs git commands in a folder.
+type Runner struct {
+	// Dir is the folder that git runs in
+	Dir string
+	// Timeout is the maximum time a command may take, 0 means no timeout
+	Timeout time.Duration
+	// Env contains extra environment variables, like "GIT_OPTIONAL_LOCKS=0"
+	Env []string
+	// Stdin is the standard input of the command, nil means no input
+	Stdin io.Reader
+	// NoPrompt makes git fail instead of asking for passwords or passphrases
+	NoPrompt bool
+}
+
+// Result contains the result of a git command.
+type Result struct {
+	Args     []string
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+}
+
+// ExitError is returned when git exits with a non-zero exit code.
+type ExitError struct {
+	*Result
+}
+
+// Error returns the git command, the exit code and the first line of stderr.
+func (e *ExitError) Error() string {
+	msg := fmt.Sprintf("git %s : exit code %d", strings.Join(e.Args, " "), e.ExitCode)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += " : " + strings.SplitN(stderr, "\n", 2)[0]
+	}
+	return msg
+}
+
+// NewRunner creates a new Runner for the folder dir.
+func NewRunner(dir string) *Runner {
+	runner := new(Runner)
+	runner.Dir = dir
+	return runner
+}
+
+// Run runs git with the arguments args, and waits for it to finish. The result
+// is returned even if there is an error, which is an *ExitError if git exits
+// with a non-zero exit code, or the context error if the command is cancelled
+// or times out.
+func (r *Runner) Run(ctx context.Context, args ...string) (*Result, error) {
+	var stdout, stderr bytes.Buffer
+	result, err := r.run(ctx, args, &stdout, &stderr)
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	return result, err
+}
+
+// Output runs git like Run, and returns the trimmed standard output.
+func (r *Runner) Output(ctx context.Context, args ...string) (string, error) {
+	result, err := r.Run(ctx, args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(result.Stdout), nil
+}
+
+func (r *Runner) run(ctx context.Context, args []string, stdout, stderr io.Writer) (*Result, error) {
+	if r.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.Timeout)
+		defer cancel()
+	}
+
+	result := &Result{Args: args}
+	cmd := r.command(ctx, args)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	start := time.Now()
+	err := cmd.Run()
+	result.Duration = time.Since(start)
+
+	return result, r.getError(ctx, result, err)
+}
+
+func (r *Runner) command(ctx context.Context, args []string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = r.Dir
+	cmd.Stdin = r.Stdin
+	cmd.Env = append(os.Environ(), r.Env...)
+	if r.NoPrompt {
+		cmd.Env = append(cmd.Env, getNoPromptEnvironment()...)
+	}
+	return cmd
+}
+
+func (r *Runner) getError(ctx context.Context, result *Result, err error) error {
+	if err == nil {
+		return nil
+	}
+	result.ExitCode = -1
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		return &ExitError{result}
+	}
+	return err
+}
+
+// Git must never wait for the user to enter a password or a
+// passphrase in the background, so we fail instead of prompting.
+func getNoPromptEnvironment() []string {
+	env := []string{"GIT_TERMINAL_PROMPT=0"}
+	_, hasSSHCommand := os.LookupEnv("GIT_SSH_COMMAND")
+	_, hasSSH := os.LookupEnv("GIT_SSH")
+	if !hasSSHCommand && !hasSSH {
+		env = append(env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	return env
+}
@@ -0,0 +1,101 @@
+package gitrunner
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func createTestRepository(t *testing.T, name string) string {
+	dir := filepath.Join(t.TempDir(), name)
+	err := os.Mkdir(dir, 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = NewRunner(dir).Run(context.Background(), "init", "-q", "-b", "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestRunner_Run(t *testing.T) {
+	// Paths with spaces and quotes broke the old shell scripts
+	dir := createTestRepository(t, `my "repo" it's; $(here)`)
+
+	result, err := NewRunner(dir).Run(context.Background(), "rev-parse", "--show-toplevel")
+	assert.Nil(t, err)
+	assert.Equal(t, 0, result.ExitCode)
+	assert.Equal(t, dir, strings.TrimSpace(result.Stdout))
+	assert.Equal(t, "", result.Stderr)
+	assert.Equal(t, []string{"rev-parse", "--show-toplevel"}, result.Args)
+}
+
+func TestRunner_Run_ExitCode(t *testing.T) {
+	dir := createTestRepository(t, "repo")
+
+	result, err := NewRunner(dir).Run(context.Background(), "rev-parse", "--verify", "no-such-branch")
+	var exitErr *ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 128, result.ExitCode)
+	assert.Equal(t, 128, exitErr.ExitCode)
+	assert.Equal(t, "", result.Stdout)
+	assert.Contains(t, result.Stderr, "fatal")
+	assert.True(t, strings.HasPrefix(err.Error(), "git rev-parse --verify no-such-branch : exit code 128 : fatal"))
+}
+
+func TestRunner_Run_Stdin(t *testing.T) {
+	dir := createTestRepository(t, "repo")
+
+	runner := NewRunner(dir)
+	runner.Stdin = strings.NewReader("hello\n")
+	hash, err := runner.Output(context.Background(), "hash-object", "--stdin")
+	assert.Nil(t, err)
+	assert.Equal(t, "ce013625030ba8dba906f756967f9e9ca394464a", hash)
+}
+
+func TestRunner_Run_Env(t *testing.T) {
+	dir := createTestRepository(t, "repo")
+
+	runner := NewRunner(dir)
+	runner.Env = []string{"GIT_AUTHOR_NAME=Test Author", "GIT_AUTHOR_EMAIL=test@example.com"}
+	ident, err := runner.Output(context.Background(), "var", "GIT_AUTHOR_IDENT")
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(ident, "Test Author "))
+}
+
+func TestRunner_Run_Timeout(t *testing.T) {
+	dir := createTestRepository(t, "repo")
+
+	// git waits for input that never comes
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = reader.Close()
+		_ = writer.Close()
+	}()
+
+	runner := NewRunner(dir)
+	runner.Stdin = reader
+	runner.Timeout = 100 * time.Millisecond
+	result, err := runner.Run(context.Background(), "hash-object", "--stdin")
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	assert.Equal(t, -1, result.ExitCode)
+}
+
+func TestRunner_Run_Cancelled(t *testing.T) {
+	dir := createTestRepository(t, "repo")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := NewRunner(dir).Run(ctx, "status")
+	assert.True(t, errors.Is(err, context.Canceled))
+}
@@ -0,0 +1,80 @@
+package gitrunner
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync"
+)
+
+// Stream is the output stream (stdout or stderr) of a line.
+type Stream int
+
+const (
+	Stdout Stream = iota
+	Stderr
+)
+
+// Line is a line of output from a running git command, without the line break.
+type Line struct {
+	Stream Stream
+	Text   string
+}
+
+// LineHandler is called for each line of output from a running git command.
+// It is called from other goroutines, but never by two goroutines at once.
+type LineHandler func(line Line)
+
+// RunStreaming runs git like Run, but also calls onLine for each line of
+// output as soon as git has written it. The result contains all the output.
+func (r *Runner) RunStreaming(ctx context.Context, onLine LineHandler, args ...string) (*Result, error) {
+	var mutex sync.Mutex
+	stdout := &lineWriter{stream: Stdout, mutex: &mutex, onLine: onLine}
+	stderr := &lineWriter{stream: Stderr, mutex: &mutex, onLine: onLine}
+
+	result, err := r.run(ctx, args, stdout, stderr)
+	stdout.flush()
+	stderr.flush()
+	result.Stdout = stdout.all.String()
+	result.Stderr = stderr.all.String()
+
+	return result, err
+}
+
+// lineWriter is an io.Writer that calls onLine for each complete line
+type lineWriter struct {
+	stream  Stream
+	mutex   *sync.Mutex
+	onLine  LineHandler
+	all     bytes.Buffer
+	partial []byte
+}
+
+func (w *lineWriter) Write(p []byte) (int, error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.all.Write(p)
+	w.partial = append(w.partial, p...)
+	for {
+		i := bytes.IndexByte(w.partial, '\n')
+		if i < 0 {
+			break
+		}
+		w.onLine(Line{Stream: w.stream, Text: strings.TrimSuffix(string(w.partial[:i]), "\r")})
+		w.partial = w.partial[i+1:]
+	}
+
+	return len(p), nil
+}
+
+// flush calls onLine for the last line, if it does not end with a line break
+func (w *lineWriter) flush() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if len(w.partial) > 0 {
+		w.onLine(Line{Stream: w.stream, Text: string(w.partial)})
+		w.partial = nil
+	}
+}
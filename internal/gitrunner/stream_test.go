@@ -0,0 +1,51 @@
+package gitrunner
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunner_RunStreaming(t *testing.T) {
+	dir := createTestRepository(t, "repo")
+
+	var lines []Line
+	result, err := NewRunner(dir).RunStreaming(context.Background(), func(line Line) {
+		lines = append(lines, line)
+	}, "rev-parse", "--is-inside-work-tree", "--is-bare-repository")
+	assert.Nil(t, err)
+	assert.Equal(t, []Line{{Stdout, "true"}, {Stdout, "false"}}, lines)
+	assert.Equal(t, "true\nfalse\n", result.Stdout)
+}
+
+func TestRunner_RunStreaming_Stderr(t *testing.T) {
+	dir := createTestRepository(t, "repo")
+
+	var lines []Line
+	result, err := NewRunner(dir).RunStreaming(context.Background(), func(line Line) {
+		lines = append(lines, line)
+	}, "rev-parse", "--verify", "no-such-branch")
+	var exitErr *ExitError
+	assert.True(t, errors.As(err, &exitErr))
+	assert.Equal(t, 128, result.ExitCode)
+	assert.Equal(t, 1, len(lines))
+	assert.Equal(t, Stderr, lines[0].Stream)
+	assert.Equal(t, "fatal: Needed a single revision", lines[0].Text)
+}
+
+func TestLineWriter_Write(t *testing.T) {
+	var lines []string
+	w := &lineWriter{stream: Stdout, mutex: new(sync.Mutex), onLine: func(line Line) {
+		lines = append(lines, line.Text)
+	}}
+	_, _ = w.Write([]byte("first\r\nsec"))
+	assert.Equal(t, []string{"first"}, lines)
+	_, _ = w.Write([]byte("ond\n\nlast"))
+	assert.Equal(t, []string{"first", "second", ""}, lines)
+	w.flush()
+	assert.Equal(t, []string{"first", "second", "", "last"}, lines)
+	assert.Equal(t, "first\r\nsecond\n\nlast", w.all.String())
+}